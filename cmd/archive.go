@@ -0,0 +1,117 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// archiveWriter writes the extracted files to a zip, tar, or gzipped
+// tar archive rather than to the file system
+type archiveWriter struct {
+	base     string
+	filename string
+	f        *os.File
+	gz       *gzip.Writer
+	tw       *tar.Writer
+	zw       *zip.Writer
+}
+
+// newArchiveWriter creates the archive file. The type of archive is
+// determined by the extension of the file name. Files added to the
+// archive are named relative to the base directory.
+func newArchiveWriter(filename, base string) (*archiveWriter, error) {
+
+	a := archiveWriter{base: coalesce(base, "."), filename: filename}
+
+	var err error
+	a.f, err = os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		a.zw = zip.NewWriter(a.f)
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		a.gz = gzip.NewWriter(a.f)
+		a.tw = tar.NewWriter(a.gz)
+	case strings.HasSuffix(name, ".tar"):
+		a.tw = tar.NewWriter(a.f)
+	default:
+		a.f.Close()
+		os.Remove(filename)
+		return nil, fmt.Errorf("unknown archive type for %q, expected .zip, .tar, .tar.gz, or .tgz", filename)
+	}
+
+	return &a, nil
+}
+
+// add adds a file to the archive
+func (a *archiveWriter) add(filename string, b []byte) error {
+
+	name, err := filepath.Rel(a.base, filename)
+	if err != nil {
+		return err
+	}
+	name = filepath.ToSlash(name)
+
+	if a.zw != nil {
+		var w io.Writer
+		w, err = a.zw.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	}
+
+	err = a.tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(b)),
+		ModTime: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = a.tw.Write(b)
+	return err
+}
+
+// close finishes writing the archive
+func (a *archiveWriter) close() error {
+
+	var err error
+	if a.zw != nil {
+		err = a.zw.Close()
+	}
+	if a.tw != nil {
+		err = a.tw.Close()
+	}
+	if a.gz != nil && err == nil {
+		err = a.gz.Close()
+	}
+	if cerr := a.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// abort closes and removes the archive so that an incomplete archive is
+// not left behind when exiting on an error
+func (a *archiveWriter) abort() error {
+	a.close()
+	return os.Remove(a.filename)
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// checkpointFile is the name of the file, in the base directory, that
+// the progress of the extraction is recorded in
+const checkpointFile = ".oradex.checkpoint"
+
+// checkpointHeader starts the first line of the checkpoint file, which
+// records the options that the extraction was started with
+const checkpointHeader = "#options "
+
+// checkpointIgnored are the flags that do not affect what is extracted,
+// or how, and so may differ when resuming
+var checkpointIgnored = map[string]bool{
+	"c":              true,
+	"debug":          true,
+	"log-format":     true,
+	"log-level":      true,
+	"metrics":        true,
+	"password-stdin": true,
+	"pprof":          true,
+	"profile":        true,
+	"q":              true,
+	"resume":         true,
+	"retries":        true,
+	"retry-backoff":  true,
+	"strict":         true,
+	"summary":        true,
+	"timeout":        true,
+}
+
+// checkpointOptions returns the fingerprint of the flag settings that
+// affect the extraction, so that an extraction is not resumed with
+// options that differ from those it was started with. The configuration
+// file and profile settings have been applied to the flags by the time
+// this is called.
+func checkpointOptions() string {
+
+	var l []string
+	cmdFlags.VisitAll(func(f *flag.Flag) {
+		if !checkpointIgnored[f.Name] {
+			l = append(l, f.Name+"="+f.Value.String())
+		}
+	})
+	sort.Strings(l)
+
+	h := sha256.Sum256([]byte(strings.Join(l, "\n")))
+	return hex.EncodeToString(h[:])
+}
+
+// checkpoint records the objects that have been extracted so that an
+// interrupted extraction may be resumed
+type checkpoint struct {
+	mu       sync.Mutex
+	filename string
+	f        *os.File
+	done     map[string]bool
+}
+
+// ckpt is the checkpoint for the current extraction, or nil if there is
+// none
+var ckpt *checkpoint
+
+// openCheckpoint opens the checkpoint file in the base directory. When
+// resuming, the objects recorded by the previous extraction are loaded
+// and appended to, otherwise the file is started afresh. Resuming an
+// extraction that was started with different options (see
+// checkpointOptions) is an error.
+func openCheckpoint(base string, resume bool, options string) (*checkpoint, error) {
+
+	c := &checkpoint{
+		filename: filepath.Join(base, checkpointFile),
+		done:     make(map[string]bool),
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if resume {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+
+		f, err := os.Open(c.filename)
+		if err == nil {
+			s := bufio.NewScanner(f)
+			if s.Scan() && s.Text() != checkpointHeader+options {
+				f.Close()
+				return nil, fmt.Errorf("unable to resume as the options differ from those of the interrupted extraction, extract without -resume")
+			}
+			for s.Scan() {
+				c.done[s.Text()] = true
+			}
+			err = s.Err()
+			f.Close()
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	err := os.MkdirAll(filepath.Dir(c.filename), 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	c.f, err = os.OpenFile(c.filename, flags, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.done) == 0 {
+		if err = c.f.Truncate(0); err != nil {
+			c.f.Close()
+			return nil, err
+		}
+		_, err = fmt.Fprintln(c.f, checkpointHeader+options)
+		if err != nil {
+			c.f.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// checkpointKey returns the key that an object is recorded as
+func checkpointKey(v obj) string {
+	return strings.Join([]string{v.owner, v.objtype, v.objname}, "\t")
+}
+
+// completed returns true if the object was extracted by the extraction
+// that is being resumed
+func (c *checkpoint) completed(v obj) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.done[checkpointKey(v)]
+}
+
+// record records that the object has been extracted
+func (c *checkpoint) record(v obj) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	k := checkpointKey(v)
+	c.done[k] = true
+	_, err := fmt.Fprintln(c.f, k)
+	return err
+}
+
+// close closes the checkpoint file, removing it if the extraction was
+// completed
+func (c *checkpoint) close(completed bool) error {
+	if c == nil {
+		return nil
+	}
+
+	err := c.f.Close()
+	if err != nil || !completed {
+		return err
+	}
+	return os.Remove(c.filename)
+}
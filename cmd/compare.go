@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	dex "github.com/gsiems/oradex"
+)
+
+// compareSchema writes the DDL needed to convert the target schema, in
+// the form SCHEMA[@DBLINK], to match the source schema to stdout
+func compareSchema(ctx context.Context, db *sql.DB, schema, target string) {
+
+	if schema == "" || strings.Contains(schema, ",") {
+		failOnErr(fmt.Errorf("the compare command requires a single -s schema to compare"))
+	}
+
+	var link string
+	p := strings.SplitN(target, "@", 2)
+	if len(p) == 2 {
+		link = p[1]
+	}
+
+	changes, err := dex.CompareSchemas(ctx, db, strings.ToUpper(schema), strings.ToUpper(p[0]), link, ex.Options())
+	failOnErr(err)
+
+	for _, c := range changes {
+		fmt.Printf("%s\n\n", c)
+	}
+
+	if len(changes) > 0 {
+		drift = true
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	dex "github.com/gsiems/oradex"
+)
+
+// defaultConfigFile is the configuration file that is read, if it
+// exists, when no configuration file is specified
+const defaultConfigFile = ".oradex.toml"
+
+// rewriteRules are the DDL rewrite rules from the [[rewrite]] tables of
+// the configuration file
+var rewriteRules []dex.RewriteRule
+
+// renamedSettings are the configuration file keys that are no longer
+// accepted, along with what to use instead. The "exclude" alias used to
+// be for -x but would now otherwise be taken to be -exclude.
+var renamedSettings = map[string]string{
+	"exclude": "exclude_schemas (for -x) or exclude_names (for -exclude)",
+}
+
+// configAliases maps the more descriptive configuration file keys to
+// the corresponding command line flags
+var configAliases = map[string]string{
+	"base":            "b",
+	"database":        "d",
+	"exclude_names":   "exclude",
+	"exclude_schemas": "x",
+	"host":            "h",
+	"object":          "o",
+	"orapass_file":    "f",
+	"port":            "p",
+	"quiet":           "q",
+	"schemas":         "s",
+	"sys_exclude":     "X",
+	"user":            "u",
+}
+
+// profiles are the named presets of flag settings for -profile
+var profiles = map[string]map[string]string{
+	"minimal": {
+		"grants":    "false",
+		"sequences": "reset",
+		"storage":   "false",
+		"synonyms":  "false",
+	},
+	"full": {
+		"grants":          "true",
+		"lookup-data":     "true",
+		"received-grants": "true",
+		"storage":         "true",
+		"synonyms":        "true",
+	},
+	"code-only": {
+		"grants":   "false",
+		"storage":  "false",
+		"triggers": "separate",
+		"types":    "FUNCTION,PACKAGE,PROCEDURE,TRIGGER,VIEW",
+	},
+}
+
+// applyProfile sets the flags for the named preset (see profiles) that
+// were set neither on the command line nor in the configuration file.
+// Settings for flags that the command does not have are ignored.
+func applyProfile(name string) error {
+
+	if name == "" {
+		return nil
+	}
+
+	settings, ok := profiles[name]
+	if !ok {
+		var names []string
+		for k := range profiles {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown profile %q, expected one of %s", name, strings.Join(names, ", "))
+	}
+
+	set := make(map[string]bool)
+	cmdFlags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	for k, v := range settings {
+		if set[k] || cmdFlags.Lookup(k) == nil {
+			continue
+		}
+		err := cmdFlags.Set(k, v)
+		if err != nil {
+			return fmt.Errorf("profile %s: %s: %s", name, k, err)
+		}
+	}
+
+	return nil
+}
+
+// loadConfig reads the TOML configuration file and sets any flags that
+// were not explicitly set on the command line. Keys are either flag
+// names (with underscores in place of dashes) or one of the aliases in
+// configAliases. Tables (i.e. [connection], [extract]) are only for
+// organizing the file and are otherwise ignored. Arrays are joined into
+// comma separated lists. The [[rewrite]] array of tables holds the DDL
+// rewrite rules (see configRewriteRules). Settings for flags that only
+// other commands have are ignored.
+func loadConfig(filename string) error {
+
+	if filename == "" {
+		if _, err := os.Stat(defaultConfigFile); err != nil {
+			return nil
+		}
+		filename = defaultConfigFile
+	}
+
+	var m map[string]interface{}
+	_, err := toml.DecodeFile(filename, &m)
+	if err != nil {
+		return err
+	}
+
+	explicit := make(map[string]bool)
+	cmdFlags.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	settings := make(map[string]string)
+	err = flattenConfig(m, settings)
+	if err != nil {
+		return fmt.Errorf("%s: %s", filename, err)
+	}
+
+	// sorted so that errors are reported consistently
+	var keys []string
+	for k := range settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if r, ok := renamedSettings[k]; ok {
+			return fmt.Errorf("%s: the %q setting is no longer supported, use %s", filename, k, r)
+		}
+		name, ok := configAliases[k]
+		if !ok {
+			name = strings.Replace(k, "_", "-", -1)
+		}
+		if !knownFlag(name) {
+			return fmt.Errorf("%s: unknown setting %q", filename, k)
+		}
+		if cmdFlags.Lookup(name) == nil {
+			// a setting for another command
+			continue
+		}
+		if explicit[name] {
+			continue
+		}
+		err = cmdFlags.Set(name, settings[k])
+		if err != nil {
+			return fmt.Errorf("%s: %s: %s", filename, k, err)
+		}
+	}
+
+	return nil
+}
+
+// flattenConfig flattens the tables of a configuration into a single
+// set of key/value settings
+func flattenConfig(m map[string]interface{}, settings map[string]string) error {
+
+	for k, v := range m {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			err := flattenConfig(t, settings)
+			if err != nil {
+				return err
+			}
+		case []map[string]interface{}:
+			if k != "rewrite" {
+				return fmt.Errorf("unexpected array of tables %q", k)
+			}
+			l, err := configRewriteRules(t)
+			if err != nil {
+				return err
+			}
+			rewriteRules = append(rewriteRules, l...)
+		case []interface{}:
+			var l []string
+			for _, x := range t {
+				l = append(l, fmt.Sprint(x))
+			}
+			settings[k] = strings.Join(l, ",")
+		default:
+			if _, ok := settings[k]; ok {
+				return fmt.Errorf("duplicate setting %q", k)
+			}
+			settings[k] = fmt.Sprint(v)
+		}
+	}
+
+	return nil
+}
+
+// configRewriteRules returns the DDL rewrite rules for the [[rewrite]]
+// tables of a configuration. Each has a regular expression pattern, the
+// replacement, and an optional list of the object types to limit the
+// rule to.
+func configRewriteRules(l []map[string]interface{}) ([]dex.RewriteRule, error) {
+
+	var rules []dex.RewriteRule
+
+	for i, m := range l {
+		var pattern, replace string
+		var types []string
+
+		for k, v := range m {
+			switch k {
+			case "pattern":
+				pattern = fmt.Sprint(v)
+			case "replace":
+				replace = fmt.Sprint(v)
+			case "types":
+				switch t := v.(type) {
+				case []interface{}:
+					for _, x := range t {
+						types = append(types, strings.ToUpper(fmt.Sprint(x)))
+					}
+				default:
+					types = csvList(strings.ToUpper(fmt.Sprint(v)))
+				}
+			default:
+				return rules, fmt.Errorf("rewrite %d: unknown setting %q", i+1, k)
+			}
+		}
+		if pattern == "" {
+			return rules, fmt.Errorf("rewrite %d: no pattern specified", i+1)
+		}
+
+		r, err := dex.NewRewriteRule(pattern, replace, types)
+		if err != nil {
+			return rules, fmt.Errorf("rewrite %d: %s", i+1, err)
+		}
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/godror/godror"
+	orap "github.com/gsiems/orapass"
+)
+
+// dsnEnv is the environment variable for the DSN to connect with when
+// the -dsn flag is not specified
+const dsnEnv = "ORADEX_DSN"
+
+// connString returns the connect string for the database along with the
+// name to identify the database by. Unless a DSN is specified, the
+// database is resolved through TNS and the password for the user is
+// looked up (see getPasswd). A DSN (see -dsn) that includes the
+// credentials is used as is. Otherwise the DSN is the connect string
+// (i.e. an EZConnect string) and the password is looked up for the host,
+// port, and service of the connect string. With external authentication
+// (see -external) there is no password to look up as the credentials are
+// those of the wallet or of the OS user. A DSN of the form /@alias
+// implies external authentication.
+func connString(dbName string) (string, string, error) {
+
+	var p orap.Parser
+
+	p.Username = user
+	p.Host = host
+	p.Port = port
+	p.DbName = dbName
+	p.OrapassFile = orapassFile
+	p.Debug = debug
+
+	if externalAuth() {
+		return externalConnString(dbName)
+	}
+
+	if dsnStr == "" {
+		cp, err := getPasswd(p)
+		if err != nil {
+			return "", "", err
+		}
+		// NB that connStr asserts that the database can be resolved
+		// through TNS. The connection parameters, rather than a
+		// user/password@db string, allow for passwords containing @
+		P, err := parseDSN(cp.DbName)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid database: %s", err)
+		}
+		P.Username = proxyUser(cp.Username)
+		P.Password = godror.NewPassword(cp.Password)
+		P.ExternalAuth = false
+		return P.StringWithPassword(), cp.DbName, nil
+	}
+
+	P, err := parseDSN(dsnStr)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid DSN: %s", err)
+	}
+	if P.ConnectString == "" {
+		return "", "", fmt.Errorf("invalid DSN: no connect string")
+	}
+	if P.Username != "" && !P.Password.IsZero() {
+		if wallet == "" && proxy == "" {
+			return dsnStr, P.ConnectString, nil
+		}
+		P.Username = proxyUser(P.Username)
+		P.ConnectString, err = withWallet(P.ConnectString)
+		return P.StringWithPassword(), P.ConnectString, err
+	}
+
+	if P.Username != "" {
+		p.Username = P.Username
+	}
+	p.Host, p.Port, p.DbName = parseEZConnect(P.ConnectString)
+
+	cp, err := getPasswd(p)
+	if err != nil {
+		return "", "", err
+	}
+	P.Username = proxyUser(cp.Username)
+	P.Password = godror.NewPassword(cp.Password)
+	P.ExternalAuth = false
+	P.ConnectString, err = withWallet(P.ConnectString)
+
+	return P.StringWithPassword(), P.ConnectString, err
+}
+
+// externalAuth returns true if connecting with external authentication,
+// either for -external or for a DSN of the form /@alias
+func externalAuth() bool {
+	return external || strings.HasPrefix(dsnStr, "/@")
+}
+
+// externalConnString returns the connect string, for the DSN or else the
+// database, for connecting with external authentication (/@alias)
+func externalConnString(dbName string) (string, string, error) {
+
+	cs := dsnStr
+	if cs == "" {
+		cs = dbName
+	}
+	if cs == "" {
+		cs = os.Getenv("ORACLE_SID")
+	}
+
+	P, err := parseDSN(cs)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid DSN: %s", err)
+	}
+	if P.ConnectString == "" {
+		return "", "", fmt.Errorf("no database specified to connect to")
+	}
+
+	P.Username = proxyUser("")
+	P.Password = godror.NewPassword("")
+	P.ExternalAuth = true
+	P.ConnectString, err = withWallet(P.ConnectString)
+
+	return P.StringWithPassword(), P.ConnectString, err
+}
+
+// proxyUser returns the username for connecting as the user on behalf
+// of the proxy schema (see -proxy), i.e. user[schema]. With external
+// authentication the user is empty.
+func proxyUser(u string) string {
+	if proxy == "" {
+		return u
+	}
+	return fmt.Sprintf("%s[%s]", u, proxy)
+}
+
+// withWallet adds the wallet location (see -wallet) to an EZConnect
+// connect string. Requires a client that supports Easy Connect Plus. TNS
+// aliases and connect descriptors are returned as is as the wallet is
+// then specified by the sqlnet.ora in the TNS_ADMIN directory. Wallet
+// locations with spaces or other special characters are double quoted.
+func withWallet(cs string) (string, error) {
+
+	if wallet == "" {
+		return cs, nil
+	}
+	if h, _, _ := parseEZConnect(cs); h == "" {
+		return cs, nil
+	}
+
+	loc := wallet
+	if strings.Contains(loc, `"`) {
+		return "", fmt.Errorf("the wallet location %q can not contain double quotes", wallet)
+	}
+	if strings.ContainsAny(loc, " \t&?=#()") {
+		loc = `"` + loc + `"`
+	}
+
+	sep := "?"
+	if strings.Contains(cs, "?") {
+		sep = "&"
+	}
+	return cs + sep + "wallet_location=" + loc, nil
+}
+
+// setTNSAdmin sets the TNS_ADMIN environment variable, for the Oracle
+// client to read the tnsnames.ora and sqlnet.ora files from, before the
+// first connection
+func setTNSAdmin() error {
+	if tnsAdmin == "" {
+		return nil
+	}
+	return os.Setenv("TNS_ADMIN", tnsAdmin)
+}
+
+// parseDSN parses the DSN. DSNs that are only a connect string (an
+// EZConnect string, with or without Easy Connect Plus ?parameters, a TNS
+// alias, or a connect descriptor) are otherwise taken as a username and
+// password by godror. DSNs with credentials (user/password@...), URLs
+// (oracle://...), and the key=value form are parsed as is.
+func parseDSN(s string) (godror.ConnectionParams, error) {
+
+	s = strings.TrimSpace(s)
+
+	// The ?parameters of Easy Connect Plus are not key=value settings
+	base := s
+	if i := strings.IndexByte(s, '?'); i >= 0 {
+		base = s[:i]
+	}
+
+	if !strings.HasPrefix(s, "(") && (strings.HasPrefix(s, "oracle://") || strings.ContainsAny(base, "@= \t\n")) {
+		return godror.ParseConnString(s)
+	}
+
+	return godror.ParseConnString("/@" + s)
+}
+
+// parseEZConnect returns the host, port, and service name of an
+// EZConnect string ([//]host[:port][/service_name][:server][/instance]).
+// Connect descriptors and TNS aliases are returned as the service name.
+func parseEZConnect(s string) (host, port, service string) {
+
+	if strings.Contains(s, "(") || !strings.ContainsAny(s, ":/") {
+		return "", "", s
+	}
+
+	s = strings.TrimPrefix(s, "//")
+	if i := strings.IndexByte(s, '?'); i >= 0 {
+		s = s[:i]
+	}
+
+	hostPort := s
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		hostPort, service = s[:i], s[i+1:]
+		if j := strings.IndexAny(service, ":/"); j >= 0 {
+			service = service[:j]
+		}
+	}
+
+	host = hostPort
+	if i := strings.LastIndexByte(hostPort, ':'); i >= 0 && !strings.HasSuffix(hostPort, "]") {
+		host, port = hostPort[:i], hostPort[i+1:]
+	}
+	host = strings.Trim(host, "[]")
+
+	return host, port, service
+}
+
+// dsnFromEnv returns the DSN from the environment if neither a DSN nor
+// a database was specified
+func dsnFromEnv() string {
+	if dsnStr != "" || dbName != "" {
+		return dsnStr
+	}
+	return os.Getenv(dsnEnv)
+}
@@ -0,0 +1,96 @@
+package main
+
+import "testing"
+
+func TestParseDSN(t *testing.T) {
+
+	tests := []struct {
+		dsn          string
+		user         string
+		password     string
+		connect      string
+		externalAuth bool
+	}{
+		{"scott/tiger@dbhost:1521/orclpdb", "scott", "tiger", "dbhost:1521/orclpdb", false},
+		{"scott/tiger@orcl", "scott", "tiger", "orcl", false},
+		{`scott/p\@ss@orcl`, "scott", "p@ss", "orcl", false},
+		{"dbhost:1521/orclpdb", "", "", "dbhost:1521/orclpdb", true},
+		{"dbhost:1521/svc?connect_timeout=5&retry_count=3", "", "", "dbhost:1521/svc?connect_timeout=5&retry_count=3", true},
+		{"orcl", "", "", "orcl", true},
+		{" /@orcl ", "", "", "orcl", true},
+		{"(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=dbhost)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)))", "", "",
+			"(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=dbhost)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)))", true},
+		{`user=scott password=tiger connectString="dbhost:1521/orclpdb"`, "scott", "tiger", "dbhost:1521/orclpdb", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dsn, func(t *testing.T) {
+			P, err := parseDSN(tt.dsn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if P.Username != tt.user || P.Password.Secret() != tt.password || P.ConnectString != tt.connect {
+				t.Errorf("got %q/%q@%q, want %q/%q@%q", P.Username, P.Password.Secret(), P.ConnectString, tt.user, tt.password, tt.connect)
+			}
+			if P.ExternalAuth != tt.externalAuth {
+				t.Errorf("got external authentication %v, want %v", P.ExternalAuth, tt.externalAuth)
+			}
+		})
+	}
+}
+
+func TestParseEZConnect(t *testing.T) {
+
+	tests := []struct {
+		in                  string
+		host, port, service string
+	}{
+		{"dbhost:1521/orclpdb", "dbhost", "1521", "orclpdb"},
+		{"//dbhost/orclpdb:dedicated/orcl1", "dbhost", "", "orclpdb"},
+		{"dbhost:1521/svc?connect_timeout=5", "dbhost", "1521", "svc"},
+		{"[::1]:1521/orclpdb", "::1", "1521", "orclpdb"},
+		{"orcl", "", "", "orcl"},
+		{"(DESCRIPTION=(ADDRESS=(HOST=dbhost)))", "", "", "(DESCRIPTION=(ADDRESS=(HOST=dbhost)))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			host, port, service := parseEZConnect(tt.in)
+			if host != tt.host || port != tt.port || service != tt.service {
+				t.Errorf("got %q %q %q, want %q %q %q", host, port, service, tt.host, tt.port, tt.service)
+			}
+		})
+	}
+}
+
+func TestWithWallet(t *testing.T) {
+
+	tests := []struct {
+		wallet  string
+		cs      string
+		want    string
+		wantErr bool
+	}{
+		{"", "dbhost/orclpdb", "dbhost/orclpdb", false},
+		{"/opt/wallet", "orcl", "orcl", false},
+		{"/opt/wallet", "dbhost/orclpdb", "dbhost/orclpdb?wallet_location=/opt/wallet", false},
+		{"/opt/wallet", "dbhost/orclpdb?ssl_server_dn_match=yes", "dbhost/orclpdb?ssl_server_dn_match=yes&wallet_location=/opt/wallet", false},
+		{"/opt/my wallet (prod)", "dbhost/orclpdb", `dbhost/orclpdb?wallet_location="/opt/my wallet (prod)"`, false},
+		{`/opt/"wallet"`, "dbhost/orclpdb", "", true},
+	}
+
+	defer func(w string) { wallet = w }(wallet)
+
+	for _, tt := range tests {
+		t.Run(tt.wallet+"/"+tt.cs, func(t *testing.T) {
+			wallet = tt.wallet
+			got, err := withWallet(tt.cs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
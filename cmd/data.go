@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	dex "github.com/gsiems/oradex"
+)
+
+// schemaDataTables returns the tables, of those specified by the -data
+// flag, to export the data for in the specified schema along with, for
+// -lookup-data, the lookup tables of the schema (see dex.LookupTables).
+// Tables that are not qualified by a schema apply to all schemas.
+func schemaDataTables(ctx context.Context, db *sql.DB, schema string) ([]string, error) {
+
+	var l []string
+	seen := make(map[string]bool)
+	for _, v := range strings.Split(dataTables, ",") {
+		s, name := splitObjName(strings.TrimSpace(v))
+		if name == "" || seen[name] {
+			continue
+		}
+		if s == "" || s == schema {
+			seen[name] = true
+			l = append(l, name)
+		}
+	}
+
+	if !lookupData {
+		return l, nil
+	}
+
+	lookups, err := dex.LookupTables(ctx, db, schema)
+	if err != nil {
+		return l, err
+	}
+	for _, name := range lookups {
+		if !seen[name] {
+			seen[name] = true
+			l = append(l, name)
+		}
+	}
+	return l, nil
+}
+
+// dataObj returns the pseudo-object for the files that the data for a
+// table is written to
+func dataObj(schema, table string) obj {
+	return obj{owner: schema, objname: table, dirname: "DATA"}
+}
+
+// extractData writes the data for the tables in a schema that were
+// specified by the -data flag to CSV files along with the SQL*Loader
+// control files for loading the data
+func extractData(ctx context.Context, db *sql.DB, base, schema string) {
+
+	tables, err := schemaDataTables(ctx, db, schema)
+	if err != nil {
+		logError(err)
+		return
+	}
+
+	for _, table := range tables {
+
+		objType, err := dex.ObjType(ctx, db, schema, table)
+		if err != nil {
+			logError(err)
+			continue
+		}
+		if objType != "TABLE" {
+			// Unqualified tables need not exist in every schema
+			continue
+		}
+
+		csvFile, err := objFilename(base, dataObj(schema, table), "", "csv")
+		if err != nil {
+			logError(err)
+			continue
+		}
+		ctlFile, err := objFilename(base, dataObj(schema, table), "", "ctl")
+		if err != nil {
+			logError(err)
+			continue
+		}
+
+		var b bytes.Buffer
+		cols, skipped, _, err := dex.ExportTableData(ctx, &b, db, schema, table)
+		if err != nil {
+			logError(err)
+			continue
+		}
+		if len(skipped) > 0 {
+			warn("skipping the columns of unsupported data types", "schema", schema, "table", table, "columns", strings.Join(skipped, ", "))
+		}
+
+		label := fmt.Sprintf("%q.%q data", schema, table)
+		err = emit(label, csvFile, b.Bytes())
+		logError(err)
+
+		ctl, err := dex.LoaderControlFile(schema, table, filepath.Base(csvFile), encoding, cols)
+		if err != nil {
+			logError(err)
+			continue
+		}
+		// SQL*Loader reads the control file in the client character
+		// set, which can not be UTF-16
+		if cs, _ := dex.LoaderCharacterSet(encoding); strings.Contains(cs, "AL16UTF16") {
+			err = emitEncoded(label, ctlFile, []byte(ctl), "utf-8", false)
+		} else {
+			err = emit(label, ctlFile, []byte(ctl))
+		}
+		logError(err)
+	}
+}
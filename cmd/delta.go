@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	dex "github.com/gsiems/oradex"
+)
+
+// deltaState determines the objects that have changed since a previous
+// extraction, as recorded in its manifest, and collects the changes
+type deltaState struct {
+	mu      sync.Mutex
+	files   map[string]dex.ManifestEntry
+	objects map[string]dex.ManifestEntry
+	changes []string
+}
+
+// delta is the state for -delta, or nil if not extracting the changes
+// only
+var delta *deltaState
+
+func newDeltaState(m dex.Manifest) *deltaState {
+
+	d := &deltaState{
+		files:   make(map[string]dex.ManifestEntry),
+		objects: make(map[string]dex.ManifestEntry),
+	}
+	for _, e := range m.Objects {
+		d.files[e.File] = e
+		d.objects[deltaKey(e.Schema, e.Name, e.ObjType)] = e
+	}
+	return d
+}
+
+// deltaKey returns the key for an object in the previous manifest
+func deltaKey(schema, name, objType string) string {
+	return schema + "\t" + dex.InventoryKey(name, objType)
+}
+
+// filter returns the objects that are new, or that have a different
+// last DDL time, compared with the previous manifest. Objects that are in
+// the previous manifest but that no longer exist are reported as
+// removed.
+func (d *deltaState) filter(ctx context.Context, db *sql.DB, schema string, l []obj) ([]obj, error) {
+
+	inv, err := dex.SchemaInventory(ctx, db, schema)
+	if err != nil {
+		return l, err
+	}
+
+	var f []obj
+	current := make(map[string]bool)
+	for _, v := range l {
+		key := deltaKey(v.owner, v.objname, v.objtype)
+		current[key] = true
+
+		e, ok := d.objects[key]
+		if !ok {
+			f = append(f, v)
+			continue
+		}
+
+		item, ok := inv[dex.InventoryKey(v.objname, v.objtype)]
+		if !ok && v.objtype == "QUEUE TABLE" {
+			item, ok = inv[dex.InventoryKey(v.objname, "TABLE")]
+		}
+		if !ok || !item.LastDDLTime.UTC().Equal(e.LastDDLTime) {
+			f = append(f, v)
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for key, e := range d.objects {
+		if e.Schema == schema && !current[key] {
+			d.changes = append(d.changes, strings.Join([]string{"removed", e.Schema, e.ObjType, e.Name, e.File}, "\t"))
+		}
+	}
+
+	return f, nil
+}
+
+// record compares a file written for an object with the previous
+// manifest. Files with a different checksum are changed while those with
+// the same checksum (for objects whose last DDL time changed without a
+// change to the DDL) are unchanged.
+func (d *deltaState) record(v obj, file, checksum string) {
+	if d == nil {
+		return
+	}
+
+	status := "added"
+	if e, ok := d.files[file]; ok {
+		status = "changed"
+		if e.SHA256 == checksum {
+			status = "unchanged"
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.changes = append(d.changes, strings.Join([]string{status, v.owner, v.objtype, v.objname, file}, "\t"))
+}
+
+// writeReport writes the changes, one tab separated line of the status,
+// schema, object type, object name, and file per file, sorted by file
+func (d *deltaState) writeReport(w io.Writer) error {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	sort.Slice(d.changes, func(i, j int) bool {
+		fi := d.changes[i][strings.LastIndex(d.changes[i], "\t")+1:]
+		fj := d.changes[j][strings.LastIndex(d.changes[j], "\t")+1:]
+		return fi < fj
+	})
+
+	for _, c := range d.changes {
+		_, err := fmt.Fprintln(w, c)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
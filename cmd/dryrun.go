@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// dryRunSchemas writes the owner, type, name, and file path(s) of each
+// of the objects that would be extracted for the schemas to stdout
+// without extracting them
+func dryRunSchemas(ctx context.Context, db *sql.DB, base string, since time.Time) {
+
+	l, err := getSchemaList(ctx, schemas, xclude)
+	failOnErr(err)
+
+	for _, schema := range l {
+		var objs []obj
+		if since.IsZero() {
+			objs, err = getObjList(ctx, db, schema)
+		} else {
+			objs, err = getChangedObjList(ctx, db, schema, since)
+		}
+		if err != nil {
+			logError(err)
+			continue
+		}
+
+		for _, v := range filterObjList(objs) {
+			var files []string
+			if singleFile {
+				files = []string{filepath.Join(base, outName(safeName(schema))+".sql")}
+			} else {
+				files, err = objFiles(base, v)
+				if err != nil {
+					logError(err)
+					continue
+				}
+			}
+			fmt.Printf("%s\t%s\t%s\t%s\n", v.owner, v.objtype, v.objname, strings.Join(files, ","))
+		}
+	}
+}
@@ -0,0 +1,846 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/godror/godror"
+	dex "github.com/gsiems/oradex"
+)
+
+// extractDatabase connects to the database and extracts the database or,
+// if pluggable databases are specified, each of the pluggable databases
+func extractDatabase(ctx context.Context, dbName, base string, opts dex.ExportOptions, sinceTime time.Time) {
+
+	connStr, dbLabel, err := connString(dbName)
+	exitOnErr(exitConnect, err)
+	gitInfo.DbName = dbLabel
+
+	// The DBMS_METADATA transform parameters are session settings so are
+	// set for each session in the pool, not just the one that the
+	// extractor is initialized with
+	db, err := openDB(connStr, dex.SessionInitSQL(opts))
+	exitOnErr(exitConnect, err)
+	defer func() {
+		if cerr := db.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+	exitOnErr(exitConnect, db.PingContext(ctx))
+
+	if pdbs == "" {
+		extractConnected(ctx, db, base, opts, sinceTime)
+		return
+	}
+
+	l, err := getPdbList(ctx, db, pdbs)
+	failOnErr(err)
+
+	for _, pdb := range l {
+		gitInfo.DbName = dbLabel + "/" + pdb
+
+		// Each PDB uses a separate pool of sessions that are switched to
+		// the PDB, before setting the transform parameters, when created
+		pdbDB, err := openDB(connStr, dex.SetContainerSQL(pdb), dex.SessionInitSQL(opts))
+		exitOnErr(exitConnect, err)
+		exitOnErr(exitConnect, pdbDB.PingContext(ctx))
+
+		extractConnected(ctx, pdbDB, filepath.Join(base, pdb), opts, sinceTime)
+		logError(pdbDB.Close())
+
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// openDB opens the database, running the onInit statements for each new
+// session
+func openDB(connStr string, onInit ...string) (*sql.DB, error) {
+
+	P, err := godror.ParseConnString(connStr)
+	if err != nil {
+		return nil, err
+	}
+	P.OnInitStmts = append(P.OnInitStmts, onInit...)
+	P.IsSysDBA = P.IsSysDBA || connectAs == "sysdba"
+	P.IsSysOper = P.IsSysOper || connectAs == "sysoper"
+	// godror only infers external authentication for pooled connections
+	// without a username, which excludes proxy ([schema]/@alias) and
+	// administrative connections
+	if externalAuth() {
+		P.ExternalAuth = true
+	}
+	// godror drops the username when acquiring a connection from a
+	// homogeneous pool, which would drop the [schema] of the proxy
+	if proxy != "" {
+		P.Heterogeneous = true
+	}
+
+	return sql.OpenDB(godror.NewConnector(P)), nil
+}
+
+// getPdbList returns the open pluggable databases, of those in the
+// comma separated list (or all of them), for the CDB
+func getPdbList(ctx context.Context, db *sql.DB, pdbs string) ([]string, error) {
+
+	l, err := dex.PluggableDatabases(ctx, db)
+	if err != nil || strings.EqualFold(pdbs, "all") {
+		return l, err
+	}
+
+	var f []string
+	for _, pdb := range strings.Split(pdbs, ",") {
+		pdb = strings.ToUpper(strings.TrimSpace(pdb))
+		if !contains(l, pdb) {
+			return f, fmt.Errorf("no open pluggable database found for %q", pdb)
+		}
+		f = append(f, pdb)
+	}
+	return f, nil
+}
+
+// extractConnected performs the comparison, or the extraction of the
+// schema(s) or object, for the connected database
+func extractConnected(ctx context.Context, db *sql.DB, base string, opts dex.ExportOptions, sinceTime time.Time) {
+
+	var err error
+
+	if preflight {
+		preflightCheck(ctx, db)
+		return
+	}
+
+	// The data dictionary settings are carried by the context for the
+	// queries made for this database
+	var dict dex.Dictionary
+	switch {
+	case compat:
+		l, err := dex.DetectMissingViews(ctx, db)
+		failOnErr(err)
+		if len(l) > 0 {
+			warn("no access to some dba_* views, using the all_* views instead", "views", strings.Join(l, ", "))
+			dict.MissingViews = make(map[string]bool)
+			for _, v := range l {
+				dict.MissingViews[v] = true
+			}
+		}
+	case noDba || !dex.HasDbaViews(ctx, db):
+		if !noDba {
+			warn("no access to the dba_* views, using the all_* views instead")
+		}
+		dict.AllViews = true
+	}
+	if extraXclude != "" {
+		dict.SystemSchemas = dex.AddExcludedSchemas(dex.ExcludedSchemas(), strings.Split(extraXclude, ","))
+	}
+	ctx = dex.WithDictionary(ctx, dict)
+
+	// The version dependent queries are gated on the version, which is
+	// assumed to be current if it can not be determined
+	dict.Version, err = dex.DbVersion(ctx, db)
+	logError(err)
+	ctx = dex.WithDictionary(ctx, dict)
+
+	// Dry runs and listings do not initialize DBMS_METADATA
+	ex = dex.NewExtractor(db, opts)
+	switch {
+	case dryRun:
+		dryRunSchemas(ctx, db, base, sinceTime)
+		return
+	case listMode:
+		listObjects(ctx, db)
+		return
+	}
+
+	err = ex.Init(ctx)
+	failOnErr(err)
+
+	// comparison, database, schema(s), or object?
+	switch {
+	case serveMode:
+		serveDDL(ctx, db, listen)
+
+	case compareTo != "":
+		compareSchema(ctx, db, schemas, compareTo)
+
+	case graph != "":
+		graphSchemas(ctx, db, schemas, xclude)
+
+	case objectName == "":
+		// Changes made while extracting are picked up by the first poll
+		var watchFrom time.Time
+		if watch > 0 {
+			watchFrom, err = dbTime(ctx, db)
+			failOnErr(err)
+		}
+
+		// Directory extractions record their progress so that they may be
+		// resumed if interrupted
+		if !check && !singleFile && archive == nil {
+			ckpt, err = openCheckpoint(base, resume, checkpointOptions())
+			failOnErr(err)
+		}
+
+		// The manifest lists the files, with their checksums, that are
+		// written for the objects
+		if !check && !singleFile {
+			manifest = newManifestWriter(base)
+		}
+
+		extractSchemas(ctx, db, schemas, xclude, base, sinceTime, prune)
+		logError(ckpt.close(ctx.Err() == nil))
+		ckpt = nil
+		logError(manifest.write())
+		manifest = nil
+
+		if roles {
+			extractRoles(ctx, db, base)
+		}
+		if lockdown {
+			extractLockdownProfiles(ctx, db, base)
+		}
+		if scheduler {
+			extractSchedulerObjects(ctx, db, base)
+		}
+		if gitCommitOn {
+			commitChanges(base)
+		}
+		if watch > 0 {
+			watchSchemas(ctx, db, base, watchFrom, prune)
+		}
+
+	default:
+		schema, name := splitObjName(objectName)
+		schema = coalesce(schema, schemas)
+		extractObject(ctx, db, schema, name)
+	}
+}
+
+// extractSchemas extracts the database objects for a list of schemas
+func extractSchemas(ctx context.Context, db *sql.DB, schemas, xclude, base string, since time.Time, prune bool) {
+
+	l, err := getSchemaList(ctx, schemas, xclude)
+	failOnErr(err)
+
+	var dirs []string
+	var schemaRoleList []string
+	var tsList []string
+
+	// collect adds the names not already in the list to the list
+	collect := func(l []string, names []string, err error) []string {
+		logError(err)
+		for _, name := range names {
+			if !contains(l, name) {
+				l = append(l, name)
+			}
+		}
+		return l
+	}
+
+	var timedOut []dex.ObjectError
+
+	for _, schema := range l {
+		res := extractSchema(ctx, db, base, schema, since, prune)
+		summary.add(gitInfo.DbName, res)
+		if len(res.Missing()) > 0 {
+			partial = true
+		}
+		info("extracted schema", "schema", res.Schema, "objects", res.ObjectCount, "failed", len(res.FailedObjects),
+			"missing", len(res.Missing()), "timed_out", len(res.TimedOut()), "elapsed", res.Elapsed.Round(time.Millisecond))
+		timedOut = append(timedOut, res.TimedOut()...)
+
+		if dataTables != "" || lookupData {
+			extractData(ctx, db, base, schema)
+		}
+
+		reportInvalid(ctx, db, schema)
+		if recompile != "" {
+			extractRecompile(ctx, db, base, schema)
+		}
+		if uninstall {
+			extractUninstall(ctx, db, base, schema)
+		}
+		if toggle {
+			extractToggleScripts(ctx, db, base, schema)
+		}
+
+		if directories {
+			d, err := dex.SchemaDirectories(ctx, db, schema)
+			dirs = collect(dirs, d, err)
+		}
+
+		if tablespaces {
+			t, err := dex.SchemaTablespaces(ctx, db, schema)
+			tsList = collect(tsList, t, err)
+		}
+
+		// Extracting all roles supersedes extracting the schema roles
+		if schemaRoles && !roles {
+			r, err := dex.SchemaRoles(ctx, db, schema)
+			schemaRoleList = collect(schemaRoleList, r, err)
+		}
+
+		if sizeReport {
+			r, err := dex.SchemaSizeReport(ctx, db, schema)
+			if err != nil {
+				logError(err)
+				continue
+			}
+			logError(r.WriteTable(os.Stdout))
+		}
+	}
+
+	if users {
+		extractDbObjects(ctx, db, filepath.Join(base, "USERS"), l, dex.ObjUser)
+	}
+
+	if sysPrivs {
+		extractDbObjects(ctx, db, filepath.Join(base, "SYSTEM_PRIVILEGES"), l, dex.SchemaSysPrivs)
+	}
+
+	sort.Strings(dirs)
+	extractDbObjects(ctx, db, filepath.Join(base, "DIRECTORIES"), dirs, dex.ObjDirectory)
+
+	sort.Strings(tsList)
+	extractDbObjects(ctx, db, filepath.Join(base, "TABLESPACES"), tsList, dex.ObjTablespace)
+
+	sort.Strings(schemaRoleList)
+	extractDbObjects(ctx, db, filepath.Join(base, "ROLES"), schemaRoleList, dex.ObjRole)
+
+	if install {
+		writeInstall(ctx, db, base, l)
+	}
+
+	for _, e := range timedOut {
+		warn("skipped the object as it timed out", "schema", e.Schema, "name", e.Name, "type", e.ObjType)
+	}
+}
+
+// extractSchema extracts the database objects for a schema. If since is
+// set then only those objects that have changed since then are extracted.
+func extractSchema(ctx context.Context, db *sql.DB, base, schema string, since time.Time, prune bool) dex.ExtractionResult {
+
+	var l []obj
+	var schemaGrants []string
+	var err error
+
+	start := time.Now()
+	res := dex.ExtractionResult{Schema: schema}
+
+	// Whether all of the objects, rather than only the changed ones, are
+	// extracted
+	full := since.IsZero() && delta == nil
+
+	if since.IsZero() {
+		l, err = getObjList(ctx, db, schema)
+	} else {
+		l, err = getChangedObjList(ctx, db, schema, since)
+	}
+	failOnErr(err)
+
+	l = filterObjList(l)
+
+	if prune || reportStale || check {
+		pruneSchema(ctx, db, base, schema, prune)
+	}
+
+	if delta != nil {
+		l, err = delta.filter(ctx, db, schema, l)
+		failOnErr(err)
+	}
+
+	if len(l) == 0 {
+		if full {
+			logError(fmt.Errorf("no objects returned for %q", schema))
+		}
+		res.Elapsed = time.Since(start)
+		return res
+	}
+
+	if received {
+		extractReceivedGrants(ctx, base, schema)
+	}
+
+	if sequences == "restart" {
+		if full {
+			extractSequenceRestarts(ctx, base, schema, l)
+		} else {
+			warn("not writing the sequence restarts as only the changed objects were extracted", "schema", schema)
+		}
+	}
+
+	if singleFile {
+		extractSingleFile(ctx, db, base, schema, l, &res)
+		res.Elapsed = time.Since(start)
+		return res
+	}
+
+	for _, v := range l {
+
+		if ckpt.completed(v) {
+			// The grants are still needed for the schema grants file
+			if grantsFile == "schema" && separateGrants() {
+				grants, err := ex.ExportGrants(ctx, v.owner, v.objname, v.objtype)
+				logError(err)
+				schemaGrants = appendGrants(schemaGrants, grants)
+			}
+			res.Skipped++
+			continue
+		}
+
+		if splitSpecBody(v) {
+			objRes, err := extractSpecBody(ctx, base, v)
+			res.Add(objRes, err)
+			stats.observe(v.objtype, objRes, err)
+			if err != nil {
+				continue
+			}
+		} else if splitTable(v) {
+			objRes, err := extractTableParts(ctx, base, v)
+			res.Add(objRes, err)
+			stats.observe(v.objtype, objRes, err)
+			if err != nil {
+				continue
+			}
+		} else {
+			b, objRes, err := exportObj(ctx, v)
+			res.Add(objRes, err)
+			stats.observe(v.objtype, objRes, err)
+			if err != nil {
+				logError(err)
+				continue
+			}
+
+			filename, err := objFilename(base, v, "", outputExt(v.objtype))
+			if err != nil {
+				logError(err)
+				continue
+			}
+
+			b = sqlplusScript(b, v.objtype, v.owner, v.objname)
+			err = emit(fmt.Sprintf("%q.%q", v.owner, v.objname), filename, b)
+			if err != nil {
+				// Not recorded as completed so that resuming retries it
+				failObject(err)
+				continue
+			}
+			manifest.add(v, filename, b)
+		}
+
+		if drop == "file" {
+			err = extractDrop(base, v)
+			logError(err)
+		}
+
+		if separateGrants() {
+			grants, err := ex.ExportGrants(ctx, v.owner, v.objname, v.objtype)
+			if err != nil {
+				logError(err)
+				continue
+			}
+			switch grantsFile {
+			case "object":
+				err = extractGrants(base, v, grants)
+				logError(err)
+			case "schema":
+				schemaGrants = appendGrants(schemaGrants, grants)
+			}
+		}
+
+		logError(ckpt.record(v))
+	}
+
+	logError(manifest.setDDLTimes(ctx, db, schema))
+
+	if grantsFile == "schema" && separateGrants() {
+		if full {
+			err = extractGrants(base, schemaGrantsObj(schema), strings.Join(schemaGrants, "\n\n"))
+			logError(err)
+		} else {
+			warn("not writing the schema grants as only the changed objects were extracted", "schema", schema)
+		}
+	}
+
+	res.Elapsed = time.Since(start)
+	return res
+}
+
+// extractSingleFile extracts the database objects for a schema, in
+// dependency order, to a single <schema>.sql file
+func extractSingleFile(ctx context.Context, db *sql.DB, base, schema string, l []obj, res *dex.ExtractionResult) {
+
+	// Objects of different types may share a name (i.e. a table and a
+	// trigger) so there may be more than one object for a name
+	var q []dex.QualifiedName
+	objs := make(map[dex.QualifiedName][]obj)
+	for _, v := range l {
+		n := dex.QualifiedName{Schema: v.owner, Name: v.objname}
+		if _, ok := objs[n]; !ok {
+			q = append(q, n)
+		}
+		objs[n] = append(objs[n], v)
+	}
+
+	ordered, err := dex.OrderByDependency(ctx, db, q)
+	if err != nil {
+		logError(err)
+		ordered = q
+	}
+
+	var b bytes.Buffer
+	var drops, grants []string
+	if sqlplus {
+		b.WriteString(sqlplusHeader)
+	}
+	for _, n := range ordered {
+		for _, v := range objs[n] {
+			objDDL, objRes, err := exportObj(ctx, v)
+			res.Add(objRes, err)
+			stats.observe(v.objtype, objRes, err)
+			if err != nil {
+				logError(err)
+				continue
+			}
+
+			if drop == "file" {
+				drops = append(drops, dex.DropDDL(v.owner, v.objname, v.objtype, dropCascade))
+			}
+			if separateGrants() {
+				g, err := ex.ExportGrants(ctx, v.owner, v.objname, v.objtype)
+				logError(err)
+				grants = appendGrants(grants, g)
+			}
+
+			fmt.Fprintf(&b, "-- %s \"%s\".\"%s\"\n", strings.ToLower(v.objtype), v.owner, v.objname)
+			if sqlplus {
+				b.WriteString(sqlplusObject(string(objDDL), v.objtype, v.owner, v.objname))
+				continue
+			}
+			if prompts {
+				fmt.Fprintf(&b, "PROMPT %s \"%s\".\"%s\"\n", strings.ToLower(v.objtype), v.owner, v.objname)
+			}
+			b.Write(objDDL)
+		}
+	}
+
+	filename := filepath.Join(base, outName(safeName(schema))+".sql")
+	escapedNames.add(filename, schema, "")
+
+	err = emit(fmt.Sprintf("%q", schema), filename, b.Bytes())
+	logError(err)
+
+	// The objects are dropped in the reverse of the order that they are
+	// created in
+	if drop == "file" {
+		for i, j := 0, len(drops)-1; i < j; i, j = i+1, j-1 {
+			drops[i], drops[j] = drops[j], drops[i]
+		}
+		filename := filepath.Join(base, outName(safeName(schema))+".drop.sql")
+		b := sqlplusScript([]byte(strings.Join(drops, "\n")+"\n\n"), "DROP", "", schema)
+		err = emit(fmt.Sprintf("%q drop", schema), filename, b)
+		logError(err)
+	}
+
+	if separateGrants() {
+		filename := filepath.Join(base, outName(safeName(schema))+".grants.sql")
+		label := fmt.Sprintf("%q grants", schema)
+		if len(grants) == 0 {
+			err = unemit(label, filename)
+		} else {
+			b := sqlplusScript([]byte(strings.Join(grants, "\n\n")+"\n\n"), "GRANTS", "", schema)
+			err = emit(label, filename, b)
+		}
+		logError(err)
+	}
+}
+
+// extractSpecBody extracts the specification and body of a package or
+// type to separate files
+func extractSpecBody(ctx context.Context, base string, v obj) (dex.ObjectResult, error) {
+
+	exportFn := ex.ExportPackageDDL
+	if v.objtype == "TYPE" {
+		exportFn = ex.ExportTypeDDL
+	}
+
+	specDDL, bodyDDL, res, err := exportFn(ctx, v.owner, v.objname)
+	if err != nil {
+		logError(err)
+		return res, err
+	}
+
+	specFile, bodyFile, err := specBodyFilenames(base, v)
+	if err != nil {
+		logError(err)
+		return res, err
+	}
+
+	// The errors writing the files are returned so that the object is
+	// not recorded as completed and resuming retries it
+	b := sqlplusScript([]byte(specDDL+"\n\n"), v.objtype, v.owner, v.objname)
+	err = emit(fmt.Sprintf("%q.%q", v.owner, v.objname), specFile, b)
+	if err != nil {
+		logError(err)
+		return res, err
+	}
+	manifest.add(v, specFile, b)
+
+	if bodyDDL != "" {
+		b = sqlplusScript([]byte(bodyDDL+"\n\n"), v.objtype+" BODY", v.owner, v.objname)
+		err = emit(fmt.Sprintf("%q.%q body", v.owner, v.objname), bodyFile, b)
+		if err != nil {
+			logError(err)
+			return res, err
+		}
+		manifest.add(v, bodyFile, b)
+	}
+
+	return res, nil
+}
+
+// extractTableParts extracts a table, or materialized view, with the
+// foreign key and check constraints and the indexes written to separate
+// files. There are no constraint or index files for tables without
+// them, unless the files already exist.
+func extractTableParts(ctx context.Context, base string, v obj) (dex.ObjectResult, error) {
+
+	tableDDL, constraintDDL, indexDDL, res, err := ex.ExportTableDDL(ctx, v.owner, v.objname, v.objtype)
+	if err != nil {
+		logError(err)
+		return res, err
+	}
+
+	filename, err := objFilename(base, v, "", outputExt(v.objtype))
+	if err != nil {
+		logError(err)
+		return res, err
+	}
+	constraintFile, indexFile, err := tablePartFilenames(base, v)
+	if err != nil {
+		logError(err)
+		return res, err
+	}
+
+	// The errors writing the files are returned so that the object is
+	// not recorded as completed and resuming retries it
+	b := sqlplusScript([]byte(tableDDL+"\n\n"), v.objtype, v.owner, v.objname)
+	err = emit(fmt.Sprintf("%q.%q", v.owner, v.objname), filename, b)
+	if err != nil {
+		logError(err)
+		return res, err
+	}
+	manifest.add(v, filename, b)
+
+	parts := []struct {
+		label    string
+		filename string
+		ddl      string
+	}{
+		{"constraints", constraintFile, constraintDDL},
+		{"indexes", indexFile, indexDDL},
+	}
+	for _, p := range parts {
+		if p.ddl == "" {
+			if _, err := os.Stat(p.filename); err != nil {
+				continue
+			}
+		}
+		b = sqlplusScript([]byte(p.ddl+"\n\n"), strings.ToUpper(p.label)+" ON "+v.objtype, v.owner, v.objname)
+		err = emit(fmt.Sprintf("%q.%q %s", v.owner, v.objname, p.label), p.filename, b)
+		if err != nil {
+			logError(err)
+			return res, err
+		}
+		manifest.add(v, p.filename, b)
+	}
+
+	return res, nil
+}
+
+// extractDrop writes the DROP statement for an object to a separate file
+func extractDrop(base string, v obj) error {
+
+	filename, err := objFilename(base, v, ".drop", "sql")
+	if err != nil {
+		return err
+	}
+
+	dropDDL := dex.DropDDL(v.owner, v.objname, v.objtype, dropCascade)
+
+	b := sqlplusScript([]byte(dropDDL+"\n\n"), "DROP "+v.objtype, v.owner, v.objname)
+	return emit(fmt.Sprintf("%q.%q drop", v.owner, v.objname), filename, b)
+}
+
+// exportObj exports an object in the output format
+func exportObj(ctx context.Context, v obj) ([]byte, dex.ObjectResult, error) {
+
+	if format == "sxml" || format == "xml" {
+		m, res, err := ex.ExportMetadata(ctx, v.owner, v.objname, v.objtype, format)
+		if err != nil {
+			return nil, res, err
+		}
+		return []byte(m + "\n"), res, nil
+	}
+
+	if format == "json" {
+		o, res, err := ex.ExportObjectOfType(ctx, v.owner, v.objname, v.objtype)
+		if err != nil {
+			return nil, res, err
+		}
+		b, err := json.MarshalIndent(o, "", "  ")
+		return append(b, '\n'), res, err
+	}
+
+	objDDL, res, err := ex.ExportDDL(ctx, v.owner, v.objname, v.objtype)
+	if err != nil {
+		return nil, res, err
+	}
+	return []byte(objDDL + "\n\n"), res, nil
+}
+
+// outputExt returns the file extension for the output format
+func outputExt(objType string) string {
+	switch format {
+	case "json", "sxml", "xml":
+		return format
+	}
+	if typeExt {
+		if ext, ok := typeExts[objType]; ok {
+			return ext
+		}
+	}
+	return "sql"
+}
+
+// typeExts are the file extensions, by object type, for -type-ext
+var typeExts = map[string]string{
+	"FUNCTION":          "fnc",
+	"MATERIALIZED VIEW": "mv",
+	"PACKAGE":           "pkg",
+	"PROCEDURE":         "prc",
+	"SEQUENCE":          "seq",
+	"SYNONYM":           "syn",
+	"TABLE":             "tab",
+	"TRIGGER":           "trg",
+	"TYPE":              "typ",
+	"VIEW":              "vw",
+}
+
+// writtenExt returns true if the file extension, including the leading
+// dot, is one of the extensions that oradex writes files with
+func writtenExt(ext string) bool {
+	ext = strings.ToLower(strings.TrimPrefix(ext, "."))
+	switch ext {
+	case "sql", "json", "sxml", "xml", "pks", "pkb", "tps", "tpb", "csv", "ctl":
+		return true
+	}
+	for _, v := range typeExts {
+		if ext == v {
+			return true
+		}
+	}
+	return false
+}
+
+// outName returns the directory or file name, in lowercase for -lower
+func outName(s string) string {
+	if lowerNames {
+		return strings.ToLower(s)
+	}
+	return s
+}
+
+// reportInvalid reports the objects in a schema that are invalid
+func reportInvalid(ctx context.Context, db *sql.DB, schema string) {
+
+	l, err := dex.InvalidObjects(ctx, db, schema)
+	if err != nil {
+		logError(err)
+		return
+	}
+	for _, v := range l {
+		logError(fmt.Errorf("invalid %s %q.%q", strings.ToLower(v.ObjType), v.Schema, v.Name))
+	}
+}
+
+// extractRoles extracts the (non-Oracle maintained) database roles
+func extractRoles(ctx context.Context, db *sql.DB, base string) {
+
+	l, err := getRoleList(ctx, db)
+	failOnErr(err)
+
+	extractDbObjects(ctx, db, filepath.Join(base, "ROLES"), l, dex.ObjRole)
+}
+
+// extractLockdownProfiles extracts the PDB lockdown profiles
+func extractLockdownProfiles(ctx context.Context, db *sql.DB, base string) {
+
+	if !dex.ViewAvailable(ctx, "dba_lockdown_profiles") {
+		warn("no access to dba_lockdown_profiles, not extracting the lockdown profiles")
+		return
+	}
+
+	l, err := getNameList(ctx, db, "SELECT DISTINCT profile_name FROM dba_lockdown_profiles ORDER BY 1")
+	failOnErr(err)
+
+	extractDbObjects(ctx, db, filepath.Join(base, "LOCKDOWN_PROFILES"), l, dex.ObjLockdownProfile)
+}
+
+// extractSchedulerObjects extracts the (non-Oracle maintained)
+// scheduler job classes and windows
+func extractSchedulerObjects(ctx context.Context, db *sql.DB, base string) {
+
+	query := `
+SELECT o.object_name
+    FROM dba_objects o
+    WHERE o.owner = 'SYS'
+        AND o.object_type = '%s'
+        AND %s
+    ORDER BY o.object_name
+`
+
+	l, err := getNameList(ctx, db, fmt.Sprintf(query, "JOB CLASS", dex.OracleMaintainedClause(ctx, "o", "object_name")))
+	failOnErr(err)
+
+	extractDbObjects(ctx, db, filepath.Join(base, "JOB_CLASSES"), l, dex.ObjJobClass)
+
+	l, err = getNameList(ctx, db, fmt.Sprintf(query, "WINDOW", dex.OracleMaintainedClause(ctx, "o", "object_name")))
+	failOnErr(err)
+
+	extractDbObjects(ctx, db, filepath.Join(base, "WINDOWS"), l, dex.ObjWindow)
+}
+
+// extractDbObjects extracts database level (non-schema) objects to
+// the specified directory
+func extractDbObjects(ctx context.Context, db *sql.DB, dir string, l []string, ddlFunc func(context.Context, dex.Querier, string) (string, error)) {
+
+	if len(l) == 0 {
+		return
+	}
+
+	dir = filepath.Join(filepath.Dir(dir), outName(filepath.Base(dir)))
+
+	for _, name := range l {
+		objDDL, err := ddlFunc(ctx, db, name)
+		if err != nil {
+			failObject(err)
+			continue
+		}
+
+		filename := fmt.Sprintf("%s.sql", filepath.Join(dir, outName(safeName(name))))
+		escapedNames.add(filename, "", name)
+
+		b := sqlplusScript([]byte(objDDL+"\n\n"), "", "", name)
+		err = emit(fmt.Sprintf("%q", name), filename, b)
+		failObject(err)
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	dex "github.com/gsiems/oradex"
+)
+
+// objFiles returns the names of all the files that are written for an
+// object
+func objFiles(base string, v obj) ([]string, error) {
+
+	var l []string
+
+	if splitSpecBody(v) {
+		specFile, bodyFile, err := specBodyFilenames(base, v)
+		if err != nil {
+			return l, err
+		}
+		l = append(l, specFile, bodyFile)
+	} else {
+		filename, err := objFilename(base, v, "", outputExt(v.objtype))
+		if err != nil {
+			return l, err
+		}
+		l = append(l, filename)
+	}
+
+	if splitTable(v) {
+		constraintFile, indexFile, err := tablePartFilenames(base, v)
+		if err != nil {
+			return l, err
+		}
+		l = append(l, constraintFile, indexFile)
+	}
+
+	if drop == "file" {
+		filename, err := objFilename(base, v, ".drop", "sql")
+		if err != nil {
+			return l, err
+		}
+		l = append(l, filename)
+	}
+
+	if separateGrants() && grantsFile == "object" {
+		filename, err := grantsFilename(base, v)
+		if err != nil {
+			return l, err
+		}
+		l = append(l, filename)
+	}
+
+	return l, nil
+}
+
+// splitSpecBody returns true if the object is a package or type that is
+// to have the specification and body written to separate files
+func splitSpecBody(v obj) bool {
+	if format != "sql" {
+		return false
+	}
+	switch v.objtype {
+	case "PACKAGE":
+		return splitPkg
+	case "TYPE":
+		return splitType
+	}
+	return false
+}
+
+// splitTable returns true if the object is a table, or materialized
+// view, that is to have the constraints and indexes written to separate
+// files
+func splitTable(v obj) bool {
+	if format != "sql" || constraints != "separate" {
+		return false
+	}
+	return v.objtype == "TABLE" || v.objtype == "MATERIALIZED VIEW"
+}
+
+// tablePartFilenames returns the constraint and index file names for a
+// split table
+func tablePartFilenames(base string, v obj) (string, string, error) {
+
+	c := obj{owner: v.owner, objname: v.objname, objtype: v.objtype, dirname: "CONSTRAINT"}
+	constraintFile, err := objFilename(base, c, "", "sql")
+	if err != nil {
+		return "", "", err
+	}
+
+	i := obj{owner: v.owner, objname: v.objname, objtype: v.objtype, dirname: "INDEX"}
+	indexFile, err := objFilename(base, i, "", "sql")
+	return constraintFile, indexFile, err
+}
+
+// specBodyFilenames returns the specification and body file names for
+// a split package or type
+func specBodyFilenames(base string, v obj) (string, string, error) {
+
+	specSuffix, specExt, bodySuffix, bodyExt := "", "pks", "", "pkb"
+	if v.objtype == "TYPE" {
+		specExt, bodyExt = "tps", "tpb"
+	}
+	if pkgFiles == "sql" {
+		specSuffix, specExt, bodySuffix, bodyExt = "_spec", "sql", "_body", "sql"
+	}
+
+	specFile, err := objFilename(base, v, specSuffix, specExt)
+	if err != nil {
+		return "", "", err
+	}
+
+	bodyFile, err := objFilename(base, v, bodySuffix, bodyExt)
+	return specFile, bodyFile, err
+}
+
+// objFilename returns the file name, as determined by the path
+// template, for an object
+func objFilename(base string, v obj, suffix, ext string) (string, error) {
+
+	o := dex.ObjectInfo{
+		Schema: safeName(v.owner),
+		Name:   safeName(v.objname) + suffix,
+		Type:   v.dirname,
+		Ext:    ext,
+	}
+
+	filename, err := namer.Render(o)
+	if err != nil {
+		return "", err
+	}
+
+	filename = filepath.Join(base, outName(filename))
+	if len(filename) > dex.MaxPathLength {
+		return "", fmt.Errorf("the path for %q.%q is longer than %d bytes", v.owner, v.objname, dex.MaxPathLength)
+	}
+	escapedNames.add(filename, v.owner, v.objname)
+	return filename, nil
+}
+
+// emit writes the DDL to the file or, when in check mode, compares the
+// DDL with the file and reports any differences
+func emit(label, filename string, b []byte) error {
+	return emitEncoded(label, filename, b, encoding, bom)
+}
+
+// emitEncoded is emit with the specified encoding and byte order mark in
+// place of those of -encoding and -bom
+func emitEncoded(label, filename string, b []byte, enc string, withBOM bool) error {
+
+	b, err := dex.EncodeText(b, eol, enc, withBOM)
+	if err != nil {
+		return err
+	}
+
+	if !check {
+		return writeIfChanged(filename, b)
+	}
+
+	current, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		drift = true
+		fmt.Printf("%s: added (%s)\n", label, filename)
+		if showDiff {
+			fmt.Print(dex.UnifiedDiff("/dev/null", filename, "", string(b), 3))
+		}
+		return nil
+	}
+
+	diffs, same := dex.CompareObjectDDL(string(current), string(b))
+	if !same {
+		drift = true
+		if showDiff {
+			fmt.Printf("%s: changed (%s)\n", label, filename)
+			fmt.Print(dex.UnifiedDiff(filename, filename+" (database)", string(current), string(b), 3))
+			return nil
+		}
+		d := diffs[0]
+		fmt.Printf("%s: changed (%s)\n    line %d\n    disk: %s\n    live: %s\n", label, filename, d.Line, d.Disk, d.Live)
+	}
+
+	return nil
+}
+
+// unemit removes a file that is no longer to be written or, when in
+// check mode, reports that it would be removed
+func unemit(label, filename string) error {
+
+	if archive != nil {
+		return nil
+	}
+
+	_, err := os.Stat(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	if check {
+		drift = true
+		fmt.Printf("%s: removed (%s)\n", label, filename)
+		return nil
+	}
+
+	return os.Remove(filename)
+}
+
+// encodeOutput sets the line endings and the character encoding of the
+// DDL as specified by -eol, -encoding, and -bom
+func encodeOutput(b []byte) ([]byte, error) {
+	return dex.EncodeText(b, eol, encoding, bom)
+}
+
+// writeIfChanged writes the file, atomically, only if the content
+// differs from what is already on disk (see dex.WriteFile). When writing
+// to an archive the file is always added to the archive. Nothing is
+// written under the base directory in check mode.
+func writeIfChanged(filename string, b []byte) error {
+
+	if check {
+		return fmt.Errorf("not writing %s in check mode", filename)
+	}
+
+	if archive != nil {
+		return archive.add(filename, b)
+	}
+
+	err := os.MkdirAll(filepath.Dir(filename), 0700)
+	if err != nil {
+		return err
+	}
+
+	written, err := dex.WriteFile(filename, b, 0600)
+	if written {
+		stats.written(len(b))
+	}
+	return err
+}
@@ -0,0 +1,504 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	dex "github.com/gsiems/oradex"
+)
+
+// newFlagSet returns the flag set for the command. Each command has its
+// own flag set with the flags that apply to it, the connection flags
+// being shared by all of them. Running oradex without a command accepts
+// all of the flags but for those of serve.
+func newFlagSet(command string) *flag.FlagSet {
+
+	fs := flag.NewFlagSet("oradex", flag.ExitOnError)
+	fs.SetOutput(os.Stderr)
+	fs.Usage = usage
+
+	connectionFlags(fs)
+
+	switch command {
+	case "preflight":
+	case "list":
+		filterFlags(fs)
+		fs.StringVar(&format, "format", format, "")
+	case "compare":
+		commonFlags(fs)
+		filterFlags(fs)
+	case "object":
+		commonFlags(fs)
+		filterFlags(fs)
+		tableFlags(fs)
+		retryFlags(fs)
+		objectFlags(fs)
+	case "serve":
+		commonFlags(fs)
+		filterFlags(fs)
+		tableFlags(fs)
+		retryFlags(fs)
+		serveFlags(fs)
+		monitorFlags(fs)
+	default:
+		commonFlags(fs)
+		filterFlags(fs)
+		tableFlags(fs)
+		retryFlags(fs)
+		objectFlags(fs)
+		extractFlags(fs)
+		monitorFlags(fs)
+	}
+
+	return fs
+}
+
+// knownFlag returns true if the flag is one of those of any of the
+// commands, so that settings in the configuration file that are for
+// other commands may be ignored rather than rejected
+func knownFlag(name string) bool {
+	for _, command := range []string{"", "serve"} {
+		if newFlagSet(command).Lookup(name) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// connectionFlags registers the database connection flags, along with
+// the configuration and logging flags, that are shared by all of the
+// commands
+func connectionFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&showVersion, "version", false, "")
+	fs.BoolVar(&compat, "compat", false, "")
+	fs.StringVar(&configFile, "c", "", "")
+	fs.StringVar(&connectAs, "as", "", "")
+	fs.StringVar(&dbName, "d", "", "")
+	fs.BoolVar(&debug, "debug", false, "")
+	fs.StringVar(&dsnStr, "dsn", "", "")
+	fs.BoolVar(&external, "external", false, "")
+	fs.StringVar(&host, "h", "", "")
+	fs.StringVar(&logFormat, "log-format", logFormat, "")
+	fs.StringVar(&logLevel, "log-level", logLevel, "")
+	fs.BoolVar(&noDba, "nodba", false, "")
+	fs.StringVar(&orapassFile, "f", "", "")
+	fs.BoolVar(&passwdStdin, "password-stdin", false, "")
+	fs.StringVar(&pdbs, "pdbs", "", "")
+	fs.StringVar(&port, "p", "", "")
+	fs.StringVar(&profile, "profile", "", "")
+	fs.StringVar(&proxy, "proxy", "", "")
+	fs.BoolVar(&quiet, "q", false, "")
+	fs.StringVar(&tnsAdmin, "tns-admin", "", "")
+	fs.StringVar(&user, "u", "", "")
+	fs.StringVar(&wallet, "wallet", "", "")
+}
+
+// commonFlags registers the flags for what is included in, and how to
+// render, the DDL of the objects (see Common extract flags)
+func commonFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&alter, "alter", false, "")
+	fs.StringVar(&consState, "constraint-state", "", "")
+	fs.BoolVar(&currentValue, "current-value", false, "")
+	fs.StringVar(&drop, "drop", "", "")
+	fs.BoolVar(&dropCascade, "cascade", false, "")
+	fs.BoolVar(&force, "force", false, "")
+	fs.StringVar(&format, "format", format, "")
+	fs.StringVar(&grantsFile, "grants-file", "", "")
+	fs.BoolVar(&grantsOf, "grants", false, "")
+	fs.BoolVar(&install, "install", false, "")
+	fs.StringVar(&keywordCase, "keyword-case", "", "")
+	fs.StringVar(&metaFormat, "metadata-format", metaFormat, "")
+	fs.BoolVar(&neededGrants, "needed", false, "")
+	fs.BoolVar(&noPublic, "nopublic", false, "")
+	fs.BoolVar(&normalize, "normalize", false, "")
+	fs.BoolVar(&noSchema, "noschema", false, "")
+	fs.BoolVar(&objGrants, "", false, "")
+	fs.BoolVar(&pretty, "pretty", false, "")
+	fs.BoolVar(&received, "received-grants", false, "")
+	fs.StringVar(&recompile, "recompile", "", "")
+	fs.StringVar(&remapSchema, "remap-schema", "", "")
+	fs.StringVar(&remapTs, "remap-tablespace", "", "")
+	fs.StringVar(&sequences, "sequences", sequences, "")
+	fs.BoolVar(&storage, "storage", false, "")
+	fs.BoolVar(&synonyms, "synonyms", false, "")
+	fs.StringVar(&target, "target-version", "", "")
+	fs.BoolVar(&toggle, "toggle", false, "")
+	fs.BoolVar(&uninstall, "uninstall", false, "")
+}
+
+// filterFlags registers the flags for limiting the schemas and objects
+func filterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&exclude, "exclude", "", "")
+	fs.BoolVar(&excludeSys, "exclude-sys", excludeSys, "")
+	fs.StringVar(&extraXclude, "X", "", "")
+	fs.StringVar(&include, "include", "", "")
+	fs.BoolVar(&noExcludeSys, "no-exclude-sys", false, "")
+	fs.BoolVar(&regex, "regex", false, "")
+	fs.StringVar(&schemas, "s", "", "")
+	fs.StringVar(&types, "types", "", "")
+	fs.StringVar(&xclude, "x", "", "")
+	fs.StringVar(&xtypes, "xtypes", "", "")
+}
+
+// tableFlags registers the flags for how the triggers and constraints
+// of tables are extracted
+func tableFlags(fs *flag.FlagSet) {
+	fs.StringVar(&constraints, "constraints", constraints, "")
+	fs.StringVar(&triggers, "triggers", triggers, "")
+}
+
+// retryFlags registers the flags for retrying, and timing out, the
+// extraction of an object
+func retryFlags(fs *flag.FlagSet) {
+	fs.DurationVar(&objTimeout, "timeout", 0, "")
+	fs.IntVar(&retries, "retries", 0, "")
+	fs.DurationVar(&retryWait, "retry-backoff", retryWait, "")
+}
+
+// objectFlags registers the flags for extracting a single object (see
+// Extract object DDL flags)
+func objectFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&dependents, "dependents", false, "")
+	fs.BoolVar(&deps, "deps", false, "")
+	fs.StringVar(&objectName, "o", "", "")
+	fs.BoolVar(&prompts, "prompt", false, "")
+}
+
+// extractFlags registers the flags for extracting the schemas to the
+// base directory (see Extract database/schema(s) DDL flags)
+func extractFlags(fs *flag.FlagSet) {
+	fs.StringVar(&archiveFile, "archive", "", "")
+	fs.StringVar(&base, "b", "", "")
+	fs.BoolVar(&bom, "bom", false, "")
+	fs.BoolVar(&check, "check", false, "")
+	fs.StringVar(&dataTables, "data", "", "")
+	fs.StringVar(&deltaFile, "delta", "", "")
+	fs.BoolVar(&directories, "directories", false, "")
+	fs.BoolVar(&dryRun, "dry-run", false, "")
+	fs.StringVar(&encoding, "encoding", encoding, "")
+	fs.StringVar(&eol, "eol", "", "")
+	fs.BoolVar(&gitCommitOn, "git-commit", false, "")
+	fs.StringVar(&gitMessage, "git-message", "", "")
+	fs.StringVar(&graph, "graph", "", "")
+	fs.IntVar(&keepSnaps, "keep", 0, "")
+	fs.BoolVar(&lockdown, "lockdown-profiles", false, "")
+	fs.BoolVar(&lookupData, "lookup-data", false, "")
+	fs.BoolVar(&lowerNames, "lower", false, "")
+	fs.StringVar(&pathTmpl, "template", pathTmpl, "")
+	fs.StringVar(&pkgFiles, "package-files", pkgFiles, "")
+	fs.BoolVar(&prune, "prune", false, "")
+	fs.BoolVar(&reportStale, "stale", false, "")
+	fs.BoolVar(&resume, "resume", false, "")
+	fs.BoolVar(&roles, "roles", false, "")
+	fs.BoolVar(&scheduler, "scheduler", false, "")
+	fs.BoolVar(&schemaRoles, "schema-roles", false, "")
+	fs.BoolVar(&showDiff, "diff", false, "")
+	fs.StringVar(&since, "since", "", "")
+	fs.BoolVar(&singleFile, "single-file", false, "")
+	fs.BoolVar(&sizeReport, "size-report", false, "")
+	fs.BoolVar(&snapshot, "snapshot", false, "")
+	fs.StringVar(&snapTag, "tag", "", "")
+	fs.BoolVar(&splitPkg, "split-package", false, "")
+	fs.BoolVar(&splitType, "split-type", false, "")
+	fs.BoolVar(&sqlplus, "sqlplus", false, "")
+	fs.BoolVar(&strict, "strict", false, "")
+	fs.StringVar(&summaryFile, "summary", "", "")
+	fs.BoolVar(&sysPrivs, "sys-privs", false, "")
+	fs.BoolVar(&tablespaces, "tablespaces", false, "")
+	fs.BoolVar(&typeExt, "type-ext", false, "")
+	fs.BoolVar(&users, "users", false, "")
+	fs.DurationVar(&watch, "watch", 0, "")
+}
+
+// serveFlags registers the flags for serving the DDL over HTTP
+func serveFlags(fs *flag.FlagSet) {
+	fs.StringVar(&listen, "listen", listen, "")
+}
+
+// monitorFlags registers the flags for the metrics and profiles that
+// are served in watch or serve mode
+func monitorFlags(fs *flag.FlagSet) {
+	fs.StringVar(&metricsAddr, "metrics", "", "")
+	fs.StringVar(&pprofAddr, "pprof", "", "")
+}
+
+// checkFlags checks the flags, and the arguments, for the command and
+// sets those that are implied by others
+func checkFlags(command string, args []string) error {
+
+	// The object, compare, and diff commands take the object, or the
+	// schema to compare with, as an argument. The other commands take
+	// none.
+	switch command {
+	case "object":
+		if len(args) > 0 {
+			objectName = args[0]
+			args = args[1:]
+		}
+		if objectName == "" {
+			return fmt.Errorf("the object command requires a [schema.]object_name")
+		}
+	case "compare", "diff":
+		if len(args) > 0 {
+			compareTo = args[0]
+			args = args[1:]
+		}
+		if command == "compare" && compareTo == "" {
+			return fmt.Errorf("the compare command requires a SCHEMA[@DBLINK] to compare with")
+		}
+		// Without a schema to compare with the differences are between
+		// the database and the files under the base directory
+		if compareTo == "" {
+			showDiff = true
+		}
+	case "extract":
+		if objectName != "" {
+			return fmt.Errorf("the extract command can not be used with the -o flag, use the object command")
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(args, " "))
+	}
+
+	if format != "sql" && format != "json" {
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	// The metadata formats replace the SQL output format
+	switch metaFormat {
+	case "ddl":
+	case "sxml", "xml":
+		if format == "json" {
+			return fmt.Errorf("the %s metadata format can not be used with the json format", metaFormat)
+		}
+		format = metaFormat
+	default:
+		return fmt.Errorf("unknown metadata format %q", metaFormat)
+	}
+
+	switch drop {
+	case "", "prepend", "file":
+	default:
+		return fmt.Errorf("unknown drop option %q", drop)
+	}
+
+	switch pkgFiles {
+	case "pks", "sql":
+	default:
+		return fmt.Errorf("unknown package files option %q", pkgFiles)
+	}
+
+	// Asking for a package file naming implies splitting the packages
+	cmdFlags.Visit(func(f *flag.Flag) {
+		if f.Name == "package-files" {
+			splitPkg = true
+		}
+	})
+
+	switch grantsFile {
+	case "", "object", "schema":
+	default:
+		return fmt.Errorf("unknown grants file option %q", grantsFile)
+	}
+
+	switch recompile {
+	case "", "alter", "utl_recomp":
+	default:
+		return fmt.Errorf("unknown recompile option %q", recompile)
+	}
+
+	switch graph {
+	case "", "dot", "mermaid":
+	default:
+		return fmt.Errorf("unknown graph format %q", graph)
+	}
+
+	switch sequences {
+	case "live", "reset", "restart":
+	default:
+		return fmt.Errorf("unknown sequences option %q", sequences)
+	}
+
+	// Normalizing removes the START WITH clause that these set
+	if normalize && (currentValue || sequences != "live") {
+		return fmt.Errorf("the -normalize flag can not be used with the -current-value flag or with -sequences reset or restart")
+	}
+
+	if sqlplus && format != "sql" {
+		return fmt.Errorf("-sqlplus requires the sql format")
+	}
+
+	_, err := dex.EncodeText(nil, eol, encoding, bom)
+	if err != nil {
+		return err
+	}
+
+	switch keywordCase {
+	case "", "upper", "lower":
+	default:
+		return fmt.Errorf("unknown keyword case %q", keywordCase)
+	}
+	if keywordCase != "" && !pretty {
+		return fmt.Errorf("-keyword-case requires -pretty")
+	}
+
+	if triggers != "inline" && triggers != "separate" {
+		return fmt.Errorf("unknown triggers option %q", triggers)
+	}
+	if constraints != "inline" && constraints != "separate" {
+		return fmt.Errorf("unknown constraints option %q", constraints)
+	}
+
+	// Showing the differences implies checking for them
+	if showDiff {
+		check = true
+	}
+
+	if singleFile && (format != "sql" || since != "" || prune || reportStale) {
+		return fmt.Errorf("the -single-file flag can only be used with the sql format and can not be used with the -since, -prune, or -stale flags")
+	}
+
+	if check && (prune || objectName != "") {
+		return fmt.Errorf("the -check flag can not be used with the -prune or -o flags")
+	}
+
+	if (deps || dependents) && objectName == "" {
+		return fmt.Errorf("the -deps and -dependents flags can only be used with the -o flag")
+	}
+	if deps && dependents {
+		return fmt.Errorf("the -deps and -dependents flags can not be used together")
+	}
+
+	if archiveFile != "" && (check || prune || reportStale) {
+		return fmt.Errorf("the -archive flag can not be used with the -check, -prune, or -stale flags")
+	}
+
+	if install && (archiveFile != "" || singleFile || format != "sql") {
+		return fmt.Errorf("the -install flag requires the sql format and can not be used with the -archive or -single-file flags")
+	}
+
+	if gitCommitOn {
+		if check || archiveFile != "" || objectName != "" || compareTo != "" || graph != "" {
+			return fmt.Errorf("the -git-commit flag can not be used with the compare command or with the -check, -archive, -o, or -graph flags")
+		}
+		var err error
+		gitTmpl, err = parseGitMessage(gitMessage)
+		if err != nil {
+			return err
+		}
+	}
+
+	if serveMode && (check || singleFile || archiveFile != "" || objectName != "" || compareTo != "" || graph != "" || watch > 0 || gitCommitOn) {
+		return fmt.Errorf("serve mode can not be used with the compare command or with the -check, -single-file, -archive, -o, -graph, -watch, or -git-commit flags")
+	}
+
+	if metricsAddr != "" && watch == 0 && !serveMode {
+		return fmt.Errorf("the -metrics flag can only be used in watch or serve mode")
+	}
+	if pprofAddr != "" && watch == 0 && !serveMode {
+		return fmt.Errorf("the -pprof flag can only be used in watch or serve mode")
+	}
+
+	if dryRun && (serveMode || preflight || watch > 0 || objectName != "" || compareTo != "" || graph != "" || check || archiveFile != "" || gitCommitOn) {
+		return fmt.Errorf("the -dry-run flag can not be used in serve, preflight, compare, or watch mode or with the -o, -graph, -check, -archive, or -git-commit flags")
+	}
+
+	if (snapTag != "" || keepSnaps > 0) && !snapshot {
+		return fmt.Errorf("the -tag and -keep flags can only be used with the -snapshot flag")
+	}
+	if snapshot && (serveMode || preflight || listMode || dryRun || watch > 0 || objectName != "" || compareTo != "" || graph != "" || check || archiveFile != "") {
+		return fmt.Errorf("the -snapshot flag can not be used in serve, preflight, compare, list, or watch mode or with the -dry-run, -o, -graph, -check, or -archive flags")
+	}
+
+	if deltaFile != "" && (serveMode || preflight || listMode || dryRun || watch > 0 || objectName != "" || compareTo != "" || graph != "" || check || singleFile || since != "") {
+		return fmt.Errorf("the -delta flag can not be used in serve, preflight, compare, list, or watch mode or with the -dry-run, -o, -graph, -check, -single-file, or -since flags")
+	}
+
+	if watch > 0 && (check || singleFile || archiveFile != "" || objectName != "" || compareTo != "" || graph != "") {
+		return fmt.Errorf("the -watch flag can not be used with the compare command or with the -check, -single-file, -archive, -o, or -graph flags")
+	}
+
+	pt, err := dex.NewPathTemplate(pathTmpl)
+	if err != nil {
+		return err
+	}
+	if !pt.UsesExt() && (dataTables != "" || lookupData || typeExt || splitPkg || format != "sql") {
+		return fmt.Errorf("the -template must use {{.Ext}} with the -data, -lookup-data, -type-ext, -split-package, or -format flags")
+	}
+	namer = pt
+
+	if noExcludeSys {
+		excludeSys = false
+	}
+
+	return nil
+}
+
+// exportOptions returns the export options set by the flags
+func exportOptions() (dex.ExportOptions, error) {
+
+	var err error
+
+	opts := dex.NewExportOptions()
+	opts.NeededGrants = neededGrants
+	// Grants written to separate files are exported separately
+	opts.ObjectGrants = grantsOf && !separateGrants()
+	opts.Storage = storage
+	opts.Force = force
+	opts.Alter = alter || constraints == "separate"
+	opts.EmitSchema = !noSchema
+	opts.Normalize = normalize
+	opts.Format = pretty
+	opts.KeywordCase = keywordCase
+	if consState != "" {
+		opts.ConstraintState, err = dex.ParseConstraintState(consState)
+		if err != nil {
+			return opts, err
+		}
+	}
+	opts.Retries = retries
+	opts.RetryBackoff = retryWait
+	opts.ObjectTimeout = objTimeout
+	opts.RewriteRules = rewriteRules
+	opts.Synonyms = synonyms
+	opts.PublicSynonyms = !noPublic
+	opts.CurrentValue = currentValue
+	opts.ResetSequences = sequences != "live"
+	opts.Drop = drop == "prepend"
+	opts.DropCascade = dropCascade
+	opts.ExcludeSystemSchemas = excludeSys
+	opts.SeparateTriggers = triggers == "separate"
+	opts.RemapSchemas, err = dex.ParseSchemaRemap(remapSchema)
+	if err != nil {
+		return opts, err
+	}
+	opts.RemapTablespaces, err = dex.ParseTablespaceRemap(remapTs)
+	if err != nil {
+		return opts, err
+	}
+	if types != "" {
+		opts.ObjectTypes = strings.Split(types, ",")
+	}
+	if xtypes != "" {
+		opts.ExcludeObjectTypes = strings.Split(xtypes, ",")
+	}
+	opts.IncludeNames, err = dex.CompileNamePatterns(strings.Split(include, ","), regex)
+	if err != nil {
+		return opts, err
+	}
+	opts.ExcludeNames, err = dex.CompileNamePatterns(strings.Split(exclude, ","), regex)
+	if err != nil {
+		return opts, err
+	}
+
+	if target != "" {
+		opts.TargetVersion, err = dex.ParseOracleVersion(target)
+		if err != nil {
+			return opts, err
+		}
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// setFlags resets the flags of all of the commands to their defaults,
+// parses those for the command the same way main does, and returns the
+// positional arguments
+func setFlags(t *testing.T, defaults map[string]string, command string, argv ...string) []string {
+
+	t.Helper()
+
+	for _, c := range []string{"", "serve"} {
+		fs := newFlagSet(c)
+		fs.VisitAll(func(f *flag.Flag) {
+			if err := fs.Set(f.Name, defaults[f.Name]); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+	serveMode = command == "serve"
+	listMode = command == "list"
+	preflight = command == "preflight"
+	compareTo = ""
+
+	cmdFlags = newFlagSet(command)
+	cmdFlags.Init("oradex", flag.ContinueOnError)
+	cmdFlags.SetOutput(ioutil.Discard)
+	cmdFlags.Usage = func() {}
+	if err := cmdFlags.Parse(argv); err != nil {
+		t.Fatal(err)
+	}
+	var args []string
+	for cmdFlags.NArg() > 0 {
+		args = append(args, cmdFlags.Arg(0))
+		if err := cmdFlags.Parse(cmdFlags.Args()[1:]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return args
+}
+
+// flagDefaults returns the default values of the flags of all of the
+// commands and restores them when the test is done
+func flagDefaults(t *testing.T) map[string]string {
+
+	defaults := make(map[string]string)
+	for _, c := range []string{"", "serve"} {
+		newFlagSet(c).VisitAll(func(f *flag.Flag) {
+			defaults[f.Name] = f.DefValue
+		})
+	}
+	t.Cleanup(func() {
+		setFlags(t, defaults, "")
+		cmdFlags = nil
+	})
+	return defaults
+}
+
+func TestCheckFlags(t *testing.T) {
+
+	defaults := flagDefaults(t)
+
+	tests := []struct {
+		command string
+		args    string
+		wantErr string
+	}{
+		{"", "", ""},
+		{"extract", "-format json", ""},
+		{"extract", "-format yaml", "unknown format"},
+		{"extract", "-o APP.ORDERS", "use the object command"},
+		{"extract", "APP.ORDERS", "unexpected arguments"},
+		{"object", "", "requires a [schema.]object_name"},
+		{"object", "APP.ORDERS -deps", ""},
+		{"object", "APP.ORDERS APP.ITEMS", "unexpected arguments"},
+		{"compare", "", "requires a SCHEMA[@DBLINK]"},
+		{"compare", "APP@PROD", ""},
+		{"diff", "", ""},
+		{"", "-metadata-format sxml -format json", "can not be used with the json format"},
+		{"", "-drop append", "unknown drop option"},
+		{"", "-normalize -current-value", "-normalize"},
+		{"", "-normalize -sequences reset", "-normalize"},
+		{"", "-keyword-case upper", "requires -pretty"},
+		{"", "-keyword-case upper -pretty", ""},
+		{"", "-triggers after", "unknown triggers option"},
+		{"", "-single-file -prune", "-single-file"},
+		{"", "-deps", "can only be used with the -o flag"},
+		{"", "-watch 1m -check", "-watch"},
+		{"", "-tag release", "-snapshot"},
+		{"", "-template {{.Schema}}/{{.Name}} -format json", "{{.Ext}}"},
+		{"serve", "-metrics :9090", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command+" "+tt.args, func(t *testing.T) {
+			args := setFlags(t, defaults, tt.command, strings.Fields(tt.args)...)
+
+			err := checkFlags(tt.command, args)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error %v", err)
+			case tt.wantErr != "" && err == nil:
+				t.Fatalf("no error, want %q", tt.wantErr)
+			case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+				t.Fatalf("error %q, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckFlagsImplied(t *testing.T) {
+
+	defaults := flagDefaults(t)
+
+	args := setFlags(t, defaults, "diff")
+	if err := checkFlags("diff", args); err != nil {
+		t.Fatal(err)
+	}
+	if !showDiff || !check {
+		t.Errorf("diff without a schema sets -diff %v, -check %v", showDiff, check)
+	}
+
+	args = setFlags(t, defaults, "", "-package-files", "pks")
+	if err := checkFlags("", args); err != nil {
+		t.Fatal(err)
+	}
+	if !splitPkg {
+		t.Error("-package-files does not imply -split-package")
+	}
+
+	args = setFlags(t, defaults, "", "-metadata-format", "sxml")
+	if err := checkFlags("", args); err != nil {
+		t.Fatal(err)
+	}
+	if format != "sxml" {
+		t.Errorf("format %q, want %q", format, "sxml")
+	}
+}
+
+func TestExportOptions(t *testing.T) {
+
+	defaults := flagDefaults(t)
+
+	args := setFlags(t, defaults, "", "-triggers", "separate", "-sequences", "reset", "-drop", "prepend", "-types", "TABLE,VIEW")
+	if err := checkFlags("", args); err != nil {
+		t.Fatal(err)
+	}
+	opts, err := exportOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.SeparateTriggers || !opts.ResetSequences || !opts.Drop {
+		t.Errorf("got %+v", opts)
+	}
+	if len(opts.ObjectTypes) != 2 || opts.ObjectTypes[1] != "VIEW" {
+		t.Errorf("object types %q", opts.ObjectTypes)
+	}
+
+	setFlags(t, defaults, "", "-remap-schema", "APP")
+	if _, err := exportOptions(); err == nil {
+		t.Error("no error for an invalid schema remapping")
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultGitMessage is the default template for the git commit message
+const defaultGitMessage = "Extract {{.Schemas}} from {{.DbName}} at {{.Time}}"
+
+// gitMessageInfo contains the values available to git commit message
+// templates
+type gitMessageInfo struct {
+	DbName  string
+	Schemas string
+	Time    string
+}
+
+// parseGitMessage parses, and validates, the git commit message template
+func parseGitMessage(s string) (*template.Template, error) {
+
+	tmpl, err := template.New("message").Parse(coalesce(s, defaultGitMessage))
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure that the template only uses the available fields
+	var b bytes.Buffer
+	err = tmpl.Execute(&b, gitMessageInfo{})
+	if err != nil {
+		return nil, err
+	}
+
+	return tmpl, nil
+}
+
+// gitCommit stages all of the changes under the base directory and, if
+// there are any, commits them with the message rendered from the
+// template. The base directory must be in a git working tree.
+func gitCommit(base string, tmpl *template.Template, info gitMessageInfo) error {
+
+	dir := coalesce(base, ".")
+
+	_, err := git(dir, "add", "--all", "--", ".")
+	if err != nil {
+		return err
+	}
+
+	// diff --quiet exits with 1, and no message, if there are staged
+	// changes
+	_, err = git(dir, "diff", "--cached", "--quiet", "--", ".")
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*exec.ExitError); !ok {
+		return err
+	}
+
+	if info.Time == "" {
+		info.Time = time.Now().Format("2006-01-02T15:04:05")
+	}
+
+	var msg bytes.Buffer
+	err = tmpl.Execute(&msg, info)
+	if err != nil {
+		return err
+	}
+
+	_, err = git(dir, "commit", "--quiet", "--message", msg.String(), "--", ".")
+	return err
+}
+
+// commitChanges commits the changes to the files under the base
+// directory to git
+func commitChanges(base string) {
+	info := gitInfo
+	info.Schemas = coalesce(schemas, "all schemas")
+	logError(gitCommit(base, gitTmpl, info))
+}
+
+// git runs a git command in the specified directory and returns the
+// output
+func git(dir string, args ...string) (string, error) {
+
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return string(out), fmt.Errorf("git %s: %s", args[0], msg)
+		}
+	}
+
+	return string(out), err
+}
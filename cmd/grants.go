@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// schemaReceivedObj returns the pseudo-object for the file that the
+// grants received by a schema are written to
+func schemaReceivedObj(schema string) obj {
+	return obj{owner: schema, objname: "received", dirname: "GRANTS"}
+}
+
+// extractReceivedGrants writes the grants that a schema has received on
+// the objects of other schemas to a separate file
+func extractReceivedGrants(ctx context.Context, base, schema string) {
+
+	grants, err := ex.ExportReceivedGrants(ctx, schema)
+	if err != nil {
+		logError(err)
+		return
+	}
+
+	filename, err := objFilename(base, schemaReceivedObj(schema), "", "sql")
+	if err != nil {
+		logError(err)
+		return
+	}
+	if grants == "" {
+		// No grants, no file... unless it already exists
+		if _, err := os.Stat(filename); err != nil {
+			return
+		}
+	}
+
+	b := sqlplusScript([]byte(grants+"\n\n"), "RECEIVED GRANTS", "", schema)
+	err = emit(fmt.Sprintf("%q received grants", schema), filename, b)
+	logError(err)
+}
+
+// separateGrants returns true if the object grants are to be written to
+// separate files. Single objects (-o) are written to stdout so there the
+// grants are always included with the object DDL.
+func separateGrants() bool {
+	return grantsOf && grantsFile != "" && format == "sql" && objectName == ""
+}
+
+// appendGrants appends the (non-empty) grants to the list of grants
+func appendGrants(l []string, grants string) []string {
+	if grants != "" {
+		l = append(l, grants)
+	}
+	return l
+}
+
+// schemaGrantsObj returns the pseudo-object for the file that the
+// grants for a schema are written to
+func schemaGrantsObj(schema string) obj {
+	return obj{owner: schema, objname: "grants", dirname: "GRANTS"}
+}
+
+// grantsFilename returns the name of the file that the grants for an
+// object, or for a schema, are written to
+func grantsFilename(base string, v obj) (string, error) {
+	if v == schemaGrantsObj(v.owner) {
+		return objFilename(base, v, "", "sql")
+	}
+	return objFilename(base, v, ".grants", "sql")
+}
+
+// extractGrants writes the grants for an object, or for a schema, to a
+// separate file
+func extractGrants(base string, v obj, grants string) error {
+
+	filename, err := grantsFilename(base, v)
+	if err != nil {
+		return err
+	}
+	label := fmt.Sprintf("%q.%q grants", v.owner, v.objname)
+	if grants == "" {
+		// No grants, no file
+		return unemit(label, filename)
+	}
+
+	b := sqlplusScript([]byte(grants+"\n\n"), "GRANTS ON "+v.objtype, v.owner, v.objname)
+	return emit(label, filename, b)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"os"
+
+	dex "github.com/gsiems/oradex"
+)
+
+// graphSchemas writes the dependency graph, including the foreign keys,
+// for a list of schemas to stdout
+func graphSchemas(ctx context.Context, db *sql.DB, schemas, xclude string) {
+
+	l, err := getSchemaList(ctx, schemas, xclude)
+	failOnErr(err)
+
+	var edges []dex.GraphEdge
+	for _, schema := range l {
+		e, err := dex.SchemaGraph(ctx, db, schema)
+		if err != nil {
+			logError(err)
+			continue
+		}
+		edges = append(edges, e...)
+	}
+
+	if graph == "mermaid" {
+		err = dex.WriteMermaid(os.Stdout, edges)
+	} else {
+		err = dex.WriteDOT(os.Stdout, edges)
+	}
+	failOnErr(err)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dex "github.com/gsiems/oradex"
+)
+
+// installFile is the file, in the base directory, that the install
+// script for the extracted schemas is written to
+const installFile = "install.sql"
+
+// writeInstall writes the SQL*Plus script for running the extracted
+// files of the schemas in two phases. The first phase creates the
+// objects, along with any separate object grants, ordered such that
+// each object follows the objects that it depends on, or that its
+// foreign keys reference, regardless of the schema (see
+// dex.InstallOrder). With the grants written to a file for each schema
+// the objects are instead grouped by schema (see
+// dex.InstallOrderBySchema) and the grants of each schema are applied
+// once its objects are created, before the objects of the schemas that
+// depend on them (i.e. views over, or foreign keys to, its tables). The
+// second phase applies the received grants, the package and type bodies,
+// and any separate indexes and constraints, which may depend on the
+// objects of any of the schemas, and then recompiles the schemas.
+func writeInstall(ctx context.Context, db *sql.DB, base string, schemas []string) {
+
+	objs := make(map[dex.ObjectEntry]obj)
+	var entries []dex.ObjectEntry
+
+	for _, schema := range schemas {
+		l, err := getObjList(ctx, db, schema)
+		if err != nil {
+			logError(err)
+			return
+		}
+		for _, v := range filterObjList(l) {
+			e := dex.ObjectEntry{Schema: v.owner, Name: v.objname, ObjType: v.objtype}
+			objs[e] = v
+			entries = append(entries, e)
+		}
+	}
+
+	schemaGrants := separateGrants() && grantsFile == "schema"
+
+	order := dex.InstallOrder
+	if schemaGrants {
+		order = dex.InstallOrderBySchema
+	}
+	ordered, err := order(ctx, db, entries)
+	if err != nil {
+		logError(err)
+		return
+	}
+
+	var phase1, phase2 []string
+
+	// run adds the file, if it exists, to the phase
+	run := func(phase []string, filename string, err error) []string {
+		if err != nil {
+			logError(err)
+			return phase
+		}
+		if _, err := os.Stat(filename); err != nil {
+			return phase
+		}
+		rel, err := filepath.Rel(base, filename)
+		if err != nil {
+			logError(err)
+			return phase
+		}
+		return append(phase, "@@"+filepath.ToSlash(rel))
+	}
+
+	// schemaGrantsFile adds the grants file for the schema to phase 1,
+	// only once per schema
+	granted := make(map[string]bool)
+	schemaGrantsFile := func(schema string) {
+		if !schemaGrants || granted[schema] {
+			return
+		}
+		granted[schema] = true
+		filename, err := grantsFilename(base, schemaGrantsObj(schema))
+		phase1 = run(phase1, filename, err)
+	}
+
+	for _, schema := range schemas {
+		if received {
+			filename, err := objFilename(base, schemaReceivedObj(schema), "", "sql")
+			phase2 = run(phase2, filename, err)
+		}
+	}
+
+	var bodies, indexes, constraints []string
+	for i, e := range ordered {
+		v := objs[e]
+
+		if i > 0 && ordered[i-1].Schema != e.Schema {
+			schemaGrantsFile(ordered[i-1].Schema)
+		}
+
+		switch {
+		case splitSpecBody(v):
+			specFile, bodyFile, err := specBodyFilenames(base, v)
+			phase1 = run(phase1, specFile, err)
+			bodies = run(bodies, bodyFile, err)
+		case splitTable(v):
+			filename, err := objFilename(base, v, "", outputExt(v.objtype))
+			phase1 = run(phase1, filename, err)
+			constraintFile, indexFile, err := tablePartFilenames(base, v)
+			constraints = run(constraints, constraintFile, err)
+			indexes = run(indexes, indexFile, err)
+		default:
+			filename, err := objFilename(base, v, "", outputExt(v.objtype))
+			phase1 = run(phase1, filename, err)
+		}
+
+		if separateGrants() && grantsFile == "object" {
+			filename, err := grantsFilename(base, v)
+			phase1 = run(phase1, filename, err)
+		}
+	}
+	for _, schema := range schemas {
+		// including the schemas that have no objects to install
+		schemaGrantsFile(schema)
+	}
+	phase2 = append(phase2, bodies...)
+	phase2 = append(phase2, indexes...)
+	phase2 = append(phase2, constraints...)
+
+	var l []string
+	l = append(l, strings.TrimSpace(sqlplusHeader), "")
+	l = append(l, "PROMPT Creating the objects", "")
+	l = append(l, phase1...)
+	l = append(l, "", "PROMPT Applying the grants, bodies, indexes, and constraints", "")
+	l = append(l, phase2...)
+	l = append(l, "", "PROMPT Recompiling the schemas", "")
+	for _, schema := range schemas {
+		l = append(l, dex.CompileSchemaCommand(schema))
+	}
+
+	err = emit("install", filepath.Join(base, installFile), []byte(strings.Join(l, "\n")+"\n"))
+	logError(err)
+}
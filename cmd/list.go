@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"strconv"
+	"time"
+
+	dex "github.com/gsiems/oradex"
+)
+
+// listObjects writes the inventory of the objects that would be
+// extracted for the schemas, with the status, created and last DDL
+// times, and number of lines of source of each, to stdout as either
+// JSON or CSV
+func listObjects(ctx context.Context, db *sql.DB) {
+
+	l, err := getSchemaList(ctx, schemas, xclude)
+	failOnErr(err)
+
+	items := []dex.InventoryItem{}
+	for _, schema := range l {
+		objs, err := getObjList(ctx, db, schema)
+		if err != nil {
+			logError(err)
+			continue
+		}
+
+		inv, err := dex.SchemaInventory(ctx, db, schema)
+		if err != nil {
+			logError(err)
+			continue
+		}
+
+		for _, v := range filterObjList(objs) {
+			item, ok := inv[dex.InventoryKey(v.objname, v.objtype)]
+			if !ok && v.objtype == "QUEUE TABLE" {
+				item, ok = inv[dex.InventoryKey(v.objname, "TABLE")]
+			}
+			if !ok {
+				item = dex.InventoryItem{Schema: v.owner, Name: v.objname}
+			}
+			item.ObjType = v.objtype
+			items = append(items, item)
+		}
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		failOnErr(enc.Encode(items))
+		return
+	}
+
+	ts := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("2006-01-02T15:04:05")
+	}
+
+	w := csv.NewWriter(os.Stdout)
+	failOnErr(w.Write([]string{"schema", "name", "type", "status", "created", "last_ddl_time", "lines"}))
+	for _, v := range items {
+		failOnErr(w.Write([]string{v.Schema, v.Name, v.ObjType, v.Status, ts(v.Created), ts(v.LastDDLTime), strconv.FormatInt(v.Lines, 10)}))
+	}
+	w.Flush()
+	failOnErr(w.Error())
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	dex "github.com/gsiems/oradex"
+)
+
+// manifestWriter collects the files written for the objects of an
+// extraction for the manifest in the base directory
+type manifestWriter struct {
+	mu      sync.Mutex
+	base    string
+	entries map[string]dex.ManifestEntry
+}
+
+// manifest is the manifest for the current extraction, or nil if there
+// is none
+var manifest *manifestWriter
+
+func newManifestWriter(base string) *manifestWriter {
+	return &manifestWriter{base: base, entries: make(map[string]dex.ManifestEntry)}
+}
+
+// add records a file written for an object
+func (m *manifestWriter) add(v obj, filename string, b []byte) {
+	if m == nil {
+		return
+	}
+
+	rel, err := filepath.Rel(m.base, filename)
+	if err != nil {
+		rel = filename
+	}
+
+	// checksum the file as written
+	if eb, err := encodeOutput(b); err == nil {
+		b = eb
+	}
+
+	e := dex.ManifestEntry{
+		Schema:    v.owner,
+		Name:      v.objname,
+		ObjType:   v.objtype,
+		File:      filepath.ToSlash(rel),
+		SHA256:    dex.Checksum(b),
+		Extracted: time.Now().UTC(),
+	}
+	delta.record(v, e.File, e.SHA256)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[e.File] = e
+}
+
+// setDDLTimes sets the last DDL time of the recorded objects of the
+// schema from the schema inventory
+func (m *manifestWriter) setDDLTimes(ctx context.Context, db *sql.DB, schema string) error {
+	if m == nil {
+		return nil
+	}
+
+	inv, err := dex.SchemaInventory(ctx, db, schema)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for k, e := range m.entries {
+		if e.Schema != schema || !e.LastDDLTime.IsZero() {
+			continue
+		}
+		item, ok := inv[dex.InventoryKey(e.Name, e.ObjType)]
+		if !ok && e.ObjType == "QUEUE TABLE" {
+			item, ok = inv[dex.InventoryKey(e.Name, "TABLE")]
+		}
+		if ok {
+			e.LastDDLTime = item.LastDDLTime.UTC()
+			m.entries[k] = e
+		}
+	}
+	return nil
+}
+
+// write merges the recorded files into the manifest in the base
+// directory. Entries from previous extractions are kept for as long as
+// their files exist, so that the manifest describes all of the files in
+// the base directory.
+func (m *manifestWriter) write() error {
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	filename := filepath.Join(m.base, dex.ManifestFile)
+
+	prev, err := dex.ReadManifest(filename)
+	if err != nil {
+		return err
+	}
+
+	entries := make(map[string]dex.ManifestEntry)
+	if archive == nil {
+		for _, e := range prev.Objects {
+			if _, err := os.Stat(filepath.Join(m.base, filepath.FromSlash(e.File))); err == nil {
+				entries[e.File] = e
+			}
+		}
+	}
+	for k, e := range m.entries {
+		entries[k] = e
+	}
+
+	mf := dex.Manifest{Created: time.Now().UTC(), Objects: []dex.ManifestEntry{}}
+	for _, e := range entries {
+		mf.Objects = append(mf.Objects, e)
+	}
+	sort.Slice(mf.Objects, func(i, j int) bool {
+		return mf.Objects[i].File < mf.Objects[j].File
+	})
+
+	b, err := mf.Marshal()
+	if err != nil {
+		return err
+	}
+	return writeIfChanged(filename, b)
+}
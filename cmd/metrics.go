@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	dex "github.com/gsiems/oradex"
+)
+
+// metrics contains the extraction statistics that are exposed, in the
+// Prometheus text format, when running in watch or serve mode
+type metrics struct {
+	mu        sync.Mutex
+	objects   map[string]int64
+	errors    map[string]int64
+	durations map[string]time.Duration
+	bytes     int64
+}
+
+// stats is the metrics for the current run, or nil if metrics are not
+// enabled
+var stats *metrics
+
+func newMetrics() *metrics {
+	return &metrics{
+		objects:   make(map[string]int64),
+		errors:    make(map[string]int64),
+		durations: make(map[string]time.Duration),
+	}
+}
+
+// observe records the result of extracting an object
+func (m *metrics) observe(objType string, res dex.ObjectResult, err error) {
+	if m == nil {
+		return
+	}
+
+	objType = coalesce(res.ObjType, objType)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.objects[objType]++
+	m.durations[objType] += res.Elapsed
+	m.errors[objType] += int64(len(res.Errors))
+	if err != nil {
+		m.errors[objType]++
+	}
+}
+
+// written records the number of bytes written
+func (m *metrics) written(n int) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.bytes += int64(n)
+}
+
+// writeTo writes the metrics in the Prometheus text exposition format
+func (m *metrics) writeTo(w io.Writer) error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var types []string
+	for k := range m.objects {
+		types = append(types, k)
+	}
+	sort.Strings(types)
+
+	var l []string
+
+	l = append(l,
+		"# HELP oradex_objects_extracted_total The number of objects extracted.",
+		"# TYPE oradex_objects_extracted_total counter")
+	for _, t := range types {
+		l = append(l, fmt.Sprintf("oradex_objects_extracted_total{type=%q} %d", t, m.objects[t]))
+	}
+
+	l = append(l,
+		"# HELP oradex_errors_total The number of errors encountered while extracting objects.",
+		"# TYPE oradex_errors_total counter")
+	for _, t := range types {
+		l = append(l, fmt.Sprintf("oradex_errors_total{type=%q} %d", t, m.errors[t]))
+	}
+
+	l = append(l,
+		"# HELP oradex_extract_duration_seconds The time spent extracting objects.",
+		"# TYPE oradex_extract_duration_seconds summary")
+	for _, t := range types {
+		l = append(l,
+			fmt.Sprintf("oradex_extract_duration_seconds_sum{type=%q} %g", t, m.durations[t].Seconds()),
+			fmt.Sprintf("oradex_extract_duration_seconds_count{type=%q} %d", t, m.objects[t]))
+	}
+
+	l = append(l,
+		"# HELP oradex_bytes_written_total The number of bytes written.",
+		"# TYPE oradex_bytes_written_total counter",
+		fmt.Sprintf("oradex_bytes_written_total %d", m.bytes))
+
+	_, err := io.WriteString(w, strings.Join(l, "\n")+"\n")
+	return err
+}
+
+// serveMetrics serves the metrics, at /metrics, on the address until
+// interrupted
+func serveMetrics(ctx context.Context, addr string) error {
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		logError(stats.writeTo(w))
+	})
+
+	return listenAndServe(ctx, addr, mux)
+}
+
+// servePprof serves the pprof profiles, at /debug/pprof/, on the address
+// until interrupted. Addresses without a host are bound to localhost as
+// the profiles are not authenticated.
+func servePprof(ctx context.Context, addr string) error {
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if host == "" {
+		addr = net.JoinHostPort("localhost", port)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	return listenAndServe(ctx, addr, mux)
+}
+
+// listenAndServe listens on the address, so that any error in doing so
+// is returned immediately, and then serves the handler in the background
+// until interrupted
+func listenAndServe(ctx context.Context, addr string, h http.Handler) error {
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Handler:           h,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		sctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		logError(srv.Shutdown(sctx))
+	}()
+
+	go func() {
+		err := srv.Serve(l)
+		if !errors.Is(err, http.ErrServerClosed) {
+			logError(err)
+		}
+	}()
+
+	return nil
+}
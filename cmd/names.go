@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	dex "github.com/gsiems/oradex"
+)
+
+// namesFile is the name of the file, in the base directory, that maps
+// the escaped file names back to the schema and object names
+const namesFile = ".oradex.names"
+
+// nameMap records the files whose names were escaped (see
+// dex.SafeFileName), keyed by the file name
+type nameMap struct {
+	mu sync.Mutex
+	m  map[string][2]string
+}
+
+// escapedNames is the escaped file names for the current run
+var escapedNames = &nameMap{m: make(map[string][2]string)}
+
+// safeName escapes a schema or object name for use in a file name (see
+// dex.CaseSafeFileName). The wildcard used for matching all of the files
+// is left as is.
+func safeName(name string) string {
+	if name == "*" {
+		return name
+	}
+	return dex.CaseSafeFileName(name)
+}
+
+// add records the schema and object name for the file if either needed
+// escaping
+func (n *nameMap) add(filename, schema, name string) {
+
+	if safeName(schema) == schema && safeName(name) == name {
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.m[filename] = [2]string{schema, name}
+}
+
+// write merges the escaped file names into the names file in the base
+// directory. The file has a tab separated line, of the file name
+// (relative to the base directory), schema, and object name, for each
+// file whose name was escaped. Entries for files that no longer exist
+// are dropped.
+func (n *nameMap) write(base string) error {
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if len(n.m) == 0 {
+		return nil
+	}
+
+	filename := filepath.Join(base, namesFile)
+
+	entries := make(map[string]string)
+	f, err := os.Open(filename)
+	if err == nil {
+		s := bufio.NewScanner(f)
+		for s.Scan() {
+			l := strings.SplitN(s.Text(), "\t", 2)
+			if len(l) == 2 {
+				entries[l[0]] = l[1]
+			}
+		}
+		err = s.Err()
+		f.Close()
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for k := range entries {
+		if _, err := os.Stat(filepath.Join(base, filepath.FromSlash(k))); err != nil {
+			delete(entries, k)
+		}
+	}
+
+	for k, v := range n.m {
+		rel, err := filepath.Rel(base, k)
+		if err != nil {
+			return err
+		}
+		entries[filepath.ToSlash(rel)] = v[0] + "\t" + v[1]
+	}
+
+	var l []string
+	for k, v := range entries {
+		l = append(l, k+"\t"+v)
+	}
+	sort.Strings(l)
+
+	return writeIfChanged(filename, []byte(strings.Join(l, "\n")+"\n"))
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+
+	dex "github.com/gsiems/oradex"
+)
+
+// extractObject extracts the DDL for a specific database object
+func extractObject(ctx context.Context, db *sql.DB, schema, name string) {
+
+	switch {
+	case deps:
+		l, err := dex.DependencyClosure(ctx, db, []dex.QualifiedName{{Schema: schema, Name: name}})
+		failOnErr(err)
+		extractObjects(ctx, db, l)
+		return
+	case dependents:
+		l, err := dex.Dependents(ctx, db, []dex.QualifiedName{{Schema: schema, Name: name}})
+		failOnErr(err)
+		extractObjects(ctx, db, l)
+		return
+	}
+
+	objType, err := dex.ObjType(ctx, db, schema, name)
+	failOnErr(err)
+	if objType == "" {
+		failOnErr(fmt.Errorf("no object found for %q.%q", schema, name))
+	}
+
+	if format != "sql" {
+		b, _, err := exportObj(ctx, obj{owner: schema, objname: name, objtype: objType})
+		failOnErr(err)
+		os.Stdout.Write(b)
+		return
+	}
+
+	_, err = ex.ExportDDLTo(ctx, os.Stdout, schema, name, objType)
+	failOnErr(err)
+
+	fmt.Println()
+}
+
+// extractObjects extracts the DDL for a list of database objects, in
+// the order given, to stdout
+func extractObjects(ctx context.Context, db *sql.DB, l []dex.QualifiedName) {
+
+	for _, q := range l {
+		objType, err := dex.ObjType(ctx, db, q.Schema, q.Name)
+		if err != nil {
+			failObject(err)
+			continue
+		}
+		if objType == "" {
+			failObject(fmt.Errorf("no object found for %q.%q", q.Schema, q.Name))
+			continue
+		}
+
+		b, _, err := exportObj(ctx, obj{owner: q.Schema, objname: q.Name, objtype: objType})
+		if err != nil {
+			failObject(err)
+			continue
+		}
+
+		if format == "sql" {
+			fmt.Printf("-- %s \"%s\".\"%s\"\n", strings.ToLower(objType), q.Schema, q.Name)
+			if prompts {
+				fmt.Printf("PROMPT %s \"%s\".\"%s\"\n", strings.ToLower(objType), q.Schema, q.Name)
+			}
+		}
+		os.Stdout.Write(b)
+	}
+}
+
+// splitObjName takes a string of schema.object name and splits it into
+// the separate schema and object name strings.
+func splitObjName(objectName string) (string, string) {
+
+	var schema, name string
+
+	// Note: check for case sensitivity/quote marks? If quoted then leave case as is
+	fq := strings.Split(objectName, ".")
+
+	qt := "\""
+
+	for i, _ := range fq {
+		if strings.Contains(fq[i], qt) {
+			fq[i] = strings.Replace(fq[i], qt, "", -1)
+		} else {
+			fq[i] = strings.ToUpper(fq[i])
+		}
+	}
+
+	switch len(fq) {
+	case 1:
+		name = fq[0]
+	case 2:
+		schema = fq[0]
+		name = fq[1]
+	}
+
+	return schema, name
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	dex "github.com/gsiems/oradex"
+)
+
+// getSchemaList returns the list of database schemas taking into account the allowed or excluded schemas list
+func getSchemaList(ctx context.Context, schemas, xclude string) ([]string, error) {
+
+	return ex.ListSchemas(ctx, csvList(schemas), csvList(xclude))
+}
+
+// getRoleList returns the list of non-Oracle maintained database roles
+func getRoleList(ctx context.Context, db *sql.DB) ([]string, error) {
+
+	query := `
+SELECT r.role
+    FROM dba_roles r
+    WHERE %s
+    ORDER BY r.role
+`
+	return getNameList(ctx, db, fmt.Sprintf(query, dex.OracleMaintainedClause(ctx, "r", "role")))
+}
+
+// getNameList returns the list of names returned by a query
+func getNameList(ctx context.Context, db *sql.DB, query string) ([]string, error) {
+
+	var l []string
+
+	rows, err := db.QueryContext(ctx, dex.DictQuery(ctx, query))
+	if err != nil {
+		return l, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	for rows.Next() {
+		var name string
+		err = rows.Scan(&name)
+		if err != nil {
+			logError(err)
+		} else {
+			l = append(l, name)
+		}
+	}
+
+	return l, err
+}
+
+// getObjList returna a list of database objects for the specified schema
+func getObjList(ctx context.Context, db *sql.DB, schema string) ([]obj, error) {
+
+	var l []obj
+
+	f := dex.Filter{SeparateTriggers: ex.Options().SeparateTriggers}
+
+	objs, err := dex.ListObjects(ctx, db, schema, f)
+	for _, o := range objs {
+		l = append(l, obj{
+			owner:   o.Schema,
+			objname: o.Name,
+			objtype: o.ObjType,
+			dirname: o.DirName,
+		})
+	}
+
+	return l, err
+}
+
+// filterObjList removes the objects that are not to be extracted from
+// the object list
+func filterObjList(l []obj) []obj {
+
+	opts := ex.Options()
+
+	var f []obj
+	for _, v := range l {
+		if opts.IncludeObject(v.objname, v.objtype) {
+			f = append(f, v)
+		}
+	}
+	return f
+}
+
+// getChangedObjList returns a list of database objects for the specified
+// schema that have changed since the specified time
+func getChangedObjList(ctx context.Context, db *sql.DB, schema string, since time.Time) ([]obj, error) {
+
+	var l []obj
+
+	times, err := dex.GetChangedObjectTimes(ctx, db, schema, since)
+	if err != nil {
+		return l, err
+	}
+
+	var names []string
+	if watching != nil {
+		names = watching.changed(schema, times)
+	} else {
+		for name := range times {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	added := make(map[obj]bool)
+	for _, name := range names {
+
+		objType, err := dex.ObjType(ctx, db, schema, name)
+		if err != nil {
+			logError(err)
+			continue
+		}
+
+		o := obj{
+			owner:   schema,
+			objname: name,
+			objtype: objType,
+			dirname: strings.Replace(objType, " ", "_", -1),
+		}
+
+		// Unless the triggers are extracted separately, a trigger is
+		// extracted with the table, or view, that it is on
+		if objType == "TRIGGER" && !ex.Options().SeparateTriggers {
+			t, tableType, err := dex.ObjTriggerTable(ctx, db, schema, name)
+			if err != nil {
+				logError(err)
+				continue
+			}
+			if t.Name != "" {
+				o = obj{
+					owner:   t.Schema,
+					objname: t.Name,
+					objtype: tableType,
+					dirname: strings.Replace(tableType, " ", "_", -1),
+				}
+			}
+		}
+
+		if !added[o] {
+			added[o] = true
+			l = append(l, o)
+		}
+	}
+
+	return l, nil
+}
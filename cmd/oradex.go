@@ -1,20 +1,17 @@
 package main
 
 import (
-	"database/sql"
+	"context"
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
-
-	//
-	_ "github.com/godror/godror"
-	//
+	"text/template"
+	"time"
 
 	dex "github.com/gsiems/oradex"
-	orap "github.com/gsiems/orapass"
 )
 
 type obj struct {
@@ -24,365 +21,367 @@ type obj struct {
 	dirname string
 }
 
-type objList map[string]obj
+// Exit codes
+const (
+	exitOK      = 0 // success
+	exitDiff    = 1 // differences were found (-check, compare, preflight)
+	exitError   = 2 // the extraction failed
+	exitConnect = 3 // unable to connect to the database
+	exitPartial = 4 // some objects failed to extract (-strict)
+)
 
 var (
 	showVersion  bool
 	version      = "0.1"
 	alter        bool
+	archiveFile  string
 	base         string
+	bom          bool
+	check        bool
+	compareTo    string
+	compat       bool
+	configFile   string
+	connectAs    string
+	consState    string
+	constraints  = "inline"
+	currentValue bool
+	dataTables   string
 	dbName       string
 	debug        bool
+	deltaFile    string
+	dependents   bool
+	deps         bool
+	directories  bool
+	drop         string
+	dropCascade  bool
+	dryRun       bool
+	dsnStr       string
+	encoding     = "utf-8"
+	eol          string
+	exclude      string
+	excludeSys   = true
+	external     bool
+	extraXclude  string
 	force        bool
+	format       = "sql"
+	gitCommitOn  bool
+	gitMessage   string
+	grantsFile   string
 	grantsOf     bool
+	graph        string
 	host         string
+	include      string
+	install      bool
+	keepSnaps    int
+	keywordCase  string
+	listen       = "localhost:8080"
+	listMode     bool
+	lockdown     bool
+	logFormat    = "text"
+	logLevel     = "info"
+	lookupData   bool
+	lowerNames   bool
+	metaFormat   = "ddl"
+	metricsAddr  string
 	neededGrants bool
+	noDba        bool
+	noExcludeSys bool
+	noPublic     bool
+	normalize    bool
+	noSchema     bool
 	objectName   string
 	objGrants    bool
+	objTimeout   time.Duration
 	orapassFile  string
+	passwdStdin  bool
+	password     string
+	pathTmpl     = dex.DefaultPathTemplate
+	pdbs         string
+	pkgFiles     = "pks"
+	pprofAddr    string
 	port         string
+	preflight    bool
+	pretty       bool
+	profile      string
+	prompts      bool
+	proxy        string
+	prune        bool
 	quiet        bool
+	received     bool
+	recompile    string
+	regex        bool
+	remapSchema  string
+	remapTs      string
+	reportStale  bool
+	resume       bool
+	retries      int
+	retryWait    = dex.DefaultRetryBackoff
+	roles        bool
+	scheduler    bool
+	schemaRoles  bool
 	schemas      string
+	sequences    = "live"
+	serveMode    bool
+	showDiff     bool
+	since        string
+	singleFile   bool
+	sizeReport   bool
+	snapshot     bool
+	snapTag      string
+	splitPkg     bool
+	splitType    bool
+	sqlplus      bool
 	storage      bool
+	strict       bool
+	summaryFile  string
+	synonyms     bool
+	sysPrivs     bool
+	tablespaces  bool
+	target       string
+	tnsAdmin     string
+	toggle       bool
+	triggers     = "inline"
+	typeExt      bool
+	types        string
+	uninstall    bool
 	user         string
+	users        bool
+	wallet       string
+	watch        time.Duration
 	xclude       string
+	xtypes       string
+
+	namer    dex.FileNamer
+	gitTmpl  *template.Template
+	gitInfo  gitMessageInfo
+	drift    bool
+	partial  bool
+	archive  *archiveWriter
+	ex       *dex.Extractor
+	watching *watchState
+	cmdFlags *flag.FlagSet
 )
 
 func main() {
-	flag.Usage = func() {
-		fmt.Fprint(os.Stderr, `usage: oradex [flags]
-
-Database connection flags
-
-  -d      The database to connect to. Overrides the ORACLE_SID
-          environment variable.
-
-  -h      The hostname that the database is on. Overrides the
-          ORACLE_HOST environment variable. Defaults to localhost.
-
-  -f      The orapass file to search for first.
-
-  -p      The port that the database is listening on. Overrides the
-          ORACLE_PORT environment variable. Defaults to 1521.
-
-  -u      The username to obtain a password for. Overrides the
-          ORACLE_USER environment variable. Defaults to the OS user.
-
-Common extract flags
-
-  -alter  Include constraints as ALTER commands. Defaults to including
-          constraints as part of the create command.
-
-  -needed Include grants needed by the object exported. Since Oracle
-          does not stort privilege or dependency information at a fine
-          enough level of detail this is a best guess and may contain
-          additional privileges not actually needed for the object.
-
-  -grants Include grants on the object.
-
-  -force  Include the FORCE keywork in CREATE DDL commands
 
-  -storage Include storage parameters in CREATE commands.
-
-Extract database/schema(s) DDL flags
-
-  -b      The base directory to write the extracted DDL to. Overrides
-          the BASE_DIR environment variable. Defaults to the current
-          directory.
-
-  -s      The comma separated list of schemas to extract.
-
-  -x      The comma separated list of schemas to exclude.
-          Ignored if the -s flag is supplied.
-
-Extract object DDL flags
-
-  -o      The schema.object_name of the object to extract.
-          If specified then the -b, -s, and -x flags are ignored.
-
-Other flags
-
-  -debug
+	// oradex [command] [flags] [args]
+	var command string
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		command = os.Args[1]
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+	switch command {
+	case "", "extract", "object", "compare", "diff":
+	case "list":
+		listMode = true
+	case "preflight":
+		preflight = true
+	case "serve":
+		serveMode = true
+	default:
+		usage()
+		exitOnErr(exitError, fmt.Errorf("unknown command %q", command))
+	}
 
-  -q      Quiet mode. Do not print any error messages.
+	cmdFlags = newFlagSet(command)
+	cmdFlags.Parse(os.Args[1:])
 
-`)
+	// Flags may follow the positional arguments, i.e.
+	// "oradex object APP.ORDERS -grants"
+	var args []string
+	for cmdFlags.NArg() > 0 {
+		args = append(args, cmdFlags.Arg(0))
+		cmdFlags.Parse(cmdFlags.Args()[1:])
 	}
-	flag.BoolVar(&showVersion, "version", false, "")
-	flag.BoolVar(&alter, "alter", false, "")
-	flag.StringVar(&base, "b", "", "")
-	flag.StringVar(&dbName, "d", "", "")
-	flag.BoolVar(&debug, "debug", false, "")
-	flag.BoolVar(&force, "force", false, "")
-	flag.BoolVar(&grantsOf, "grants", false, "")
-	flag.StringVar(&host, "h", "", "")
-	flag.BoolVar(&neededGrants, "needed", false, "")
-	flag.StringVar(&objectName, "o", "", "")
-	flag.BoolVar(&objGrants, "", false, "")
-	flag.StringVar(&orapassFile, "f", "", "")
-	flag.StringVar(&port, "p", "", "")
-	flag.BoolVar(&quiet, "q", false, "")
-	flag.StringVar(&schemas, "s", "", "")
-	flag.BoolVar(&storage, "storage", false, "")
-	flag.StringVar(&user, "u", "", "")
-	flag.StringVar(&xclude, "x", "", "")
-
-	flag.Parse()
 
+	// The version is shown without reading the configuration, which may
+	// not be valid for this version
 	if showVersion {
 		fmt.Println(version)
-		os.Exit(0)
+		os.Exit(exitOK)
 	}
 
-	var p orap.Parser
-
-	p.Username = user
-	p.Host = host
-	p.Port = port
-	p.DbName = dbName
-	p.OrapassFile = orapassFile
-	p.Debug = debug
+	// -q also applies to the errors in reading the configuration
+	if quiet {
+		dex.SetLogger(dex.NewLogger(os.Stderr, dex.LevelOff, false))
+	}
 
-	cp, err := p.GetPasswd()
-	failOnErr(quiet, err)
+	err := loadConfig(configFile)
+	failOnErr(err)
 
-	// NB that connStr asserts that the database can be resolved through TNS
-	connStr := fmt.Sprintf("%s/%s@%s", cp.Username, cp.Password, cp.DbName)
-	db, err := sql.Open("godror", connStr)
-	failOnErr(quiet, err)
-	defer func() {
-		if cerr := db.Close(); cerr != nil && err == nil {
-			err = cerr
-		}
-	}()
+	err = applyProfile(profile)
+	failOnErr(err)
 
-	_, err = dex.InitDbmsMetadata(db, storage, force, alter)
-	failOnErr(quiet, err)
+	level, err := dex.ParseLevel(logLevel)
+	failOnErr(err)
+	if debug {
+		level = dex.LevelDebug
+	}
+	if quiet {
+		level = dex.LevelOff
+	}
+	if logFormat != "text" && logFormat != "json" {
+		failOnErr(fmt.Errorf("unknown log format %q", logFormat))
+	}
+	dex.SetLogger(dex.NewLogger(os.Stderr, level, logFormat == "json"))
 
-	// database, schema(s), or object?
-	switch objectName {
-	case "":
-		extractSchemas(db, schemas, xclude, base, quiet, neededGrants, grantsOf)
+	err = checkFlags(command, args)
+	failOnErr(err)
 
+	dsnStr = dsnFromEnv()
+	if dsnStr != "" && dbName != "" {
+		failOnErr(fmt.Errorf("the -dsn flag can not be used with the -d flag"))
+	}
+	if external && (user != "" || orapassFile != "") {
+		failOnErr(fmt.Errorf("the -external flag can not be used with the -u or -f flags"))
+	}
+	if passwdStdin {
+		if external {
+			failOnErr(fmt.Errorf("the -password-stdin flag can not be used with the -external flag"))
+		}
+		var err error
+		password, err = readPassword(os.Stdin)
+		failOnErr(err)
+	}
+	failOnErr(setTNSAdmin())
+	switch connectAs {
+	case "", "sysdba", "sysoper":
 	default:
-		schema, name := splitObjName(objectName)
-		schema = coalesce(schema, schemas)
-		extractObject(db, schema, name, quiet, neededGrants, grantsOf)
+		failOnErr(fmt.Errorf("invalid -as privilege: %q (expected sysdba or sysoper)", connectAs))
 	}
 
-}
-
-// extractObject extracts the DDL for a specific database object
-func extractObject(db *sql.DB, schema, name string, quiet, neededGrants, grantsOf bool) {
-
-	objType, err := dex.ObjType(db, schema, name)
-	failOnErr(quiet, err)
-
-	objDDL, err := dex.ExportDDL(db, schema, name, objType, quiet, neededGrants, grantsOf)
-	failOnErr(quiet, err)
-
-	fmt.Println(objDDL)
-}
-
-// extractSchemas extracts the database objects for a list of schemas
-func extractSchemas(db *sql.DB, schemas, xclude, base string, quiet, neededGrants, grantsOf bool) {
+	// Multiple databases are each extracted to their own directory
+	dbNames := []string{dbName}
+	if strings.Contains(dbName, ",") {
+		dbNames = nil
+		for _, d := range strings.Split(dbName, ",") {
+			if d = strings.TrimSpace(d); d != "" {
+				dbNames = append(dbNames, d)
+			}
+		}
+		if serveMode || watch > 0 || objectName != "" || compareTo != "" || graph != "" {
+			failOnErr(fmt.Errorf("multiple databases can not be used in serve, compare, or watch mode or with the -o or -graph flags"))
+		}
+	}
+	if pdbs != "" && (serveMode || watch > 0 || objectName != "" || compareTo != "" || graph != "") {
+		failOnErr(fmt.Errorf("the -pdbs flag can not be used in serve, compare, or watch mode or with the -o or -graph flags"))
+	}
+	if deltaFile != "" && (len(dbNames) > 1 || pdbs != "") {
+		failOnErr(fmt.Errorf("the -delta flag can only be used with a single database"))
+	}
 
-	l, err := getSchemaList(db, schemas, xclude, quiet)
-	failOnErr(quiet, err)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
-	for _, schema := range l {
-		extractSchema(db, base, schema, quiet, neededGrants, grantsOf)
+	var sinceTime time.Time
+	if since != "" {
+		sinceTime, err = time.Parse("2006-01-02T15:04:05", since)
+		failOnErr(err)
 	}
-}
 
-// extractSchema extracts the database objects for a schema
-func extractSchema(db *sql.DB, base, schema string, quiet, neededGrants, grantsOf bool) {
+	opts, err := exportOptions()
+	failOnErr(err)
+
+	// Snapshots are written to a directory, named for the tag or the
+	// time, under the base directory
+	snapBase := base
+	var tag string
+	if snapshot {
+		tag, err = snapshotTag(snapTag)
+		failOnErr(err)
+		base = filepath.Join(base, tag)
+	}
 
-	l, err := getObjList(db, schema, quiet)
-	failOnErr(quiet, err)
+	if deltaFile != "" {
+		m, err := dex.ReadManifest(deltaFile)
+		failOnErr(err)
+		delta = newDeltaState(m)
+	}
 
-	if len(l) == 0 {
-		carp(quiet, fmt.Errorf("no objects returned for %q", schema))
-		return
+	if archiveFile != "" {
+		archive, err = newArchiveWriter(archiveFile, base)
+		failOnErr(err)
 	}
 
-	for _, v := range l {
-		dir := filepath.Join(base, v.owner, v.dirname)
+	if metricsAddr != "" {
+		stats = newMetrics()
+		failOnErr(serveMetrics(ctx, metricsAddr))
+	}
+	if pprofAddr != "" {
+		failOnErr(servePprof(ctx, pprofAddr))
+	}
 
-		err = os.MkdirAll(dir, 0700)
-		if err != nil {
-			carp(quiet, err)
-			continue
+	for _, d := range dbNames {
+		dbBase := base
+		if len(dbNames) > 1 {
+			dbBase = filepath.Join(base, d)
 		}
-
-		objDDL, err := dex.ExportDDL(db, v.owner, v.objname, v.objtype, quiet, neededGrants, grantsOf)
-		if err != nil {
-			carp(quiet, err)
-			continue
+		extractDatabase(ctx, d, dbBase, opts, sinceTime)
+		if ctx.Err() != nil {
+			break
 		}
-
-		filename := fmt.Sprintf("%s.sql", filepath.Join(dir, v.objname))
-
-		err = ioutil.WriteFile(filename, []byte(objDDL+"\n\n"), 0600)
-		carp(quiet, err)
 	}
-}
 
-// csvSplit splits a somma-separated list into a map
-func csvSplit(s string) map[string]int {
-
-	l := make(map[string]int)
-
-	for i, v := range strings.Split(s, ",") {
-		l[v] = i
+	if !check && !dryRun {
+		failOnErr(escapedNames.write(base))
 	}
-	return l
-}
-
-// getSchemaList returns the list of database schemas taking into account the allowed or excluded schemas list
-func getSchemaList(db *sql.DB, schemas, xclude string, quiet bool) ([]string, error) {
 
-	var l []string
+	if snapshot && ctx.Err() == nil {
+		failOnErr(finishSnapshot(snapBase, tag, keepSnaps))
+	}
 
-	included := csvSplit(schemas)
-	excluded := csvSplit(xclude)
-
-	query := `
-SELECT DISTINCT owner
-    FROM dba_objects
-    WHERE owner NOT IN  (
-                'APPQOSSYS', 'AUDSYS', 'CTXSYS', 'DBSFWUSER', 'DBSNMP', 'DMSYS', 'EXFSYS', 'GSMADMIN_INTERNAL',
-                'MDSYS', 'OJVMSYS', 'OLAPSYS', 'ORACLE_OCM', 'ORDSYS', 'OUTLN', 'PERFSTAT',
-                'REMOTE_SCHEDULER_AGENT', 'SQLTXPLAIN', 'SYS', 'SYSMAN', 'SYSTEM', 'TSMSYS', 'WMSYS', 'XDB' )
-        AND object_type IN (
-                'DATABASE LINK', 'FUNCTION', 'MATERIALIZED VIEW', 'PACKAGE', 'PROCEDURE', 'SEQUENCE', 'TABLE', 'TYPE', 'VIEW' )
-`
-
-	rows, err := db.Query(query)
-	if err != nil {
-		return l, err
+	if delta != nil {
+		failOnErr(delta.writeReport(os.Stdout))
 	}
-	defer func() {
-		if cerr := rows.Close(); cerr != nil && err == nil {
-			err = cerr
+
+	if !summary.empty() {
+		summary.finish()
+		if !quiet {
+			logError(summary.writeText(os.Stderr))
 		}
-	}()
-
-	var schema string
-
-	for rows.Next() {
-		err = rows.Scan(&schema)
-		if err != nil {
-			carp(quiet, err)
-		} else {
-
-			switch {
-			case schemas != "":
-				_, ok := included[schema]
-				if ok {
-					l = append(l, schema)
-				}
-			case xclude != "":
-				_, ok := excluded[schema]
-				if !ok {
-					l = append(l, schema)
-				}
-			default:
-				l = append(l, schema)
-			}
+		if summaryFile != "" {
+			failOnErr(summary.writeJSON(summaryFile))
 		}
 	}
 
-	return l, err
-}
-
-// getObjList returna a list of database objects for the specified schema
-func getObjList(db *sql.DB, schema string, quiet bool) ([]obj, error) {
-
-	var l []obj
-
-	query := `
-WITH objs AS (
-    SELECT owner,
-            object_name,
-            object_type,
-            row_number () OVER (
-                PARTITION BY owner, object_name
-                ORDER BY CASE
-                        WHEN object_type = 'MATERIALIZED VIEW' THEN 1
-                        WHEN object_type = 'PACKAGE' THEN 1
-                        WHEN object_type = 'TYPE' THEN 1
-                        WHEN object_type = 'TABLE' THEN 2
-                        WHEN object_type = 'VIEW' THEN 3
-                        WHEN object_type = 'SEQUENCE' THEN 4
-                        ELSE 10
-                        END ) AS rn
-        FROM dba_objects
-        WHERE object_type IN (
-                'DATABASE LINK', 'FUNCTION', 'MATERIALIZED VIEW', 'PACKAGE', 'PROCEDURE', 'SEQUENCE', 'TABLE', 'TYPE', 'VIEW' )
-            AND object_name NOT LIKE 'SYS_PLSQL%'
-            AND object_name <> 'CREATE$JAVA$LOB$TABLE'
-)
-SELECT owner,
-        object_name,
-        object_type,
-        regexp_replace ( object_type, '[[:space:]]+', '_' ) AS dir_name
-    FROM objs
-    WHERE owner = :1
-        AND rn = 1
-`
-
-	rows, err := db.Query(query, schema)
-	if err != nil {
-		return l, err
-	}
-	defer func() {
-		if cerr := rows.Close(); cerr != nil && err == nil {
-			err = cerr
-		}
-	}()
-
-	for rows.Next() {
-		var o obj
-		err = rows.Scan(&o.owner, &o.objname, &o.objtype, &o.dirname)
-		if err != nil {
-			carp(quiet, err)
-		} else {
-			l = append(l, o)
+	if archive != nil {
+		a := archive
+		archive = nil
+		if err := a.close(); err != nil {
+			a.abort()
+			failOnErr(err)
 		}
 	}
 
-	return l, err
+	switch {
+	case strict && partial:
+		os.Exit(exitPartial)
+	case drift:
+		os.Exit(exitDiff)
+	}
 }
 
-// splitObjName takes a string of schema.object name and splits it into
-// the separate schema and object name strings.
-func splitObjName(objectName string) (string, string) {
+// csvList splits a comma-separated list, dropping the empty entries
+func csvList(s string) []string {
 
-	var schema, name string
-
-	// Note: check for case sensitivity/quote marks? If quoted then leave case as is
-	fq := strings.Split(objectName, ".")
-
-	qt := "\""
+	var l []string
 
-	for i, _ := range fq {
-		if strings.Contains(fq[i], qt) {
-			fq[i] = strings.Replace(fq[i], qt, "", -1)
-		} else {
-			fq[i] = strings.ToUpper(fq[i])
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			l = append(l, v)
 		}
 	}
+	return l
+}
 
-	switch len(fq) {
-	case 1:
-		name = fq[0]
-	case 2:
-		schema = fq[0]
-		name = fq[1]
+// contains returns true if the list contains the string
+func contains(l []string, s string) bool {
+	for _, v := range l {
+		if v == s {
+			return true
+		}
 	}
-
-	return schema, name
+	return false
 }
 
 // coalesce picks the first non-empty string from a list
@@ -395,17 +394,45 @@ func coalesce(s ...string) string {
 	return ""
 }
 
-func failOnErr(quiet bool, err error) {
+// failOnErr reports the error, if any, and exits
+func failOnErr(err error) {
+	exitOnErr(exitError, err)
+}
+
+// exitOnErr reports the error, if any, and exits with the exit code.
+// Any archive being written is removed as it would be incomplete.
+func exitOnErr(code int, err error) {
 	if err != nil {
-		carp(quiet, err)
-		os.Exit(1)
+		logError(err)
+		if archive != nil {
+			logError(archive.abort())
+		}
+		os.Exit(code)
 	}
 }
 
-func carp(quiet bool, err error) {
+// failObject reports the failure to extract an object, which fails the
+// run in strict mode
+func failObject(err error) {
 	if err != nil {
-		if !quiet {
-			os.Stderr.WriteString(fmt.Sprintf("%s\n", err))
-		}
+		logError(err)
+		partial = true
+	}
+}
+
+// logError logs the error, if any
+func logError(err error) {
+	if err != nil {
+		dex.GetLogger().Error(err.Error())
 	}
 }
+
+// warn logs a warning with the context fields
+func warn(msg string, args ...interface{}) {
+	dex.GetLogger().Warn(msg, args...)
+}
+
+// info logs an informational message with the context fields
+func info(msg string, args ...interface{}) {
+	dex.GetLogger().Info(msg, args...)
+}
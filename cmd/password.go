@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	osuser "os/user"
+	"strings"
+
+	orap "github.com/gsiems/orapass"
+	"golang.org/x/term"
+)
+
+// passwordEnv is the environment variable for the password to connect
+// with, for when an orapass file is not practical
+const passwordEnv = "ORACLE_PASSWORD"
+
+// getPasswd returns the credentials for connecting as the user of the
+// parser. The password is, in order of precedence, the one read from
+// stdin (see -password-stdin), the ORACLE_PASSWORD environment variable,
+// or the entry in the orapass file. Failing those, the password is
+// prompted for when stdin is a terminal.
+func getPasswd(p orap.Parser) (orap.Parser, error) {
+
+	pw := password
+	if pw == "" {
+		pw = os.Getenv(passwordEnv)
+	}
+	if pw != "" {
+		passwdDefaults(&p)
+		p.Password = pw
+		return p, nil
+	}
+
+	cp, err := p.GetPasswd()
+	if err == nil || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return cp, err
+	}
+
+	passwdDefaults(&p)
+	p.Password, err = promptPassword(p.Username, p.DbName)
+	return p, err
+}
+
+// passwdDefaults sets the username and database, when not specified, as
+// the orapass lookup does
+func passwdDefaults(p *orap.Parser) {
+	if p.DbName == "" {
+		p.DbName = os.Getenv("ORACLE_SID")
+	}
+	if p.Username == "" {
+		p.Username = os.Getenv("ORACLE_USER")
+	}
+	if p.Username == "" {
+		if u, err := osuser.Current(); err == nil {
+			p.Username = u.Username
+		}
+	}
+}
+
+// promptPassword prompts, on stderr, for the password of the user
+// without echoing it
+func promptPassword(username, dbName string) (string, error) {
+
+	fmt.Fprintf(os.Stderr, "Password for %s@%s: ", username, dbName)
+	b, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if len(b) == 0 {
+		return "", fmt.Errorf("no password entered")
+	}
+	return string(b), nil
+}
+
+// readPassword reads the password from the first line of the reader
+func readPassword(r io.Reader) (string, error) {
+
+	s, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	s = strings.TrimRight(s, "\r\n")
+	if s == "" {
+		return "", fmt.Errorf("no password read from stdin")
+	}
+	return s, nil
+}
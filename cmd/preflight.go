@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	dex "github.com/gsiems/oradex"
+)
+
+// preflightCheck reports whether or not the connected user has the
+// privileges needed for extracting the DDL along with the grants for
+// any missing privileges
+func preflightCheck(ctx context.Context, db *sql.DB) {
+
+	l, err := dex.Preflight(ctx, db)
+	failOnErr(err)
+
+	for _, c := range l {
+		if c.OK {
+			fmt.Printf("ok       %s\n", c.Name)
+			continue
+		}
+		fmt.Printf("MISSING  %s\n         %s\n", c.Name, c.Grant)
+		if debug && c.Err != nil {
+			fmt.Printf("         %s\n", strings.TrimSpace(c.Err.Error()))
+		}
+		drift = true
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dex "github.com/gsiems/oradex"
+)
+
+// pruneSchema removes, or lists, the files for any objects that are on
+// disk but that no longer exist in the database
+func pruneSchema(ctx context.Context, db *sql.DB, base, schema string, remove bool) {
+
+	l, err := getObjList(ctx, db, schema)
+	if err != nil {
+		logError(err)
+		return
+	}
+
+	if separateGrants() && grantsFile == "schema" {
+		l = append(l, schemaGrantsObj(schema))
+	}
+
+	current := make(map[string]bool)
+	for _, v := range l {
+		files, err := objFiles(base, v)
+		if err != nil {
+			logError(err)
+			return
+		}
+		for _, filename := range files {
+			current[filename] = true
+		}
+	}
+
+	var pseudo []obj
+	if received {
+		pseudo = append(pseudo, schemaReceivedObj(schema))
+	}
+	if sequences == "restart" {
+		pseudo = append(pseudo, schemaRestartObj(schema))
+	}
+	if recompile != "" {
+		pseudo = append(pseudo, schemaRecompileObj(schema))
+	}
+	if uninstall {
+		pseudo = append(pseudo, schemaUninstallObj(schema))
+	}
+	if toggle {
+		pseudo = append(pseudo, schemaToggleObjs(schema)...)
+	}
+	for _, v := range pseudo {
+		filename, err := objFilename(base, v, "", "sql")
+		if err != nil {
+			logError(err)
+			return
+		}
+		current[filename] = true
+	}
+	tables, err := schemaDataTables(ctx, db, schema)
+	if err != nil {
+		logError(err)
+		return
+	}
+	for _, table := range tables {
+		for _, ext := range []string{"csv", "ctl"} {
+			filename, err := objFilename(base, dataObj(schema, table), "", ext)
+			if err != nil {
+				logError(err)
+				return
+			}
+			current[filename] = true
+		}
+	}
+
+	// Rendering the template with wildcards gives the pattern for all
+	// files for the schema. Unless the pattern is restricted to the
+	// schema there is no telling which files belong to which schema.
+	pattern, err := objFilename(base, obj{owner: schema, objname: "*", dirname: "*"}, "", "*")
+	if err != nil {
+		logError(err)
+		return
+	}
+	if !strings.Contains(strings.ToLower(pattern), strings.ToLower(schema)) {
+		logError(fmt.Errorf("unable to prune %q as the path template does not contain the schema", schema))
+		return
+	}
+
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		logError(err)
+		return
+	}
+
+	for _, filename := range files {
+		// Only the files that oradex writes are considered, anything
+		// else in the directories (i.e. a README) is left alone
+		if current[filename] || !writtenExt(filepath.Ext(filename)) {
+			continue
+		}
+
+		if remove {
+			logError(os.Remove(filename))
+			continue
+		}
+
+		if check {
+			drift = true
+			fmt.Printf("removed: %s\n", filename)
+			if showDiff {
+				disk, err := ioutil.ReadFile(filename)
+				logError(err)
+				fmt.Print(dex.UnifiedDiff(filename, "/dev/null", string(disk), "", 3))
+			}
+			continue
+		}
+		fmt.Printf("stale: %s\n", filename)
+	}
+}
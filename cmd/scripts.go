@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+
+	dex "github.com/gsiems/oradex"
+)
+
+// schemaRecompileObj returns the pseudo-object for the file that the
+// recompile script for a schema is written to
+func schemaRecompileObj(schema string) obj {
+	return obj{owner: schema, objname: "recompile", dirname: "RECOMPILE"}
+}
+
+// extractRecompile writes the script for recompiling the invalid objects
+// in a schema to a separate file
+func extractRecompile(ctx context.Context, db *sql.DB, base, schema string) {
+
+	script, err := dex.RecompileScript(ctx, db, schema, recompile == "utl_recomp")
+	if err != nil {
+		logError(err)
+		return
+	}
+
+	filename, err := objFilename(base, schemaRecompileObj(schema), "", "sql")
+	if err != nil {
+		logError(err)
+		return
+	}
+	if script == "" {
+		// No invalid objects, no file... unless it already exists
+		if _, err := os.Stat(filename); err != nil {
+			return
+		}
+	}
+
+	b := sqlplusScript([]byte(script+"\n\n"), "RECOMPILE", "", schema)
+	err = emit(fmt.Sprintf("%q recompile", schema), filename, b)
+	logError(err)
+}
+
+// schemaUninstallObj returns the pseudo-object for the file that the
+// uninstall script for a schema is written to
+func schemaUninstallObj(schema string) obj {
+	return obj{owner: schema, objname: "uninstall"}
+}
+
+// extractUninstall writes the script for dropping the extracted objects
+// of a schema, in reverse dependency order, to a separate file
+func extractUninstall(ctx context.Context, db *sql.DB, base, schema string) {
+
+	l, err := getObjList(ctx, db, schema)
+	if err != nil {
+		logError(err)
+		return
+	}
+
+	var objs []dex.ObjectEntry
+	for _, v := range filterObjList(l) {
+		objs = append(objs, dex.ObjectEntry{Schema: v.owner, Name: v.objname, ObjType: v.objtype})
+	}
+
+	script, err := dex.UninstallScript(ctx, db, schema, objs, dropCascade)
+	if err != nil {
+		logError(err)
+		return
+	}
+
+	filename, err := objFilename(base, schemaUninstallObj(schema), "", "sql")
+	if err != nil {
+		logError(err)
+		return
+	}
+
+	b := sqlplusScript([]byte(script+"\n\n"), "UNINSTALL", "", schema)
+	err = emit(fmt.Sprintf("%q uninstall", schema), filename, b)
+	logError(err)
+}
+
+// schemaToggleObjs returns the pseudo-objects for the files that the
+// constraint and trigger toggle scripts for a schema are written to
+func schemaToggleObjs(schema string) []obj {
+	var l []obj
+	for _, name := range []string{"constraints_disable", "constraints_enable", "triggers_disable", "triggers_enable"} {
+		l = append(l, obj{owner: schema, objname: name})
+	}
+	return l
+}
+
+// extractToggleScripts writes the scripts for disabling, and then
+// re-enabling, the constraints and the triggers of a schema to separate
+// files
+func extractToggleScripts(ctx context.Context, db *sql.DB, base, schema string) {
+
+	disableCons, enableCons, err := dex.ConstraintToggleScripts(ctx, db, schema)
+	if err != nil {
+		logError(err)
+		return
+	}
+	disableTrig, enableTrig, err := dex.TriggerToggleScripts(ctx, db, schema)
+	if err != nil {
+		logError(err)
+		return
+	}
+
+	scripts := []string{disableCons, enableCons, disableTrig, enableTrig}
+	for i, v := range schemaToggleObjs(schema) {
+		filename, err := objFilename(base, v, "", "sql")
+		if err != nil {
+			logError(err)
+			return
+		}
+
+		label := strings.Replace(strings.ToUpper(v.objname), "_", " ", -1)
+		b := sqlplusScript([]byte(scripts[i]+"\n\n"), label, "", schema)
+		err = emit(fmt.Sprintf("%q %s", schema, strings.ToLower(label)), filename, b)
+		logError(err)
+	}
+}
+
+// schemaRestartObj returns the pseudo-object for the file that the
+// sequence restarts for a schema are written to
+func schemaRestartObj(schema string) obj {
+	return obj{owner: schema, objname: "restart", dirname: "SEQUENCES"}
+}
+
+// extractSequenceRestarts writes the ALTER SEQUENCE ... RESTART
+// statements for the sequences in a schema to a separate file
+func extractSequenceRestarts(ctx context.Context, base, schema string, l []obj) {
+
+	var restarts []string
+	for _, v := range l {
+		if v.objtype != "SEQUENCE" {
+			continue
+		}
+		restart, err := ex.ExportSequenceRestart(ctx, v.owner, v.objname)
+		if err != nil {
+			logError(err)
+			continue
+		}
+		restarts = append(restarts, restart)
+	}
+	if len(restarts) == 0 {
+		return
+	}
+
+	filename, err := objFilename(base, schemaRestartObj(schema), "", "sql")
+	if err != nil {
+		logError(err)
+		return
+	}
+
+	b := sqlplusScript([]byte(strings.Join(restarts, "\n")+"\n\n"), "SEQUENCE RESTARTS", "", schema)
+	err = emit(fmt.Sprintf("%q sequence restarts", schema), filename, b)
+	logError(err)
+}
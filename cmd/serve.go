@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	dex "github.com/gsiems/oradex"
+)
+
+// serveObject is an object as listed by the server
+type serveObject struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// server serves the DDL of the database objects over HTTP
+type server struct {
+	db *sql.DB
+}
+
+// serveDDL runs the HTTP server on the listen address until interrupted.
+// The endpoints are:
+//
+//	GET /schemas                      the list of schemas
+//	GET /schemas/{schema}/objects     the list of objects in a schema
+//	GET /objects/{schema}/{name}/ddl  the DDL for an object
+//
+// Names containing a "/" are to be escaped as %2F.
+// Schemas are limited by the -s and -x flags, objects by the object
+// filtering flags, and the DDL is returned in the -format format.
+func serveDDL(ctx context.Context, db *sql.DB, addr string) {
+
+	s := &server{db: db}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/schemas", s.handleSchemas)
+	mux.HandleFunc("/schemas/", s.handleObjects)
+	mux.HandleFunc("/objects/", s.handleDDL)
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(_ net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		<-ctx.Done()
+		sctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		logError(srv.Shutdown(sctx))
+	}()
+
+	info("listening", "addr", addr)
+	err := srv.ListenAndServe()
+	if !errors.Is(err, http.ErrServerClosed) {
+		failOnErr(err)
+	}
+}
+
+// handleSchemas lists the schemas
+func (s *server) handleSchemas(w http.ResponseWriter, r *http.Request) {
+
+	if !allowGet(w, r) {
+		return
+	}
+
+	l, err := getSchemaList(r.Context(), schemas, xclude)
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+
+	writeJSON(w, coalesceList(l))
+}
+
+// handleObjects lists the objects in a schema
+func (s *server) handleObjects(w http.ResponseWriter, r *http.Request) {
+
+	if !allowGet(w, r) {
+		return
+	}
+
+	p, ok := pathParts(r.URL.EscapedPath(), "/schemas/")
+	if !ok || len(p) != 2 || p[1] != "objects" {
+		http.NotFound(w, r)
+		return
+	}
+	schema := p[0]
+
+	ok, err := s.servedSchema(r.Context(), schema)
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+	if !ok {
+		http.Error(w, fmt.Sprintf("schema %q not found", schema), http.StatusNotFound)
+		return
+	}
+
+	l, err := getObjList(r.Context(), s.db, schema)
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+
+	objs := []serveObject{}
+	for _, v := range filterObjList(l) {
+		objs = append(objs, serveObject{Name: v.objname, Type: v.objtype})
+	}
+
+	writeJSON(w, objs)
+}
+
+// handleDDL returns the DDL for an object
+func (s *server) handleDDL(w http.ResponseWriter, r *http.Request) {
+
+	if !allowGet(w, r) {
+		return
+	}
+
+	p, ok := pathParts(r.URL.EscapedPath(), "/objects/")
+	if !ok || len(p) != 3 || p[2] != "ddl" {
+		http.NotFound(w, r)
+		return
+	}
+	schema, name := p[0], p[1]
+
+	ok, err := s.servedSchema(r.Context(), schema)
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+
+	var objType string
+	if ok {
+		objType, err = dex.ObjType(r.Context(), s.db, schema, name)
+		if err != nil {
+			s.fail(w, err)
+			return
+		}
+	}
+	if objType == "" || !ex.Options().IncludeObject(name, objType) {
+		http.Error(w, fmt.Sprintf("no object found for %q.%q", schema, name), http.StatusNotFound)
+		return
+	}
+
+	b, res, err := exportObj(r.Context(), obj{owner: schema, objname: name, objtype: objType})
+	stats.observe(objType, res, err)
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+
+	switch format {
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+	case "sxml", "xml":
+		w.Header().Set("Content-Type", "application/xml")
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	n, _ := w.Write(b)
+	stats.written(n)
+}
+
+// servedSchema returns whether the schema is one of the schemas that are
+// served
+func (s *server) servedSchema(ctx context.Context, schema string) (bool, error) {
+	l, err := getSchemaList(ctx, schemas, xclude)
+	return contains(l, schema), err
+}
+
+// fail logs the error and returns the corresponding status to the
+// client. The error itself is not returned as database errors may reveal
+// more about the database than is intended.
+func (s *server) fail(w http.ResponseWriter, err error) {
+	logError(err)
+
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, dex.ErrObjectNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, dex.ErrInsufficientPrivilege):
+		status = http.StatusForbidden
+	case errors.Is(err, dex.ErrUnsupportedType):
+		status = http.StatusUnprocessableEntity
+	}
+	http.Error(w, http.StatusText(status), status)
+}
+
+// allowGet rejects requests that are not GET (or HEAD) requests
+func allowGet(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	w.Header().Set("Allow", "GET, HEAD")
+	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	return false
+}
+
+// pathParts returns the unescaped elements of the escaped path that
+// follow the prefix. Splitting before unescaping allows for names that
+// contain an (escaped) "/".
+func pathParts(path, prefix string) ([]string, bool) {
+
+	p := strings.Split(strings.Trim(strings.TrimPrefix(path, prefix), "/"), "/")
+	for i, v := range p {
+		u, err := url.PathUnescape(v)
+		if err != nil {
+			return p, false
+		}
+		p[i] = u
+	}
+	return p, true
+}
+
+// coalesceList returns an empty, rather than nil, list so that it is
+// encoded as an empty JSON array
+func coalesceList(l []string) []string {
+	if l == nil {
+		return []string{}
+	}
+	return l
+}
+
+// writeJSON writes the value as a JSON response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
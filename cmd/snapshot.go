@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	dex "github.com/gsiems/oradex"
+)
+
+// snapshotsFile is the name of the file, in the base directory, that
+// lists the snapshots, oldest first, for the retention policy
+const snapshotsFile = ".oradex.snapshots"
+
+// latestLink is the name of the symbolic link, in the base directory,
+// to the most recent snapshot
+const latestLink = "latest"
+
+// snapshotTag returns the name of the directory for the snapshot: the
+// tag, if specified, or the current (UTC) time
+func snapshotTag(tag string) (string, error) {
+
+	if tag == "" {
+		return time.Now().UTC().Format("20060102T150405Z"), nil
+	}
+	if !validSnapshotTag(tag) {
+		return "", fmt.Errorf("the snapshot tag %q is not a valid directory name", tag)
+	}
+	return tag, nil
+}
+
+// validSnapshotTag returns true if the tag is a name that needs no
+// escaping, and is therefore a single path component, other than that
+// of the latest link
+func validSnapshotTag(tag string) bool {
+	return tag != "" && tag == dex.SafeFileName(tag) && tag != latestLink && filepath.Base(tag) == tag
+}
+
+// finishSnapshot points the latest link in the base directory at the
+// snapshot and, if keep is set, removes the oldest of the snapshots
+// such that only keep snapshots remain. Only directories that were
+// created as snapshots are ever removed, and entries in the snapshots
+// file that are not valid tags (see validSnapshotTag) are dropped
+// rather than removed.
+func finishSnapshot(base, tag string, keep int) error {
+
+	link := filepath.Join(base, latestLink)
+	if fi, err := os.Lstat(link); err == nil {
+		if fi.Mode()&os.ModeSymlink == 0 {
+			return fmt.Errorf("unable to update %q as it is not a symbolic link", link)
+		}
+		if err = os.Remove(link); err != nil {
+			return err
+		}
+	}
+	err := os.Symlink(tag, link)
+	if err != nil {
+		warn("unable to link the latest snapshot", "link", link, "error", err)
+	}
+
+	filename := filepath.Join(base, snapshotsFile)
+
+	var l []string
+	f, err := os.Open(filename)
+	if err == nil {
+		s := bufio.NewScanner(f)
+		for s.Scan() {
+			t := strings.TrimSpace(s.Text())
+			switch {
+			case t == "" || t == tag:
+			case !validSnapshotTag(t):
+				warn("ignoring invalid snapshot", "snapshot", t, "file", filename)
+			default:
+				l = append(l, t)
+			}
+		}
+		err = s.Err()
+		f.Close()
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	l = append(l, tag)
+
+	if keep > 0 && len(l) > keep {
+		for _, t := range l[:len(l)-keep] {
+			dir := filepath.Join(base, t)
+			if filepath.Dir(dir) != filepath.Clean(base) {
+				return fmt.Errorf("the snapshot %q is not under %q", t, base)
+			}
+			info("removing snapshot", "snapshot", t)
+			err = os.RemoveAll(dir)
+			if err != nil {
+				return err
+			}
+		}
+		l = l[len(l)-keep:]
+	}
+
+	_, err = dex.WriteFile(filename, []byte(strings.Join(l, "\n")+"\n"), 0600)
+	return err
+}
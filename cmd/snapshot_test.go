@@ -0,0 +1,86 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotTag(t *testing.T) {
+
+	tests := []struct {
+		tag     string
+		wantErr bool
+	}{
+		{"release-1.2", false},
+		{"20261018T020000Z", false},
+		{"latest", true},
+		{".", true},
+		{"..", true},
+		{"../x", true},
+		{"a/b", true},
+		{"a b", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, err := snapshotTag(tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.tag {
+				t.Errorf("got %q, want %q", got, tt.tag)
+			}
+		})
+	}
+
+	if got, err := snapshotTag(""); err != nil || !validSnapshotTag(got) {
+		t.Errorf("got %q (%v) for the default tag", got, err)
+	}
+}
+
+func TestFinishSnapshot(t *testing.T) {
+
+	base := t.TempDir()
+	outside := t.TempDir()
+
+	for _, d := range []string{"s1", "s2", "s3"} {
+		if err := os.Mkdir(filepath.Join(base, d), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Invalid entries are dropped rather than removed
+	filename := filepath.Join(base, snapshotsFile)
+	entries := []string{"s1", "..", "../" + filepath.Base(outside), outside, "s2", ""}
+	if err := ioutil.WriteFile(filename, []byte(strings.Join(entries, "\n")), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := finishSnapshot(base, "s3", 2); err != nil {
+		t.Fatal(err)
+	}
+
+	for d, want := range map[string]bool{"s1": false, "s2": true, "s3": true} {
+		if _, err := os.Stat(filepath.Join(base, d)); (err == nil) != want {
+			t.Errorf("%s exists: %v, want %v", d, err == nil, want)
+		}
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("removed %s", outside)
+	}
+
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), "s2\ns3\n"; got != want {
+		t.Errorf("got snapshots %q, want %q", got, want)
+	}
+
+	if got, err := os.Readlink(filepath.Join(base, latestLink)); err != nil || got != "s3" {
+		t.Errorf("latest links to %q (%v), want s3", got, err)
+	}
+}
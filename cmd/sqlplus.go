@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sqlplusHeader is the SQL*Plus directives that start each script when
+// using -sqlplus: substitution variables are disabled so that any
+// ampersands in the DDL are left as is, and the script stops at the
+// first error
+const sqlplusHeader = "SET DEFINE OFF\nWHENEVER SQLERROR EXIT FAILURE\n\n"
+
+// plsqlTypes are the object types that may be created with compilation
+// errors, which SQL*Plus only reports when asked to
+var plsqlTypes = map[string]bool{
+	"FUNCTION":     true,
+	"JAVA SOURCE":  true,
+	"PACKAGE":      true,
+	"PACKAGE BODY": true,
+	"PROCEDURE":    true,
+	"TRIGGER":      true,
+	"TYPE":         true,
+	"TYPE BODY":    true,
+}
+
+// sqlplusScript wraps the DDL for an object, when using -sqlplus, with
+// the SQL*Plus directives that make the file directly runnable as part
+// of a deployment (see sqlplusHeader and sqlplusObject)
+func sqlplusScript(b []byte, objType, schema, name string) []byte {
+
+	if !sqlplus || format != "sql" {
+		return b
+	}
+
+	return []byte(sqlplusHeader + sqlplusObject(string(b), objType, schema, name))
+}
+
+// sqlplusObject precedes the DDL for an object with a PROMPT banner and,
+// for PL/SQL, follows it with SHOW ERRORS
+func sqlplusObject(objDDL, objType, schema, name string) string {
+
+	var l []string
+
+	banner := strings.ToLower(objType)
+	switch {
+	case schema != "":
+		banner += fmt.Sprintf(" \"%s\".\"%s\"", schema, name)
+	case name != "":
+		banner += fmt.Sprintf(" \"%s\"", name)
+	}
+
+	l = append(l, "PROMPT "+strings.TrimSpace(banner))
+	l = append(l, strings.TrimRight(objDDL, "\n\r\t ")+"\n")
+	if plsqlTypes[objType] {
+		l = append(l, "SHOW ERRORS\n")
+	}
+
+	return strings.Join(l, "\n") + "\n"
+}
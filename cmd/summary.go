@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	dex "github.com/gsiems/oradex"
+)
+
+// runSummary summarizes the schema extractions of a run
+type runSummary struct {
+	mu       sync.Mutex
+	Started  time.Time       `json:"started"`
+	Elapsed  float64         `json:"elapsed_seconds"`
+	Objects  int             `json:"objects"`
+	Skipped  int             `json:"skipped"`
+	Failed   int             `json:"failed"`
+	TimedOut int             `json:"timed_out"`
+	Schemas  []schemaSummary `json:"schemas"`
+}
+
+// schemaSummary summarizes the extraction of a schema
+type schemaSummary struct {
+	Database      string           `json:"database,omitempty"`
+	Schema        string           `json:"schema"`
+	Objects       int              `json:"objects"`
+	ObjectsByType map[string]int   `json:"objects_by_type"`
+	Skipped       int              `json:"skipped"`
+	Failed        []failureSummary `json:"failed"`
+	Elapsed       float64          `json:"elapsed_seconds"`
+}
+
+// failureSummary is an object that could not be extracted, or that was
+// extracted with errors
+type failureSummary struct {
+	Schema   string `json:"schema"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Error    string `json:"error"`
+	Partial  bool   `json:"partial,omitempty"`
+	TimedOut bool   `json:"timed_out,omitempty"`
+}
+
+// summary is the summary of the current run
+var summary = &runSummary{Started: time.Now()}
+
+// add adds the result of extracting a schema to the summary
+func (s *runSummary) add(database string, res dex.ExtractionResult) {
+
+	ss := schemaSummary{
+		Database:      database,
+		Schema:        res.Schema,
+		Objects:       res.ObjectCount,
+		ObjectsByType: res.ObjectTypes,
+		Skipped:       res.Skipped,
+		Failed:        []failureSummary{},
+		Elapsed:       res.Elapsed.Seconds(),
+	}
+	if ss.ObjectsByType == nil {
+		ss.ObjectsByType = map[string]int{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range res.FailedObjects {
+		f := failureSummary{
+			Schema:   e.Schema,
+			Name:     e.Name,
+			Type:     e.ObjType,
+			Error:    strings.ReplaceAll(strings.TrimSpace(e.Err.Error()), "\n", "; "),
+			Partial:  e.Partial,
+			TimedOut: errors.Is(e, dex.ErrObjectTimeout),
+		}
+		ss.Failed = append(ss.Failed, f)
+		if f.TimedOut {
+			s.TimedOut++
+		}
+	}
+
+	s.Objects += ss.Objects
+	s.Skipped += ss.Skipped
+	s.Failed += len(ss.Failed)
+	s.Schemas = append(s.Schemas, ss)
+}
+
+// empty returns true if no schemas were extracted
+func (s *runSummary) empty() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.Schemas) == 0
+}
+
+// finish sets the elapsed time of the run
+func (s *runSummary) finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Elapsed = time.Since(s.Started).Seconds()
+}
+
+// writeText writes the summary as text: the object counts by type, the
+// failed objects with the reasons, and the elapsed time
+func (s *runSummary) writeText(w io.Writer) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byType := make(map[string]int)
+	var failed []failureSummary
+	for _, ss := range s.Schemas {
+		for k, n := range ss.ObjectsByType {
+			byType[k] += n
+		}
+		failed = append(failed, ss.Failed...)
+	}
+
+	var types []string
+	for k := range byType {
+		types = append(types, k)
+	}
+	sort.Strings(types)
+
+	var l []string
+	l = append(l, fmt.Sprintf("Extracted %d objects from %d schemas in %s: %d skipped, %d failed (%d timed out)",
+		s.Objects, len(s.Schemas), (time.Duration(s.Elapsed*float64(time.Second))).Round(time.Millisecond), s.Skipped, s.Failed, s.TimedOut))
+	for _, k := range types {
+		l = append(l, fmt.Sprintf("    %-24s %6d", k, byType[k]))
+	}
+	if len(failed) > 0 {
+		l = append(l, "Failed objects:")
+		for _, f := range failed {
+			if f.Partial {
+				l = append(l, fmt.Sprintf("    %s %q.%q (partially): %s", f.Type, f.Schema, f.Name, f.Error))
+				continue
+			}
+			l = append(l, fmt.Sprintf("    %s %q.%q: %s", f.Type, f.Schema, f.Name, f.Error))
+		}
+	}
+
+	_, err := io.WriteString(w, strings.Join(l, "\n")+"\n")
+	return err
+}
+
+// writeJSON writes the summary, as JSON, to the file
+func (s *runSummary) writeJSON(filename string) error {
+
+	s.mu.Lock()
+	b, err := json.MarshalIndent(s, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	_, err = dex.WriteFile(filename, append(b, '\n'), 0600)
+	return err
+}
@@ -0,0 +1,687 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// usage prints the usage of oradex
+func usage() {
+	fmt.Fprint(os.Stderr, `usage: oradex [command] [flags] [args]
+
+Commands
+
+  extract Extract the DDL for the schemas specified by the -s and -x
+          flags to the base directory. This is the default command.
+
+  object [schema.]object_name
+          Extract the DDL for a single object, as the -o flag.
+
+  list    List the objects that would be extracted (see List objects).
+
+  compare SCHEMA[@DBLINK]
+          Write the DDL needed to convert the schema to match the -s
+          schema (see Compare schemas).
+
+  diff [SCHEMA[@DBLINK]]
+          With a schema, as the compare command. Without one, print the
+          differences between the database and the files under the
+          base directory, as the -diff flag.
+
+  preflight
+          Check the privileges needed for extracting (see Preflight
+          check).
+
+  serve   Serve the DDL over HTTP (see Serve flags).
+
+  Each command accepts only the flags that apply to it, the database
+  connection flags being shared by all of the commands, and flags may
+  follow the arguments. Running oradex without a command, as
+  "oradex [flags]", remains supported.
+
+Database connection flags
+
+  -d      The database to connect to. Overrides the ORACLE_SID
+          environment variable. Multiple databases may be specified as a
+          comma separated list, in which case each database is extracted,
+          with the same settings, to a subdirectory of the base directory
+          named for the database.
+
+  -pdbs   The comma separated list of pluggable databases, or "all" for
+          all of the open pluggable databases, to extract when connected
+          to the root container of a CDB. Each PDB is extracted to a
+          subdirectory of the base directory named for the PDB.
+
+  -dsn    The full connect string to connect with rather than resolving
+          the database through TNS. Either an EZConnect string (i.e.
+          dbhost:1521/orclpdb1), optionally with Easy Connect Plus
+          parameters (i.e. dbhost:1521/orclpdb1?connect_timeout=5), a
+          connect descriptor, or a godror connect string that may
+          include the credentials and other parameters (i.e. user=scott
+          password=tiger connectString=dbhost:1521/orclpdb1). When the
+          credentials are not included, the password for the user is
+          looked up in the orapass file for the host, port, and service
+          of the connect string. Defaults to the ORADEX_DSN environment
+          variable unless -d is specified. Can not be used with -d.
+          Note that a password in the -dsn flag is visible to other
+          users in the process list (i.e. ps). Use ORADEX_DSN, or a DSN
+          without the credentials along with -password-stdin or the
+          ORACLE_PASSWORD environment variable, instead.
+
+  -external Connect using external authentication (i.e. /@alias) with
+          the credentials from an Oracle Wallet, or of the OS user,
+          rather than with a password from the orapass file. The alias
+          is the -dsn, if specified, or else the -d database.
+
+  -proxy  The schema to connect to using proxy authentication, as the
+          -u user (or the wallet user with -external) on behalf of the
+          schema (i.e. user[schema]). The password is that of the -u
+          user. The user requires the GRANT CONNECT THROUGH privilege.
+
+  -as     The administrative privilege to connect with: sysdba or
+          sysoper. Connections with either privilege are not pooled.
+
+  -tns-admin The directory of the tnsnames.ora and sqlnet.ora files (and
+          of the wallet, if the sqlnet.ora refers to it). Sets the
+          TNS_ADMIN environment variable for the Oracle client.
+
+  -wallet The directory of the Oracle Wallet. Added to EZConnect DSNs as
+          the wallet_location parameter, which requires a client that
+          supports Easy Connect Plus. For TNS aliases the wallet is
+          specified by the sqlnet.ora file (see -tns-admin).
+
+  -h      The hostname that the database is on. Overrides the
+          ORACLE_HOST environment variable. Defaults to localhost.
+
+  -f      The orapass file to search for first.
+
+  -p      The port that the database is listening on. Overrides the
+          ORACLE_PORT environment variable. Defaults to 1521.
+
+  -u      The username to obtain a password for. Overrides the
+          ORACLE_USER environment variable. Defaults to the OS user.
+
+  -password-stdin Read the password for the user from the first line of
+          stdin rather than from the orapass file.
+
+  The password may instead be set with the ORACLE_PASSWORD environment
+  variable. When neither is set and no orapass entry is found then the
+  password is prompted for, if stdin is a terminal.
+
+Common extract flags
+
+  -alter  Include constraints as ALTER commands. Defaults to including
+          constraints as part of the create command.
+
+  -needed Include grants needed by the object exported. Since Oracle
+          does not stort privilege or dependency information at a fine
+          enough level of detail this is a best guess and may contain
+          additional privileges not actually needed for the object.
+
+  -grants Include grants on the object.
+
+  -grants-file Write the grants on the objects to separate files rather
+          than including them with the object DDL. Either "object" for a
+          <name>.grants.sql file for each object or "schema" for a single
+          grants.sql file for each schema (written to the GRANTS
+          directory). Requires -grants. Ignored for single objects
+          (-o) as these are written to stdout. Any grants file for an
+          object, or schema, that no longer has grants is removed.
+
+  -current-value Restart sequences at their current value.
+
+  -recompile Write the script for recompiling the invalid objects in
+          each schema to a separate recompile.sql file in the RECOMPILE
+          directory. Either "alter" for ALTER ... COMPILE statements in
+          dependency order or "utl_recomp" for recompiling the schema
+          using UTL_RECOMP. Invalid objects are always reported.
+
+  -install Write the SQL*Plus script for installing the extracted
+          schemas to the install.sql file in the base directory. The
+          objects are created first, ordered such that each object
+          follows the objects that it depends on, or that its foreign
+          keys reference, in whichever of the schemas. With -grants-file
+          schema the objects are created a schema at a time, each schema
+          following the schemas that it depends on, and the grants of
+          each schema are applied once its objects are created. The
+          received grants, the package and type bodies, and the separate
+          indexes and constraints (see -constraints) are then applied
+          and the schemas recompiled. Using -constraints separate avoids
+          depending on the order of the tables for circular foreign
+          keys. Requires the "sql" format.
+
+  -uninstall Write the script for dropping the extracted objects of
+          each schema to a separate uninstall.sql file in the schema
+          directory. Package and type bodies are dropped first, then
+          each object is dropped before the objects that it depends on
+          and tables before the tables that their foreign keys
+          reference. See -cascade.
+
+  -toggle Write the scripts for disabling, and then re-enabling, the
+          enabled foreign key and check constraints and the enabled
+          triggers of each schema, as for bulk loading data, to the
+          constraints_disable.sql, constraints_enable.sql,
+          triggers_disable.sql, and triggers_enable.sql files in the
+          schema directory.
+
+  -received-grants Also write the grants that each schema has received
+          on the objects of other schemas, as needed for rebuilding the
+          schema, to a separate received.sql file in the GRANTS
+          directory.
+
+  -synonyms Include the synonyms on each extracted object that are
+          owned by other schemas, or by PUBLIC, with the DDL for the
+          object and extract the private synonyms of each schema to the
+          SYNONYM directory.
+
+  -nopublic Omit the PUBLIC synonyms when including the synonyms on
+          each extracted object.
+
+  -sequences How the START WITH value of sequences is extracted. Either
+          "live" for the next value of the sequence, as returned by
+          DBMS_METADATA, "reset" for the MINVALUE (or MAXVALUE for
+          descending sequences) of the sequence, or "restart" to reset
+          the sequences and to also write ALTER SEQUENCE ... RESTART
+          statements for restarting all of the sequences in a schema at
+          their current values to a separate restart.sql file in the
+          SEQUENCES directory. Defaults to "live".
+
+  -force  Include the FORCE keywork in CREATE DDL commands
+
+  -drop   Include DROP statements for the extracted objects. Either
+          "prepend" to prepend the DROP statement to the DDL or "file"
+          to write the DROP statement to a separate <name>.drop.sql file.
+
+  -cascade Drop tables with CASCADE CONSTRAINTS and types with FORCE.
+
+  -format The output format. Either "sql" for SQL scripts or "json" for
+          JSON documents containing the owner, name, type, DDL, grants,
+          and last DDL time of each object. Defaults to "sql". Packages
+          are not split when the format is "json".
+
+  -nodba  Use the all_* data dictionary views rather than the dba_*
+          views. Only those objects that are visible to the user are
+          extracted and the database level extracts (roles, users, etc.)
+          are limited to what has been granted to the user. This is
+          done automatically if the user cannot query the dba_* views.
+
+  -compat Compatibility mode for restricted privilege environments such
+          as Amazon RDS. Each of the dba_* views used is checked at
+          startup and only those views that the user cannot query are
+          replaced by their all_* equivalents. Extracts that have no
+          equivalent (i.e. lockdown profiles) are skipped.
+
+  -metadata-format The metadata format. Either "ddl" for DDL, "sxml"
+          for the simplified, canonical, XML representation that is
+          intended for comparison, or "xml" for the full XML metadata
+          (see DBMS_METADATA.GET_SXML and GET_XML). Files are written
+          with a .sxml or .xml extension respectively. Defaults to "ddl".
+
+  -constraint-state Set all table constraints to the specified state,
+          i.e. "ENABLE NOVALIDATE" or "DISABLE", rather than the state
+          that the constraints have in the database. Useful for data
+          migrations where the data may not satisfy the constraints
+          until it has been loaded. The RELY state of each constraint is
+          preserved unless RELY or NORELY is specified.
+
+  -noschema Omit the schema from the names of the objects in the
+          schema, to include the grants, comments, and triggers, and
+          start each file with ALTER SESSION SET CURRENT_SCHEMA so that
+          the DDL may be deployed to whichever schema is current.
+
+  -normalize Remove the volatile parts of the DDL, such as the START
+          WITH value of sequences and the next refresh time of
+          materialized views, and normalize the white-space so that only
+          actual changes to the objects show up in version control. The
+          white-space in literals and in PL/SQL and Java source is left
+          as is. Can not be used with -current-value or with -sequences
+          reset or restart.
+
+  -pretty Reformat the DDL to be easier to read and to compare: the
+          statements are not indented, trailing white-space is removed,
+          the columns and constraints of tables are listed one per line,
+          and the clauses following the column list are indented. The
+          source of packages, procedures, functions, types, and triggers
+          is left as is.
+
+  -keyword-case The case, either "upper" or "lower", to set the SQL
+          keywords to when reformatting the DDL. Requires -pretty. The
+          case of the keywords is left as is if not specified.
+
+  -remap-schema The comma separated list of OLD:NEW schema mappings
+          (i.e. PROD_APP:DEV_APP) to apply to the extracted DDL so that
+          it may be deployed to a different schema. Files are still
+          written to the directory for the original schema.
+
+  -remap-tablespace The comma separated list of OLD:NEW tablespace
+          mappings to apply to the TABLESPACE clauses of the extracted
+          DDL. Use * for OLD to map all tablespaces and a SQL*Plus
+          substitution variable for NEW to parameterize the tablespace
+          (i.e. *:&&DATA_TS). Only useful with -storage.
+
+  -storage Include storage parameters in CREATE commands.
+
+  -target-version The Oracle version (11.2, 12.1, 12.2, 19, 21, 23) to
+          generate DDL for. Syntax that is not supported by the target
+          version is removed or rewritten where possible and warnings
+          are issued for anything that needs to be manually rewritten.
+
+Extract database/schema(s) DDL flags
+
+  -b      The base directory to write the extracted DDL to. Overrides
+          the BASE_DIR environment variable. Defaults to the current
+          directory. A manifest.json file, listing the file, SHA-256
+          checksum, last DDL time, and extraction time for each of the
+          extracted objects, is written to the base directory.
+
+  -s      The comma separated list of schemas to extract.
+
+  -types  The comma separated list of object types (i.e. TABLE,VIEW,
+          PACKAGE) to extract. Defaults to all supported types.
+
+  -xtypes The comma separated list of object types to not extract.
+
+  -include The comma separated list of patterns for the names of the
+          objects to extract. Defaults to all objects.
+
+  -exclude The comma separated list of patterns for the names of the
+          objects to not extract (i.e. TMP_%,%_BKP).
+
+  -regex  The -include and -exclude patterns are regular expressions
+          rather than SQL LIKE patterns.
+
+  -template The text/template for the path, relative to the base
+          directory, of the extracted files. Available fields are
+          {{.Schema}} (or {{.Owner}}), {{.Name}}, {{.Type}}, and {{.Ext}}
+          and available functions are lower, upper, and replace (i.e.
+          {{.Name | replace "$" "_"}}), as in
+          "{{.Owner | lower}}/{{.Type | lower}}/{{.Name | lower}}.{{.Ext}}".
+          Defaults to "{{.Schema}}/{{.Type}}/{{.Name}}.{{.Ext}}". The
+          template must use {{.Ext}} when files with several extensions
+          may be written (with -data, -lookup-data, -type-ext, or
+          -split-package, or with a -format other than "sql").
+          Characters in the schema and object names that are not safe
+          in file names (slashes, spaces, quotes, non-ASCII characters,
+          and the like) are percent encoded, as are Windows reserved
+          names, and overly long names are shortened. Names with lower
+          case letters have a hash of the name appended so that names
+          that differ only in case (i.e. "Foo" and FOO) do not collide
+          on case-insensitive file systems or with -lower. The escaped
+          names are mapped back to the schema and object names in the
+          .oradex.names file in the base directory.
+
+  -lower  Write the directory and file names in lowercase.
+
+  -type-ext Use an extension for the type of object in place of .sql
+          for the extracted files: .tab for tables, .vw for views, .mv
+          for materialized views, .seq for sequences, .trg for
+          triggers, .fnc for functions, .prc for procedures, .pkg for
+          packages, .typ for types, and .syn for synonyms. Split
+          packages and types use the -package-files naming.
+
+  -eol    The line endings of the extracted files. Either "lf" or
+          "crlf". Defaults to the line endings of the OS that oradex
+          runs on, so specify this for the files to be the same
+          regardless of where oradex runs.
+
+  -encoding The character encoding of the extracted files. Either
+          "utf-8", "utf-16le", "utf-16be", or "latin1". Defaults to
+          "utf-8". Extracting objects that can not be represented in
+          Latin-1 fails when the encoding is "latin1".
+
+  -bom    Start the extracted files with a byte order mark. Not
+          supported for Latin-1.
+
+  -x      The comma separated list of schemas to exclude.
+          Ignored if the -s flag is supplied.
+
+  -X      The comma separated list of schemas to add to the list of
+          excluded system schemas.
+
+  -exclude-sys Exclude the Oracle supplied system schemas. Defaults to
+          true.
+
+  -no-exclude-sys Do not exclude the Oracle supplied system schemas.
+
+  -size-report Print the storage allocated to each extracted schema
+          by segment type.
+
+  -since  Only extract those objects that have had DDL applied to them
+          since the specified time (YYYY-MM-DDTHH:MM:SS). Files are only
+          rewritten if the extracted DDL differs from what is on disk.
+
+  -single-file Write the DDL for all of the objects in each schema, in
+          dependency order, to a single deployable <schema>.sql file in
+          the base directory rather than to a file per object. With
+          -drop file the DROP statements, in reverse dependency order,
+          are written to <schema>.drop.sql and with -grants-file the
+          grants are written to <schema>.grants.sql.
+
+  -prompt Precede each object in a single file, or in the output of the
+          -deps and -dependents flags, with a SQL*Plus PROMPT.
+
+  -sqlplus Wrap the extracted files with SQL*Plus directives so that
+          they may be run as is by deployment tooling: each file starts
+          with SET DEFINE OFF and WHENEVER SQLERROR EXIT FAILURE, each
+          object is preceded by a PROMPT banner, and PL/SQL objects are
+          followed by SHOW ERRORS. Requires the "sql" format.
+
+  -git-commit Stage and commit the changes to the files under the base
+          directory, which must be in a git working tree, after the
+          extraction (and after each poll when using -watch).
+
+  -git-message The template for the git commit message. Available
+          fields are {{.DbName}}, {{.Schemas}}, and {{.Time}}. Defaults
+          to "Extract {{.Schemas}} from {{.DbName}} at {{.Time}}".
+
+  -watch  Stay connected and, at the specified interval (i.e. 15m),
+          extract the objects that have changed since the previous
+          extraction. This continues until interrupted and allows for
+          continuously synchronizing the base directory, and any version
+          control thereof, with the database.
+
+  -archive Write the extracted files to the specified archive rather
+          than to the base directory. The archive type is determined by
+          the file extension (.zip, .tar, .tar.gz, or .tgz) and the
+          files in the archive are named relative to the base directory.
+          Can not be used with the -check, -prune, or -stale flags.
+
+  -split-package Write package specifications and bodies to separate
+          files.
+
+  -split-type Write object type specifications and bodies to separate
+          files so that the specifications may be deployed before any
+          dependent tables and the bodies afterwards.
+
+  -package-files The file naming to use for split packages and types.
+          Either "pks" for <name>.pks and <name>.pkb (<name>.tps and
+          <name>.tpb for types) files or "sql" for <name>_spec.sql and
+          <name>_body.sql files. Defaults to "pks". Implies
+          -split-package.
+
+  -roles  Also extract the database roles, the system and object
+          privileges granted to them, and the grants of the roles. Roles
+          are written to the ROLES directory under the base directory.
+
+  -schema-roles As -roles but only extract the roles that are granted,
+          either directly or by way of other roles, to the extracted
+          schemas.
+
+  -lockdown-profiles Also extract the PDB lockdown profiles. Lockdown
+          profiles are written to the LOCKDOWN_PROFILES directory under
+          the base directory.
+
+  -triggers How triggers are extracted. Either "inline" to include
+          triggers with the table or view that they are on or "separate"
+          to extract triggers to their own TRIGGER directory. Separate
+          extraction includes the triggers owned by the schema that are
+          on tables in other schemas. Defaults to "inline".
+
+  -constraints How the foreign key and check constraints, and the
+          indexes, of tables are extracted. Either "inline" to include
+          them with the table or "separate" to extract the constraints
+          to their own CONSTRAINT directory and the indexes (to include
+          those of materialized views) to their own INDEX directory so
+          that they may be created after loading the data. Separate
+          extraction implies -alter. Defaults to "inline".
+
+  -users  Also generate the CREATE USER DDL, to include the default
+          tablespaces, quotas, profile, granted roles and system
+          privileges, for each extracted schema so that an empty
+          database may be provisioned before running the object DDL.
+          Users are written to the USERS directory under the base
+          directory. Passwords are not extracted; users identified by
+          password use the &&<user>_PASSWORD substitution variable.
+
+  -sys-privs Also extract the system privileges (CREATE VIEW, CREATE
+          JOB, etc.) granted directly to each extracted schema. System
+          privileges are written to the SYSTEM_PRIVILEGES directory
+          under the base directory. These are also included with -users.
+
+  -tablespaces Also extract the tablespaces that are referenced by the
+          extracted schemas, either by the segments in the schema, the
+          quotas for the schema, or as the default tablespace for the
+          schema. Tablespaces are written to the TABLESPACES directory
+          under the base directory.
+
+  -directories Also extract the directories, and the grants on them,
+          that are referenced by the extracted schemas either via
+          external tables or via grants to the schema. Directories are
+          written to the DIRECTORIES directory under the base directory.
+
+  -scheduler Also extract the scheduler job classes and windows. Job
+          classes are written to the JOB_CLASSES directory and windows
+          to the WINDOWS directory under the base directory. Scheduler
+          jobs, programs, schedules, and chains are always extracted
+          with the schema that owns them.
+
+  -strict Exit with a non-zero status if any object fails to extract.
+          The exit status is 0 for success, 1 if differences were found
+          (-check, compare, and preflight), 2 if the extraction failed, 3
+          if unable to connect to the database, and 4 if some objects
+          failed to extract in strict mode. Without -strict the objects
+          that fail to extract are reported but do not fail the run.
+          Objects for which only some of the supporting DDL (grants,
+          comments, etc.) failed to extract are reported as partially
+          failed and do not fail the run even with -strict.
+
+  -summary The file to write the summary of the extraction to as JSON,
+          i.e. summary.json. The summary contains the number of objects
+          extracted by type, skipped, and failed (along with the reasons)
+          for each schema and is also printed to stderr at the end of
+          the extraction.
+
+  -timeout The maximum time to spend extracting each object, i.e. 2m.
+          Objects that take longer are skipped and listed at the end of
+          the extraction. Defaults to no timeout.
+
+  -snapshot Write the extraction to a directory, named for the -tag or
+          for the current UTC time (i.e. 20240131T120000Z), under the
+          base directory so that point-in-time snapshots may be kept
+          side by side. The "latest" symbolic link in the base directory
+          is updated to point to the snapshot.
+
+  -tag    The name of the snapshot directory for -snapshot.
+
+  -keep   The number of snapshots to keep for -snapshot. The oldest
+          snapshots beyond the number are removed. Defaults to keeping
+          all of the snapshots.
+
+  -delta  The manifest.json of a previous extraction to extract the
+          changes since. Only the objects that are new, or that have a
+          different last DDL time, are extracted and a report of the
+          changes is written to stdout: a tab separated line of the
+          status (added, changed, unchanged, or removed), schema, object
+          type, object name, and file for each file. Objects whose last
+          DDL time changed but whose DDL checksum did not are unchanged.
+
+  -dry-run List the objects that would be extracted, after applying
+          the schema and object filters, along with the files that they
+          would be written to rather than extracting them. The owner,
+          type, name, and file path(s) of each object are written to
+          stdout as tab separated values.
+
+  -resume Resume an interrupted extraction, skipping the objects that
+          were extracted before the interruption. The progress of each
+          extraction to a directory is recorded in a .oradex.checkpoint
+          file in the base directory, which is removed once the
+          extraction completes. The checkpoint also records the options
+          that the extraction was started with and resuming with
+          different options is refused.
+
+  -retries The number of times to retry extracting an object after a
+          transient error such as a library cache lock (ORA-04021), a
+          cancelled call (ORA-01013), or a lost connection. Defaults to 0.
+
+  -retry-backoff The time to wait before the first retry. The wait is
+          doubled for each subsequent retry. Defaults to 2s.
+
+  -prune  Remove the files for objects that no longer exist in the
+          database.
+
+  -stale  List, without removing, the files for objects that no longer
+          exist in the database. Stale files are always listed as
+          removed, and are counted as differences, in check mode.
+
+Extract object DDL flags
+
+  -o      The schema.object_name of the object to extract.
+          If specified then the -b, -s, and -x flags are ignored.
+
+  -deps   Also extract all of the objects that the -o object depends
+          on, directly or otherwise, in dependency order so that the
+          result is a self-contained script.
+
+  -dependents Also extract all of the objects that depend on the -o
+          object, directly or otherwise, in dependency order. This
+          shows, and allows for regenerating, the objects that are
+          affected by a change to the object.
+
+  -check  Compare the DDL in the database with the files under the
+          base directory rather than writing the files. Objects that
+          have been added to, removed from, or changed in the database
+          are listed and the exit status is non-zero if there are any
+          differences. This allows for scheduled drift detection between
+          the database and the version controlled copy of the DDL.
+
+  -diff   As -check but also print the differences in unified diff
+          format.
+
+Compare schemas
+
+  The compare command compares the schema specified by the -s flag with
+  the SCHEMA[@DBLINK] argument. The DDL needed to convert the compare
+  schema to match the -s schema is written to stdout: DBMS_METADATA_DIFF
+  ALTER statements for tables, views, sequences and the like, CREATE OR
+  REPLACE for PL/SQL, synonyms and other objects that differ, and CREATE
+  and DROP statements for the objects that are only in one of the
+  schemas. Use a database link to compare with a schema in another
+  database. The exit status is non-zero if there are any differences.
+
+  -data   The comma separated list of [schema.]table names of the tables
+          to also export the data for. The data for each table is
+          written as CSV, with a header row, to the DATA directory along
+          with a SQL*Loader control file for loading the data. The
+          control file declares the character set of the -encoding (and
+          is itself written as UTF-8 for the UTF-16 encodings). Tables
+          that are not qualified by a schema are exported from each of
+          the extracted schemas that they exist in. Intended for smaller
+          reference tables.
+
+  -lookup-data Also export the data, as for -data, of the lookup tables
+          of each schema: the tables that are referenced by the foreign
+          keys of other tables and that have no more than 1000 rows, as
+          of the last gathering of the table statistics.
+
+  -graph  Write the dependency graph, including the foreign keys
+          between tables, of the schemas specified by the -s and -x
+          flags to stdout rather than extracting the DDL. Either "dot"
+          for Graphviz or "mermaid" for a Mermaid flowchart.
+
+Preflight check
+
+  preflight
+          Check that the user can query the data dictionary views, and
+          use DBMS_METADATA, as needed for extracting the DDL rather
+          than extracting it. The grants for any missing privileges are
+          listed and the exit status is non-zero if any are missing.
+
+List objects
+
+  list    List the objects that would be extracted, as limited by the
+          schema and object filtering flags, with the status, created
+          time, last DDL time, and number of lines of source of each
+          rather than extracting the DDL. The list is written to stdout
+          as CSV or, with -format json, as JSON.
+
+Serve flags
+
+  serve   Serve the DDL over HTTP rather than extracting it. The
+          endpoints are GET /schemas for the list of schemas (as limited
+          by the -s and -x flags), GET /schemas/{schema}/objects for the
+          list of objects in a schema, and GET /objects/{schema}/{name}/ddl
+          for the DDL of an object in the -format format. Names that
+          contain a "/" are to be escaped as %2F. The extract flags that
+          apply to single objects also apply to the served DDL. Errors
+          are logged rather than returned to the client.
+
+  -listen The address for the server to listen on. Defaults to
+          localhost:8080.
+
+  -metrics The address to serve metrics on when running in watch or
+          serve mode. Prometheus metrics (objects extracted, errors and
+          extraction time by object type, and bytes written) are served
+          at /metrics.
+
+  -pprof  The address to serve the pprof profiles, at /debug/pprof/, on
+          when running in watch or serve mode. The profiles are not
+          authenticated so an address without a host (i.e. :6060) is
+          bound to localhost.
+
+Other flags
+
+  -c      The TOML configuration file to read settings from. Defaults to
+          .oradex.toml, if it exists. Settings are named for the flags
+          above (with underscores in place of dashes) and may also use
+          the aliases base, database, exclude_names, exclude_schemas,
+          host, object, orapass_file, port, schemas,
+          sys_exclude, and user. The former "exclude" alias for -x is
+          rejected, use exclude_schemas instead.
+          Flags specified on the command line take precedence over the
+          configuration file. Tables may be used to organize settings
+          and lists may be used for comma separated values, i.e.:
+
+              [connection]
+              database = "orcl"
+              user = "extract"
+
+              [extract]
+              base = "/srv/ddl"
+              schemas = [ "APP", "APP_API" ]
+              grants = true
+
+          The DDL of each object may be rewritten, i.e. to strip COLLATE
+          clauses or to replace environment specific names, by rules in
+          [[rewrite]] tables. The rules are applied in order. Each has a
+          regular expression pattern, the replacement (which may use $1
+          for submatches and {{.Schema}}, {{.Name}}, and {{.Type}} for
+          the object), and an optional list of the object types that the
+          rule is limited to, i.e.:
+
+              [[rewrite]]
+              pattern = ' COLLATE "USING_NLS_COMP"'
+              replace = ""
+
+              [[rewrite]]
+              pattern = '@PROD_LINK\b'
+              replace = "@DEV_LINK"
+              types = [ "VIEW", "PACKAGE" ]
+
+  -profile The named preset of settings to extract with. Settings from
+          the command line, or from the configuration file, take
+          precedence over those of the preset. Either:
+
+              minimal    No storage clauses, grants, or synonyms,
+                         and sequences that start at their MINVALUE
+                         (see -sequences reset)
+              full       Storage clauses, object grants, received
+                         grants, synonyms, and the data of the lookup
+                         tables (see -lookup-data)
+              code-only  Only functions, packages, procedures,
+                         triggers, and views, with neither storage
+                         clauses nor grants
+
+  -log-level The minimum level of the messages to log. Either "error",
+          "warn", "info", "debug", or "off". Defaults to "info".
+
+  -log-format The format of the log messages, which are written to
+          stderr. Either "text" for logfmt style key=value pairs or
+          "json" for a JSON object per message. Defaults to "text".
+
+  -debug  Debug mode. Sets the log level to "debug".
+
+  -q      Quiet mode. Do not print any error messages. Sets the log
+          level to "off".
+
+`)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// watchSchemas polls the database at the watch interval and extracts
+// the objects that have changed since the previous poll, starting from
+// the since time, until interrupted
+func watchSchemas(ctx context.Context, db *sql.DB, base string, since time.Time, prune bool) {
+
+	watching = &watchState{extracted: make(map[string]time.Time)}
+	defer func() { watching = nil }()
+
+	ticker := time.NewTicker(watch)
+	defer ticker.Stop()
+
+	prev := since
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Use the database time so that clock differences between the
+		// client and the database do not cause changes to be missed
+		now, err := dbTime(ctx, db)
+		if err != nil {
+			logError(err)
+			continue
+		}
+
+		watching.poll = now
+		extractSchemas(ctx, db, schemas, xclude, base, since, prune)
+		since, prev = prev, now
+
+		if gitCommitOn {
+			commitChanges(base)
+		}
+	}
+}
+
+// dbTime returns the current time of the database
+func dbTime(ctx context.Context, db *sql.DB) (time.Time, error) {
+	var t time.Time
+	err := db.QueryRowContext(ctx, "SELECT sysdate FROM dual").Scan(&t)
+	return t, err
+}
+
+// watchState tracks the objects extracted while watching. As the last
+// DDL time of an object is that of when the DDL started, DDL that was
+// still running at the time of a poll may be found with a last DDL time
+// from before the poll. Each poll therefore looks for the changes made
+// from the start of the poll before the previous one, skipping the
+// objects already extracted with the same last DDL time. As the last DDL
+// time is only to the second, objects whose last DDL time is the second
+// of the poll that they were extracted in may have changed again during
+// that second so are not skipped.
+type watchState struct {
+	// the start of the current poll
+	poll      time.Time
+	extracted map[string]time.Time
+}
+
+// changed returns the names, sorted, of the changed objects of the
+// schema, with their last DDL times, that have not already been
+// extracted
+func (w *watchState) changed(schema string, times map[string]time.Time) []string {
+
+	var l []string
+	for name, t := range times {
+		if !w.seen(schema, name, t) {
+			l = append(l, name)
+		}
+	}
+	sort.Strings(l)
+
+	return l
+}
+
+// seen returns true if the object, with the specified last DDL time, has
+// already been extracted
+func (w *watchState) seen(schema, name string, lastDDL time.Time) bool {
+
+	key := fmt.Sprintf("%q.%q", schema, name)
+	if t, ok := w.extracted[key]; ok && t.Equal(lastDDL) {
+		return true
+	}
+
+	if lastDDL.Before(w.poll) {
+		w.extracted[key] = lastDDL
+	} else {
+		delete(w.extracted, key)
+	}
+	return false
+}
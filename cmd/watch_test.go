@@ -0,0 +1,59 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWatchStateChanged(t *testing.T) {
+
+	at := func(s string) time.Time {
+		v, err := time.Parse("15:04:05", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	w := &watchState{extracted: make(map[string]time.Time)}
+
+	polls := []struct {
+		poll  string
+		times map[string]time.Time
+		want  []string
+	}{
+		{
+			"10:00:00",
+			map[string]time.Time{"ORDERS": at("09:58:30"), "ORDER_API": at("10:00:00")},
+			[]string{"ORDERS", "ORDER_API"},
+		},
+		{
+			// Looking back to the start of the first poll finds the
+			// unchanged ORDERS again, and ORDER_API changed during the
+			// second of the first poll so may have changed again
+			"10:15:00",
+			map[string]time.Time{"ORDERS": at("09:58:30"), "ORDER_API": at("10:00:00"), "OPEN_ORDERS": at("10:07:12")},
+			[]string{"OPEN_ORDERS", "ORDER_API"},
+		},
+		{
+			// Changes made in the second of a poll are reported again
+			// by the next poll, and are then skipped
+			"10:30:00",
+			map[string]time.Time{"ORDER_API": at("10:00:00"), "OPEN_ORDERS": at("10:29:59"), "ORDER_SEQ": at("10:30:00")},
+			[]string{"OPEN_ORDERS", "ORDER_SEQ"},
+		},
+		{
+			"10:45:00",
+			map[string]time.Time{"OPEN_ORDERS": at("10:29:59"), "ORDER_SEQ": at("10:30:00")},
+			[]string{"ORDER_SEQ"},
+		},
+	}
+
+	for _, p := range polls {
+		w.poll = at(p.poll)
+		if got := w.changed("APP", p.times); !reflect.DeepEqual(got, p.want) {
+			t.Errorf("poll at %s: got %q, want %q", p.poll, got, p.want)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package oradex
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiffLine is a line that differs between two versions of DDL. Line is
+// one-based and either Disk or Live is empty when one version has more
+// lines than the other.
+type DiffLine struct {
+	Line int
+	Disk string
+	Live string
+}
+
+// CompareObjectDDL performs a line-by-line comparison of the DDL on disk
+// with the live DDL from the database, ignoring trailing white-space and
+// line ending differences. It returns the lines that differ and true if
+// the two are the same.
+func CompareObjectDDL(disk, live string) ([]DiffLine, bool) {
+
+	var diffs []DiffLine
+
+	d := splitLines(trimLine(disk))
+	l := splitLines(trimLine(live))
+
+	n := len(d)
+	if len(l) > n {
+		n = len(l)
+	}
+
+	for i := 0; i < n; i++ {
+		var dl, ll string
+		if i < len(d) {
+			dl = trimLine(d[i])
+		}
+		if i < len(l) {
+			ll = trimLine(l[i])
+		}
+		if dl != ll || i >= len(d) || i >= len(l) {
+			diffs = append(diffs, DiffLine{Line: i + 1, Disk: dl, Live: ll})
+		}
+	}
+
+	return diffs, len(diffs) == 0
+}
+
+// UnifiedDiff returns the differences between the DDL on disk and the
+// live DDL from the database in unified diff format with the specified
+// number of lines of context. As with CompareObjectDDL, trailing
+// white-space and line ending differences are ignored. An empty string
+// is returned if the two are the same.
+func UnifiedDiff(diskName, liveName, disk, live string, context int) string {
+
+	d := splitLines(trimLine(disk))
+	l := splitLines(trimLine(live))
+	if disk == "" {
+		d = nil
+	}
+	for i := range d {
+		d[i] = trimLine(d[i])
+	}
+	for i := range l {
+		l[i] = trimLine(l[i])
+	}
+
+	ops := diffOps(d, l)
+
+	// Group the edits, with their context, into hunks
+	var hunks [][]diffOp
+	var hunk []diffOp
+	lastEdit := -1
+	for i, op := range ops {
+		if op.kind == ' ' {
+			continue
+		}
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		if lastEdit >= 0 && start <= lastEdit+context+1 {
+			hunk = append(hunk, ops[lastEdit+1:i+1]...)
+		} else {
+			if hunk != nil {
+				hunks = append(hunks, append(hunk, ops[lastEdit+1:minInt(lastEdit+1+context, len(ops))]...))
+			}
+			hunk = append([]diffOp(nil), ops[start:i+1]...)
+		}
+		lastEdit = i
+	}
+	if hunk == nil {
+		return ""
+	}
+	hunks = append(hunks, append(hunk, ops[lastEdit+1:minInt(lastEdit+1+context, len(ops))]...))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", diskName, liveName)
+	for _, h := range hunks {
+		var dn, ln int
+		for _, op := range h {
+			if op.kind != '+' {
+				dn++
+			}
+			if op.kind != '-' {
+				ln++
+			}
+		}
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(h[0].disk, dn), hunkRange(h[0].live, ln))
+		for _, op := range h {
+			fmt.Fprintf(&b, "%c%s\n", op.kind, op.text)
+		}
+	}
+
+	return b.String()
+}
+
+// diffOp is a single line of a diff. The kind is one of ' ', '-', or
+// '+' and disk and live are the zero-based line numbers of the line in
+// the respective versions.
+type diffOp struct {
+	kind rune
+	text string
+	disk int
+	live int
+}
+
+// diffOps returns the edit script for turning d into l. The common
+// prefix and suffix are trimmed before using the linear space variant of
+// Myers' algorithm so that large, mostly unchanged, DDL neither takes
+// quadratic time nor memory.
+func diffOps(d, l []string) []diffOp {
+	var ops []diffOp
+	diffRange(d, l, 0, 0, &ops)
+	return ops
+}
+
+// diffRange appends the edit script for turning d into l, which start at
+// lines i and j of the respective versions, to ops
+func diffRange(d, l []string, i, j int, ops *[]diffOp) {
+
+	for len(d) > 0 && len(l) > 0 && d[0] == l[0] {
+		*ops = append(*ops, diffOp{' ', d[0], i, j})
+		d, l = d[1:], l[1:]
+		i++
+		j++
+	}
+
+	n := 0
+	for n < len(d) && n < len(l) && d[len(d)-1-n] == l[len(l)-1-n] {
+		n++
+	}
+	suffix := d[len(d)-n:]
+	d, l = d[:len(d)-n], l[:len(l)-n]
+
+	switch {
+	case len(d) == 0:
+		for k, s := range l {
+			*ops = append(*ops, diffOp{'+', s, i, j + k})
+		}
+	case len(l) == 0:
+		for k, s := range d {
+			*ops = append(*ops, diffOp{'-', s, i + k, j})
+		}
+	default:
+		x, y, u, v := middleSnake(d, l)
+		diffRange(d[:x], l[:y], i, j, ops)
+		for k := x; k < u; k++ {
+			*ops = append(*ops, diffOp{' ', d[k], i + k, j + y + k - x})
+		}
+		diffRange(d[u:], l[v:], i+u, j+v, ops)
+	}
+
+	i, j = i+len(d), j+len(l)
+	for k, s := range suffix {
+		*ops = append(*ops, diffOp{' ', s, i + k, j + k})
+	}
+}
+
+// middleSnake returns the start (x, y) and end (u, v) of the middle
+// snake of the shortest edit script for turning a into b, neither of
+// which may be empty, by searching forwards from the start and
+// backwards from the end until the two searches overlap
+func middleSnake(a, b []string) (x, y, u, v int) {
+
+	N, M := len(a), len(b)
+	delta := N - M
+	max := (N + M + 1) / 2
+	off := max + 1
+
+	// the furthest x reached on each diagonal k (= x - y), forwards and
+	// backwards (with x and y measured from the ends)
+	vf := make([]int, 2*max+3)
+	vb := make([]int, 2*max+3)
+
+	for D := 0; D <= max; D++ {
+		for k := -D; k <= D; k += 2 {
+			if k == -D || (k != D && vf[off+k-1] < vf[off+k+1]) {
+				x = vf[off+k+1]
+			} else {
+				x = vf[off+k-1] + 1
+			}
+			y = x - k
+			u, v = x, y
+			for u < N && v < M && a[u] == b[v] {
+				u++
+				v++
+			}
+			vf[off+k] = u
+			if delta%2 != 0 && delta-k >= -(D-1) && delta-k <= D-1 && u+vb[off+delta-k] >= N {
+				return x, y, u, v
+			}
+		}
+		for k := -D; k <= D; k += 2 {
+			var rx, ry int
+			if k == -D || (k != D && vb[off+k-1] < vb[off+k+1]) {
+				rx = vb[off+k+1]
+			} else {
+				rx = vb[off+k-1] + 1
+			}
+			ry = rx - k
+			ru, rv := rx, ry
+			for ru < N && rv < M && a[N-1-ru] == b[M-1-rv] {
+				ru++
+				rv++
+			}
+			vb[off+k] = ru
+			if delta%2 == 0 && delta-k >= -D && delta-k <= D && ru+vf[off+delta-k] >= N {
+				return N - ru, M - rv, N - rx, M - ry
+			}
+		}
+	}
+
+	panic("diff: no middle snake found")
+}
+
+// hunkRange formats the start,count of a unified diff hunk header
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
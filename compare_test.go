@@ -0,0 +1,63 @@
+package oradex
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareObjectDDL(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		disk  string
+		live  string
+		diffs []DiffLine
+	}{
+		{"same", "a\nb\n", "a\nb", nil},
+		{"line endings and trailing white-space", "a  \r\nb\r\n", "a\nb\t\n", nil},
+		{"changed", "a\nb\nc", "a\nB\nc", []DiffLine{{Line: 2, Disk: "b", Live: "B"}}},
+		{"added", "a", "a\nb", []DiffLine{{Line: 2, Disk: "", Live: "b"}}},
+		{"removed blank line", "a\n\nb", "a\nb", []DiffLine{{Line: 2, Disk: "", Live: "b"}, {Line: 3, Disk: "b", Live: ""}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs, same := CompareObjectDDL(tt.disk, tt.live)
+			if !reflect.DeepEqual(diffs, tt.diffs) || same != (len(tt.diffs) == 0) {
+				t.Errorf("got %v %v, want %v", diffs, same, tt.diffs)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+
+	tests := []struct {
+		name string
+		disk string
+		live string
+		want string
+	}{
+		{"same", "a\nb\n", "a  \r\nb", ""},
+		{
+			"changed",
+			"a\nb\nc\nd\ne\nf\ng",
+			"a\nb\nc\nD\ne\nf\ng",
+			"--- disk\n+++ live\n@@ -2,5 +2,5 @@\n b\n c\n-d\n+D\n e\n f\n",
+		},
+		{
+			"new",
+			"",
+			"a\nb",
+			"--- disk\n+++ live\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UnifiedDiff("disk", "live", tt.disk, tt.live, 2); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
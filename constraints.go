@@ -0,0 +1,53 @@
+package oradex
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// constraintStateRe matches the state at the end of a constraint clause
+// as generated by DBMS_METADATA (i.e. RELY ENABLE NOVALIDATE). Requiring
+// the state to end the clause avoids matching the likes of ENABLE ROW
+// MOVEMENT and ENABLE STORAGE IN ROW.
+var constraintStateRe = regexp.MustCompile(`(?i)\b((?:NO)?RELY\s+)?(ENABLE|DISABLE)(\s+(?:NO)?VALIDATE)?(\s*(?:,|;|\)|\n|$))`)
+
+// deferredConstraintRe matches the ALTER TABLE statements that add the
+// foreign key and check constraints of a table, which may be deferred
+// until after the data is loaded
+var deferredConstraintRe = regexp.MustCompile(`(?is)^\s*ALTER\s+TABLE\s+(?:"[^"]*"|[\w$#]+)(?:\.(?:"[^"]*"|[\w$#]+))?\s+ADD\s+(?:CONSTRAINT\s+(?:"[^"]*"|[\w$#]+)\s+)?(?:FOREIGN\s+KEY|CHECK)\b`)
+
+var validStateRe = regexp.MustCompile(`(?i)^((NO)?RELY\s+)?(ENABLE|DISABLE)(\s+(NO)?VALIDATE)?$`)
+
+// ParseConstraintState validates, and normalizes, a constraint state
+// such as ENABLE NOVALIDATE or RELY DISABLE
+func ParseConstraintState(s string) (string, error) {
+
+	s = strings.ToUpper(strings.Join(strings.Fields(s), " "))
+	if !validStateRe.MatchString(s) {
+		return "", fmt.Errorf("invalid constraint state %q, expected [RELY|NORELY] ENABLE|DISABLE [VALIDATE|NOVALIDATE]", s)
+	}
+	return s, nil
+}
+
+// SetConstraintState rewrites the state of all of the constraints in the
+// DDL for a table to the specified state (see ParseConstraintState).
+// The RELY state of each constraint is preserved unless the specified
+// state includes RELY or NORELY.
+func SetConstraintState(objDDL, state string) string {
+
+	m := validStateRe.FindStringSubmatch(state)
+	if m == nil {
+		return objDDL
+	}
+
+	return constraintStateRe.ReplaceAllStringFunc(objDDL, func(s string) string {
+		c := constraintStateRe.FindStringSubmatch(s)
+
+		rely := c[1]
+		if m[1] != "" {
+			rely = m[1]
+		}
+		return rely + m[3] + m[4] + c[4]
+	})
+}
@@ -0,0 +1,72 @@
+package oradex
+
+import (
+	"context"
+	"fmt"
+)
+
+// CurrentContainer returns the name of the container that the session
+// is connected to. For non-CDB databases this is the database name.
+func CurrentContainer(ctx context.Context, db Querier) (string, error) {
+
+	var name string
+
+	query := "SELECT sys_context ( 'USERENV', 'CON_NAME' ) FROM dual"
+	if !HasFeature(ctx, FeatureMultitenant) {
+		query = "SELECT sys_context ( 'USERENV', 'DB_NAME' ) FROM dual"
+	}
+
+	err := db.QueryRowContext(ctx, query).Scan(&name)
+	return name, err
+}
+
+// PluggableDatabases returns the names of the open pluggable databases
+// when connected to the root container of a CDB. The seed PDB is not
+// included.
+func PluggableDatabases(ctx context.Context, db Querier) ([]string, error) {
+
+	var l []string
+
+	con, err := CurrentContainer(ctx, db)
+	if err != nil {
+		return l, err
+	}
+	if con != "CDB$ROOT" {
+		return l, fmt.Errorf("not connected to the root container of a CDB (connected to %q)", con)
+	}
+
+	query := `
+SELECT name
+    FROM v$pdbs
+    WHERE open_mode IN ( 'READ WRITE', 'READ ONLY' )
+        AND name <> 'PDB$SEED'
+    ORDER BY name
+`
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return l, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	for rows.Next() {
+		var name string
+		err = rows.Scan(&name)
+		if err != nil {
+			return l, err
+		}
+		l = append(l, name)
+	}
+
+	return l, err
+}
+
+// SetContainerSQL returns the statement for switching the session to the
+// specified container
+func SetContainerSQL(name string) string {
+	return fmt.Sprintf("ALTER SESSION SET CONTAINER = \"%s\"", name)
+}
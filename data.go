@@ -0,0 +1,307 @@
+package oradex
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// DataColumn is a column of a table that is exported by ExportTableData
+type DataColumn struct {
+	Name     string
+	DataType string
+	Length   int64
+}
+
+// tableDataColumns returns the columns of a table that can be exported
+// as CSV. Columns of other data types (BLOB, LONG, object types, etc.)
+// are returned separately.
+func tableDataColumns(ctx context.Context, db Querier, schema, table string) ([]DataColumn, []string, error) {
+
+	var l []DataColumn
+	var skipped []string
+
+	query := `
+SELECT column_name,
+        data_type,
+        CASE
+            WHEN char_used = 'C' THEN char_length
+            ELSE data_length
+            END AS data_length
+    FROM dba_tab_columns
+    WHERE owner = :1
+        AND table_name = :2
+    ORDER BY column_id
+`
+
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, query), schema, table)
+	if err != nil {
+		return l, skipped, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	for rows.Next() {
+		var c DataColumn
+		err = rows.Scan(&c.Name, &c.DataType, &c.Length)
+		if err != nil {
+			return l, skipped, err
+		}
+		if dataColumnExpr(c) == "" {
+			skipped = append(skipped, c.Name)
+			continue
+		}
+		l = append(l, c)
+	}
+
+	return l, skipped, err
+}
+
+// dataColumnExpr returns the expression for selecting the column as
+// text, or an empty string if the data type of the column is not
+// supported. Dates, timestamps, and numbers are formatted independently
+// of the session NLS settings.
+func dataColumnExpr(c DataColumn) string {
+
+	col := `"` + c.Name + `"`
+
+	switch {
+	case c.DataType == "DATE":
+		return fmt.Sprintf("to_char ( %s, 'YYYY-MM-DD HH24:MI:SS' )", col)
+	case strings.HasPrefix(c.DataType, "TIMESTAMP") && strings.HasSuffix(c.DataType, "TIME ZONE"):
+		return fmt.Sprintf("to_char ( %s, 'YYYY-MM-DD HH24:MI:SS.FF9 TZH:TZM' )", col)
+	case strings.HasPrefix(c.DataType, "TIMESTAMP"):
+		return fmt.Sprintf("to_char ( %s, 'YYYY-MM-DD HH24:MI:SS.FF9' )", col)
+	case c.DataType == "NUMBER", c.DataType == "FLOAT", c.DataType == "BINARY_FLOAT", c.DataType == "BINARY_DOUBLE":
+		return fmt.Sprintf("to_char ( %s, 'TM9', 'NLS_NUMERIC_CHARACTERS = ''.,''' )", col)
+	case c.DataType == "RAW":
+		return fmt.Sprintf("rawtohex ( %s )", col)
+	case c.DataType == "CHAR", c.DataType == "VARCHAR2", c.DataType == "NCHAR", c.DataType == "NVARCHAR2",
+		c.DataType == "CLOB", c.DataType == "NCLOB":
+		return col
+	}
+
+	return ""
+}
+
+// ExportTableData writes the data in the specified table to the writer
+// as CSV, with a header row of the column names, and returns the columns
+// that were exported, the names of the columns that were skipped, and
+// the number of rows. Columns with data types that can not be
+// represented as text (BLOB, LONG, object types, etc.) are skipped.
+func ExportTableData(ctx context.Context, w io.Writer, db Querier, schema, table string) ([]DataColumn, []string, int64, error) {
+
+	var n int64
+
+	cols, skipped, err := tableDataColumns(ctx, db, schema, table)
+	if err != nil {
+		return cols, skipped, n, err
+	}
+	if len(cols) == 0 {
+		return cols, skipped, n, fmt.Errorf("no exportable columns found for %q.%q", schema, table)
+	}
+
+	var names []string
+	var exprs []string
+	var order []string
+	for _, c := range cols {
+		names = append(names, c.Name)
+		exprs = append(exprs, dataColumnExpr(c))
+		// Order by the raw (non-LOB) columns so that repeated exports
+		// of unchanged data are the same
+		if c.DataType != "CLOB" && c.DataType != "NCLOB" {
+			order = append(order, fmt.Sprintf("t.\"%s\"", c.Name))
+		}
+	}
+
+	query := fmt.Sprintf("SELECT %s\n    FROM \"%s\".\"%s\" t", strings.Join(exprs, ",\n        "), schema, table)
+	if len(order) > 0 {
+		query += "\n    ORDER BY " + strings.Join(order, ",\n        ")
+	}
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return cols, skipped, n, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	cw := csv.NewWriter(w)
+	err = cw.Write(names)
+	if err != nil {
+		return cols, skipped, n, err
+	}
+
+	values := make([]sql.NullString, len(cols))
+	dest := make([]interface{}, len(cols))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	record := make([]string, len(cols))
+
+	for rows.Next() {
+		err = rows.Scan(dest...)
+		if err != nil {
+			return cols, skipped, n, err
+		}
+		for i, v := range values {
+			record[i] = v.String
+		}
+		err = cw.Write(record)
+		if err != nil {
+			return cols, skipped, n, err
+		}
+		n++
+	}
+	if err = rows.Err(); err != nil {
+		return cols, skipped, n, err
+	}
+
+	cw.Flush()
+	return cols, skipped, n, cw.Error()
+}
+
+// plainNameRe matches the names that need not be quoted
+var plainNameRe = regexp.MustCompile(`^[A-Z][A-Z0-9_$#]*$`)
+
+// loaderBind returns the bind variable for referencing a field in the
+// SQL string of a SQL*Loader control file. Names that need quoting are
+// quoted with escaped double quotes as the SQL string is itself double
+// quoted.
+func loaderBind(name string) string {
+	if plainNameRe.MatchString(name) {
+		return name
+	}
+	return `\"` + name + `\"`
+}
+
+// MaxLookupRows is the most rows, as of the last gathering of the table
+// statistics, that a table may have to be taken as a lookup table (see
+// LookupTables)
+const MaxLookupRows = 1000
+
+// LookupTables returns the lookup (reference) tables of the specified
+// schema: the tables that are referenced by the foreign keys of other
+// tables and that have no more than MaxLookupRows rows. Tables that have
+// no statistics are not included.
+func LookupTables(ctx context.Context, db Querier, schema string) ([]string, error) {
+
+	var l []string
+
+	query := `
+SELECT t.table_name
+    FROM dba_tables t
+    WHERE t.owner = :1
+        AND t.num_rows <= :2
+        AND EXISTS (
+            SELECT 1
+                FROM dba_constraints p
+                JOIN dba_constraints r
+                    ON ( r.r_owner = p.owner
+                        AND r.r_constraint_name = p.constraint_name )
+                WHERE p.owner = t.owner
+                    AND p.table_name = t.table_name
+                    AND p.constraint_type IN ( 'P', 'U' )
+                    AND r.constraint_type = 'R'
+                    AND NOT ( r.owner = t.owner
+                        AND r.table_name = t.table_name ) )
+    ORDER BY t.table_name
+`
+
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, query), schema, MaxLookupRows)
+	if err != nil {
+		return l, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	for rows.Next() {
+		var name string
+		err = rows.Scan(&name)
+		if err != nil {
+			return l, err
+		}
+		l = append(l, name)
+	}
+
+	return l, err
+}
+
+// LoaderCharacterSet returns the SQL*Loader CHARACTERSET (and, for
+// UTF-16, BYTEORDER) clause for the CSV files written with the specified
+// encoding (see EncodeText)
+func LoaderCharacterSet(encoding string) (string, error) {
+
+	switch strings.ToLower(strings.Replace(encoding, "_", "-", -1)) {
+	case "", "utf-8", "utf8":
+		return "CHARACTERSET AL32UTF8", nil
+	case "utf-16le", "utf16le":
+		return "CHARACTERSET AL16UTF16\nBYTEORDER LITTLE", nil
+	case "utf-16be", "utf16be":
+		return "CHARACTERSET AL16UTF16\nBYTEORDER BIG", nil
+	case "latin1", "latin-1", "iso-8859-1", "iso8859-1":
+		return "CHARACTERSET WE8ISO8859P1", nil
+	}
+	return "", fmt.Errorf("unknown encoding %q", encoding)
+}
+
+// LoaderControlFile returns the SQL*Loader control file for loading the
+// CSV file, as written by ExportTableData and encoded with the specified
+// encoding, into the specified table. Note that the control file does
+// not support values that contain line endings.
+func LoaderControlFile(schema, table, csvFile, encoding string, cols []DataColumn) (string, error) {
+
+	charset, err := LoaderCharacterSet(encoding)
+	if err != nil {
+		return "", err
+	}
+
+	var l []string
+	for _, c := range cols {
+		col := `"` + c.Name + `"`
+
+		switch {
+		case c.DataType == "DATE":
+			col += ` DATE "YYYY-MM-DD HH24:MI:SS"`
+		case strings.HasPrefix(c.DataType, "TIMESTAMP") && strings.HasSuffix(c.DataType, "TIME ZONE"):
+			col += ` TIMESTAMP WITH TIME ZONE "YYYY-MM-DD HH24:MI:SS.FF9 TZH:TZM"`
+		case strings.HasPrefix(c.DataType, "TIMESTAMP"):
+			col += ` TIMESTAMP "YYYY-MM-DD HH24:MI:SS.FF9"`
+		case c.DataType == "CLOB", c.DataType == "NCLOB":
+			col += " CHAR(1000000)"
+		case c.DataType == "RAW":
+			col += fmt.Sprintf(" CHAR(%d) \"hextoraw ( :%s )\"", c.Length*2, loaderBind(c.Name))
+		case c.Length > 255:
+			// The default maximum length for a character field is 255
+			col += fmt.Sprintf(" CHAR(%d)", c.Length)
+		}
+
+		l = append(l, col)
+	}
+
+	return fmt.Sprintf(`OPTIONS ( SKIP=1 )
+LOAD DATA
+%s
+INFILE '%s'
+APPEND
+INTO TABLE "%s"."%s"
+FIELDS TERMINATED BY ',' OPTIONALLY ENCLOSED BY '"'
+TRAILING NULLCOLS
+(
+    %s
+)
+`, charset, csvFile, schema, table, strings.Join(l, ",\n    ")), nil
+}
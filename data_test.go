@@ -0,0 +1,94 @@
+package oradex
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestLookupTables(t *testing.T) {
+
+	db := openFixtures(t, "app.json")
+
+	got, err := LookupTables(context.Background(), db, "APP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ORDER_STATUSES", "REGIONS"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLoaderCharacterSet(t *testing.T) {
+
+	tests := []struct {
+		encoding string
+		want     string
+		wantErr  bool
+	}{
+		{"", "CHARACTERSET AL32UTF8", false},
+		{"UTF_8", "CHARACTERSET AL32UTF8", false},
+		{"utf-16le", "CHARACTERSET AL16UTF16\nBYTEORDER LITTLE", false},
+		{"UTF-16BE", "CHARACTERSET AL16UTF16\nBYTEORDER BIG", false},
+		{"latin1", "CHARACTERSET WE8ISO8859P1", false},
+		{"ebcdic", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.encoding, func(t *testing.T) {
+			got, err := LoaderCharacterSet(tt.encoding)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoaderControlFile(t *testing.T) {
+
+	cols := []DataColumn{
+		{Name: "ORDER_ID", DataType: "NUMBER", Length: 22},
+		{Name: "NOTE", DataType: "VARCHAR2", Length: 200},
+		{Name: "DETAILS", DataType: "VARCHAR2", Length: 4000},
+		{Name: "CREATED", DataType: "DATE", Length: 7},
+		{Name: "UPDATED", DataType: "TIMESTAMP(6)", Length: 11},
+		{Name: "SHIPPED", DataType: "TIMESTAMP(6) WITH TIME ZONE", Length: 13},
+		{Name: "BODY", DataType: "CLOB", Length: 4000},
+		{Name: "TOKEN", DataType: "RAW", Length: 16},
+	}
+
+	want := `OPTIONS ( SKIP=1 )
+LOAD DATA
+CHARACTERSET AL32UTF8
+INFILE 'ORDERS.csv'
+APPEND
+INTO TABLE "APP"."ORDERS"
+FIELDS TERMINATED BY ',' OPTIONALLY ENCLOSED BY '"'
+TRAILING NULLCOLS
+(
+    "ORDER_ID",
+    "NOTE",
+    "DETAILS" CHAR(4000),
+    "CREATED" DATE "YYYY-MM-DD HH24:MI:SS",
+    "UPDATED" TIMESTAMP "YYYY-MM-DD HH24:MI:SS.FF9",
+    "SHIPPED" TIMESTAMP WITH TIME ZONE "YYYY-MM-DD HH24:MI:SS.FF9 TZH:TZM",
+    "BODY" CHAR(1000000),
+    "TOKEN" CHAR(32) "hextoraw ( :TOKEN )"
+)
+`
+
+	got, err := LoaderControlFile("APP", "ORDERS", "ORDERS.csv", "", cols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := LoaderControlFile("APP", "ORDERS", "ORDERS.csv", "ebcdic", cols); err == nil {
+		t.Error("no error for an unknown encoding")
+	}
+}
@@ -0,0 +1,177 @@
+package oradex
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Dictionary describes how the data dictionary of the connected
+// database is queried: which views are used and the version of the
+// database that the version dependent queries are gated on. It is
+// carried on the context (see WithDictionary) rather than held in
+// package state so that concurrent extractions, from different
+// databases or with different privileges, do not interfere.
+type Dictionary struct {
+	// AllViews uses the all_* (and user_*) data dictionary views in
+	// place of the dba_* views. This allows for extracting the DDL as a
+	// user that does not have access to the dba_* views, with the caveat
+	// that only those objects that are visible to the user are extracted
+	// and that the database level queries (roles, users, etc.) are
+	// limited to what the user has been granted.
+	AllViews bool
+	// MissingViews is the set of dba_* views that are not accessible
+	// (see DetectMissingViews) and that are replaced in the same manner
+	// as for AllViews
+	MissingViews map[string]bool
+	// Version is the version of the connected database (see DbVersion).
+	// When zero, all features are assumed to be supported.
+	Version OracleVersion
+	// SystemSchemas are the Oracle supplied schemas that are excluded
+	// from the schema listings and the dependencies. When nil, the
+	// default list (see ExcludedSchemas) is used.
+	SystemSchemas []string
+}
+
+// dictionaryKey is the context key for the Dictionary
+type dictionaryKey struct{}
+
+// WithDictionary returns a copy of the context that carries the
+// data dictionary settings
+func WithDictionary(ctx context.Context, d Dictionary) context.Context {
+	return context.WithValue(ctx, dictionaryKey{}, d)
+}
+
+// DictionaryFrom returns the data dictionary settings carried by the
+// context, or the zero Dictionary (the dba_* views of a database of
+// unknown version) if there are none
+func DictionaryFrom(ctx context.Context) Dictionary {
+	d, _ := ctx.Value(dictionaryKey{}).(Dictionary)
+	return d
+}
+
+// replaced returns true if the dba_* view is replaced by its all_* (or
+// user_*) equivalent
+func (d Dictionary) replaced(view string) bool {
+	return d.AllViews || d.MissingViews[strings.ToLower(view)]
+}
+
+// allViews maps the dba_* views that either have no all_* equivalent or
+// whose all_* equivalent has different columns to a replacement. Views
+// that are not listed are mapped to the all_* view of the same name.
+var allViews = map[string]string{
+	"dba_col_privs":  "( SELECT grantor, grantee, table_schema AS owner, table_name, column_name, privilege, grantable FROM all_col_privs )",
+	"dba_role_privs": "( SELECT username AS grantee, granted_role, admin_option FROM user_role_privs )",
+	"dba_roles":      "( SELECT role, 'N' AS oracle_maintained FROM session_roles )",
+	"dba_segments":   "( SELECT user AS owner, s.* FROM user_segments s )",
+	"dba_sys_privs":  "( SELECT username AS grantee, privilege, admin_option FROM user_sys_privs )",
+	"dba_tab_privs":  "( SELECT grantor, grantee, table_schema AS owner, table_name, privilege, grantable, type FROM all_tab_privs )",
+	"dba_ts_quotas":  "( SELECT user AS username, q.* FROM user_ts_quotas q )",
+	"dba_users":      "( SELECT username, default_tablespace, temporary_tablespace FROM user_users )",
+}
+
+// dictViews is the list of the dba_* views that are used for extracting
+// the DDL
+var dictViews = []string{
+	"dba_col_comments", "dba_col_privs", "dba_constraints", "dba_dependencies", "dba_directories",
+	"dba_external_locations", "dba_external_tables", "dba_indexes", "dba_lockdown_profiles",
+	"dba_mview_comments", "dba_objects", "dba_queue_subscribers", "dba_queue_tables",
+	"dba_role_privs", "dba_roles", "dba_segments", "dba_sequences", "dba_source", "dba_synonyms",
+	"dba_sys_privs", "dba_tab_columns", "dba_tab_comments", "dba_tab_privs", "dba_triggers",
+	"dba_ts_quotas", "dba_users",
+}
+
+// noAllViews is the set of dba_* views that have no all_* equivalent.
+// Queries using them fail, or are skipped, when the view is replaced
+// (see ViewAvailable).
+var noAllViews = map[string]bool{
+	"dba_lockdown_profiles": true,
+}
+
+var dbaViewRe = regexp.MustCompile(`(?i)\bdba_[a-z0-9_$#]+`)
+
+// DetectMissingViews determines which of the dba_* views that are used
+// for extracting the DDL are not accessible to the connected user, as is
+// the case with restricted privilege environments such as Amazon RDS.
+// The returned views should be set as the MissingViews of the Dictionary
+// so that they are replaced by their all_* equivalents.
+func DetectMissingViews(ctx context.Context, db Querier) ([]string, error) {
+
+	var l []string
+	for _, v := range dictViews {
+		var n int
+		err := db.QueryRowContext(ctx, fmt.Sprintf("SELECT count (*) FROM %s WHERE rownum = 1", v)).Scan(&n)
+		if err != nil {
+			if ctx.Err() != nil {
+				return l, ctx.Err()
+			}
+			l = append(l, v)
+		}
+	}
+
+	return l, nil
+}
+
+// ViewAvailable returns true if the dba_* view is being used or if the
+// all_* equivalent that replaces it exists
+func ViewAvailable(ctx context.Context, view string) bool {
+
+	view = strings.ToLower(view)
+	if !DictionaryFrom(ctx).replaced(view) {
+		return true
+	}
+	return !noAllViews[view]
+}
+
+// requireDbaView returns an error if the dba_* view is replaced, for the
+// queries that need columns that the replacement does not have
+func requireDbaView(ctx context.Context, view string) error {
+	if DictionaryFrom(ctx).replaced(view) {
+		return fmt.Errorf("no access to %s: %w", view, ErrInsufficientPrivilege)
+	}
+	return nil
+}
+
+// HasDbaViews returns true if the connected user is able to query the
+// dba_* data dictionary views
+func HasDbaViews(ctx context.Context, db Querier) bool {
+
+	var n int
+	err := db.QueryRowContext(ctx, "SELECT count (*) FROM dba_objects WHERE rownum = 1").Scan(&n)
+	return err == nil
+}
+
+// DictQuery returns the query with the dba_* data dictionary views
+// replaced by their all_* equivalents, as determined by the Dictionary
+// carried by the context, otherwise the query is returned unchanged.
+func DictQuery(ctx context.Context, query string) string {
+
+	d := DictionaryFrom(ctx)
+	if !d.AllViews && len(d.MissingViews) == 0 {
+		return query
+	}
+
+	return dbaViewRe.ReplaceAllStringFunc(query, func(view string) string {
+		v := strings.ToLower(view)
+		if !d.replaced(v) || noAllViews[v] {
+			return view
+		}
+		if r, ok := allViews[v]; ok {
+			return r
+		}
+		return "all_" + v[len("dba_"):]
+	})
+}
+
+// atLink returns the query with the data dictionary views referencing
+// the views in the database at the other end of the database link. The
+// query is returned unchanged if no link is specified.
+func atLink(query, link string) string {
+
+	if link == "" {
+		return query
+	}
+
+	return regexp.MustCompile(`(?i)\b((dba|all|user)_[a-z0-9_$#]+)`).ReplaceAllString(query, "${1}@"+link)
+}
@@ -0,0 +1,196 @@
+package oradex
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SchemaChange is a change that is needed to convert an object in one
+// schema to match the object in another schema. Change is one of
+// create, drop, alter, or replace.
+type SchemaChange struct {
+	Schema  string
+	Name    string
+	ObjType string
+	Change  string
+	DDL     string
+}
+
+// String returns the DDL for the change preceded by a comment
+// describing the change
+func (c SchemaChange) String() string {
+	return fmt.Sprintf("-- %s %s \"%s\".\"%s\"\n%s", c.Change, strings.ToLower(c.ObjType), c.Schema, c.Name, c.DDL)
+}
+
+// compareAlterTypes are the object types that DBMS_METADATA_DIFF is able
+// to generate ALTER statements for
+var compareAlterTypes = []string{
+	typeDatabaseLink,
+	typeMaterializedView,
+	typeQueue,
+	typeQueueTable,
+	typeSequence,
+	typeTable,
+	typeTrigger,
+	typeView,
+}
+
+// CompareAlter uses DBMS_METADATA_DIFF to return the ALTER statements
+// needed to convert the object in the target schema, in the database at
+// the other end of the target link if one is specified, to match the
+// object in the source schema.
+func CompareAlter(ctx context.Context, db Querier, objType, name, sourceSchema, targetSchema, targetLink string) (string, error) {
+
+	query := `
+SELECT dbms_metadata_diff.compare_alter ( :1, :2, :3, :4, :5, :6 )
+    FROM dual
+`
+	var link interface{}
+	if targetLink != "" {
+		link = targetLink
+	}
+
+	return runQueryArgs(ctx, db, query, metadataType(objType), name, name, targetSchema, sourceSchema, link)
+}
+
+// CompareSchemas returns the changes needed to convert the objects in
+// the target schema, in the database at the other end of the target
+// link if one is specified, to match the objects in the source schema.
+// Objects only in the source schema are created, objects only in the
+// target schema are dropped, tables and other objects supported by
+// DBMS_METADATA_DIFF are altered, and any PL/SQL that differs is
+// replaced.
+func CompareSchemas(ctx context.Context, db Querier, sourceSchema, targetSchema, targetLink string, opts ExportOptions) ([]SchemaChange, error) {
+
+	var changes []SchemaChange
+
+	src, err := schemaObjects(ctx, db, sourceSchema)
+	if err != nil {
+		return changes, err
+	}
+
+	tgt, err := schemaObjectsAt(ctx, db, targetSchema, targetLink)
+	if err != nil {
+		return changes, err
+	}
+
+	inTarget := make(map[string]bool)
+	for _, v := range tgt {
+		inTarget[v.ObjType+"."+v.Name] = true
+	}
+	inSource := make(map[string]bool)
+	for _, v := range src {
+		inSource[v.ObjType+"."+v.Name] = true
+	}
+
+	remap := map[string]string{sourceSchema: targetSchema}
+
+	for _, v := range src {
+		if !opts.IncludeObject(v.Name, v.ObjType) {
+			continue
+		}
+
+		c := SchemaChange{Schema: targetSchema, Name: v.Name, ObjType: v.ObjType}
+
+		switch {
+		case !inTarget[v.ObjType+"."+v.Name]:
+			c.Change = "create"
+			c.DDL, err = ObjDDL(ctx, db, sourceSchema, v.Name, v.ObjType)
+		case containsType(compareAlterTypes, v.ObjType):
+			c.Change = "alter"
+			c.DDL, err = CompareAlter(ctx, db, v.ObjType, v.Name, sourceSchema, targetSchema, targetLink)
+		default:
+			var same bool
+			if sourceTypes[v.ObjType] {
+				same, err = sameSource(ctx, db, v.Name, v.ObjType, sourceSchema, targetSchema, targetLink)
+			} else {
+				same, err = sameDDL(ctx, db, v.Name, v.ObjType, sourceSchema, targetSchema, targetLink)
+			}
+			if err == nil && !same {
+				c.Change = "replace"
+				c.DDL, err = ObjDDL(ctx, db, sourceSchema, v.Name, v.ObjType)
+			}
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return changes, ctx.Err()
+			}
+			logger.Error("unable to compare the object", "schema", sourceSchema, "name", v.Name, "error", err)
+			continue
+		}
+
+		c.DDL = trimString(RemapSchemas(c.DDL, remap))
+		if c.DDL != "" {
+			changes = append(changes, c)
+		}
+	}
+
+	for _, v := range tgt {
+		if inSource[v.ObjType+"."+v.Name] || !opts.IncludeObject(v.Name, v.ObjType) {
+			continue
+		}
+		changes = append(changes, SchemaChange{
+			Schema:  targetSchema,
+			Name:    v.Name,
+			ObjType: v.ObjType,
+			Change:  "drop",
+			DDL:     DropDDL(targetSchema, v.Name, v.ObjType, opts.DropCascade),
+		})
+	}
+
+	return changes, nil
+}
+
+// sameSource returns true if the source code for the specified object
+// (and body, if any) is the same in both schemas. See sameDDL for the
+// objects without source code.
+func sameSource(ctx context.Context, db Querier, name, objType, sourceSchema, targetSchema, targetLink string) (bool, error) {
+
+	query := `
+SELECT text
+    FROM dba_source
+    WHERE owner = :1
+        AND name = :2
+        AND type IN ( :3, :4 )
+    ORDER BY type,
+        line
+`
+	src, err := runQueryArgs(ctx, db, query, sourceSchema, name, objType, objType+" BODY")
+	if err != nil {
+		return false, err
+	}
+
+	tgt, err := runQueryArgs(ctx, db, atLink(query, targetLink), targetSchema, name, objType, objType+" BODY")
+	if err != nil {
+		return false, err
+	}
+
+	_, same := CompareObjectDDL(src, tgt)
+	return same, nil
+}
+
+// sameDDL returns true if the DDL for the specified object, with the
+// source schema remapped to the target schema, is the same in both
+// schemas. This is for the objects that have neither source code nor
+// support in DBMS_METADATA_DIFF (i.e. synonyms).
+func sameDDL(ctx context.Context, db Querier, name, objType, sourceSchema, targetSchema, targetLink string) (bool, error) {
+
+	src, err := ObjDDL(ctx, db, sourceSchema, name, objType)
+	if err != nil {
+		return false, err
+	}
+	src = RemapSchemas(src, map[string]string{sourceSchema: targetSchema})
+
+	fn := "dbms_metadata.get_ddl"
+	if targetLink != "" {
+		fn += "@" + targetLink
+	}
+	tgt, err := queryLob(ctx, db, "SELECT "+fn+" ( :1, :2, :3 ) FROM DUAL", metadataType(objType), name, targetSchema)
+	if err != nil {
+		return false, err
+	}
+
+	_, same := CompareObjectDDL(trimString(src), trimString(tgt))
+	return same, nil
+}
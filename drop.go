@@ -0,0 +1,117 @@
+package oradex
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DropDDL returns the DROP statement for the specified object. When
+// cascade is set then tables are dropped with CASCADE CONSTRAINTS and
+// types are dropped with FORCE. Scheduler objects are dropped using
+// DBMS_SCHEDULER, and queue tables using DBMS_AQADM, with cascade
+// setting the force parameter.
+func DropDDL(schema, name, objType string, cascade bool) string {
+
+	switch objType {
+	case typeRole:
+		return fmt.Sprintf("DROP ROLE \"%s\" ;", name)
+	case typeDatabaseLink:
+		if schema == "PUBLIC" {
+			return fmt.Sprintf("DROP PUBLIC DATABASE LINK \"%s\" ;", name)
+		}
+		// Database links can only be dropped by the owner
+		return fmt.Sprintf("DROP DATABASE LINK \"%s\" ;", name)
+	case typeChain, typeJob, typeProgram, typeSchedule:
+		return fmt.Sprintf("BEGIN\n    dbms_scheduler.drop_%s ( '\"%s\".\"%s\"', force => %t ) ;\nEND ;\n/", strings.ToLower(objType), schema, name, cascade)
+	case typeJobClass, typeWindow:
+		// Job classes and windows are always owned by SYS
+		return fmt.Sprintf("BEGIN\n    dbms_scheduler.drop_%s ( '\"%s\"', force => %t ) ;\nEND ;\n/", strings.Replace(strings.ToLower(objType), " ", "_", -1), name, cascade)
+	case typeQueue:
+		return fmt.Sprintf("BEGIN\n    dbms_aqadm.stop_queue ( queue_name => '\"%s\".\"%s\"' ) ;\n    dbms_aqadm.drop_queue ( queue_name => '\"%s\".\"%s\"' ) ;\nEND ;\n/", schema, name, schema, name)
+	case typeQueueTable:
+		return fmt.Sprintf("BEGIN\n    dbms_aqadm.drop_queue_table ( queue_table => '\"%s\".\"%s\"', force => %t ) ;\nEND ;\n/", schema, name, cascade)
+	case typeTable:
+		if cascade {
+			return fmt.Sprintf("DROP TABLE \"%s\".\"%s\" CASCADE CONSTRAINTS ;", schema, name)
+		}
+	case typeType:
+		if cascade {
+			return fmt.Sprintf("DROP TYPE \"%s\".\"%s\" FORCE ;", schema, name)
+		}
+	}
+
+	return fmt.Sprintf("DROP %s \"%s\".\"%s\" ;", objType, schema, name)
+}
+
+// UninstallScript returns the script for dropping the specified objects
+// of a schema in the reverse of the order that they would be created in:
+// the bodies of packages and types are dropped first, then each object
+// is dropped before the objects that it depends on, with tables dropped
+// before the tables that their foreign keys reference (see SchemaGraph).
+// Circular dependencies are broken at the first object revisited. When
+// cascade is set then the objects are dropped as for DropDDL.
+func UninstallScript(ctx context.Context, db Querier, schema string, objects []ObjectEntry, cascade bool) (string, error) {
+
+	edges, err := SchemaGraph(ctx, db, schema)
+	if err != nil {
+		return "", err
+	}
+	bodies, err := schemaBodies(ctx, db, schema)
+	if err != nil {
+		return "", err
+	}
+
+	// The creation order, with each object following the objects that it
+	// depends on
+	ordered := orderByGraph(objects, edges)
+
+	var l []string
+	for _, o := range objects {
+		if bodies[InventoryKey(o.Name, o.ObjType)] {
+			l = append(l, fmt.Sprintf("DROP %s BODY \"%s\".\"%s\" ;", o.ObjType, o.Schema, o.Name))
+		}
+	}
+	for i := len(ordered) - 1; i >= 0; i-- {
+		o := ordered[i]
+		l = append(l, DropDDL(o.Schema, o.Name, o.ObjType, cascade))
+	}
+
+	return strings.Join(l, "\n"), nil
+}
+
+// schemaBodies returns the packages and types of the specified schema
+// that have bodies, keyed as for SchemaInventory
+func schemaBodies(ctx context.Context, db Querier, schema string) (map[string]bool, error) {
+
+	m := make(map[string]bool)
+
+	query := `
+SELECT object_name,
+        object_type
+    FROM dba_objects
+    WHERE owner = :1
+        AND object_type IN ( 'PACKAGE BODY', 'TYPE BODY' )
+`
+
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, query), schema)
+	if err != nil {
+		return m, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	for rows.Next() {
+		var name, objType string
+		err = rows.Scan(&name, &objType)
+		if err != nil {
+			return m, err
+		}
+		m[InventoryKey(name, strings.TrimSuffix(objType, " BODY"))] = true
+	}
+
+	return m, err
+}
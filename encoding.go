@@ -0,0 +1,83 @@
+package oradex
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// The byte order marks for the encodings that EncodeText supports
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// EncodeText converts the line endings of the (UTF-8) text to eol,
+// either "lf" or "crlf", and encodes the text as "utf-8", "utf-16le",
+// "utf-16be", or "latin1" (ISO-8859-1), optionally starting with a byte
+// order mark. The line endings are left as is if eol is empty and the
+// encoding defaults to UTF-8. This allows for the output to be the same
+// regardless of the OS that the extraction is run on. An error is
+// returned for unknown line endings or encodings, and for text that
+// can not be represented in Latin-1.
+func EncodeText(b []byte, eol, encoding string, bom bool) ([]byte, error) {
+
+	switch strings.ToLower(eol) {
+	case "":
+	case "lf":
+		b = bytes.Replace(b, []byte("\r\n"), []byte("\n"), -1)
+	case "crlf":
+		b = bytes.Replace(b, []byte("\r\n"), []byte("\n"), -1)
+		b = bytes.Replace(b, []byte("\n"), []byte("\r\n"), -1)
+	default:
+		return nil, fmt.Errorf("unknown line ending %q", eol)
+	}
+
+	var out bytes.Buffer
+
+	switch strings.ToLower(strings.Replace(encoding, "_", "-", -1)) {
+	case "", "utf-8", "utf8":
+		if bom {
+			out.Write(bomUTF8)
+		}
+		out.Write(b)
+
+	case "utf-16le", "utf16le", "utf-16be", "utf16be":
+		be := strings.HasSuffix(strings.ToLower(encoding), "be")
+		if bom {
+			if be {
+				out.Write(bomUTF16BE)
+			} else {
+				out.Write(bomUTF16LE)
+			}
+		}
+		for _, u := range utf16.Encode([]rune(string(b))) {
+			if be {
+				out.Write([]byte{byte(u >> 8), byte(u)})
+			} else {
+				out.Write([]byte{byte(u), byte(u >> 8)})
+			}
+		}
+
+	case "latin1", "latin-1", "iso-8859-1", "iso8859-1":
+		if bom {
+			return nil, fmt.Errorf("no byte order mark for %s", encoding)
+		}
+		for len(b) > 0 {
+			r, n := utf8.DecodeRune(b)
+			if r > 0xFF || (r == utf8.RuneError && n == 1) {
+				return nil, fmt.Errorf("can not encode %q as %s", r, encoding)
+			}
+			out.WriteByte(byte(r))
+			b = b[n:]
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown encoding %q", encoding)
+	}
+
+	return out.Bytes(), nil
+}
@@ -0,0 +1,44 @@
+package oradex
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeText(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		in       string
+		eol      string
+		encoding string
+		bom      bool
+		want     []byte
+		wantErr  bool
+	}{
+		{"as is", "a\r\nb\n", "", "", false, []byte("a\r\nb\n"), false},
+		{"lf", "a\r\nb\n", "lf", "", false, []byte("a\nb\n"), false},
+		{"crlf", "a\r\nb\n", "CRLF", "", false, []byte("a\r\nb\r\n"), false},
+		{"utf-8 bom", "a", "", "utf-8", true, []byte{0xEF, 0xBB, 0xBF, 'a'}, false},
+		{"utf-16le", "aé\n", "", "utf-16le", true, []byte{0xFF, 0xFE, 'a', 0, 0xE9, 0, '\n', 0}, false},
+		{"utf-16be", "a€", "", "UTF_16BE", false, []byte{0, 'a', 0x20, 0xAC}, false},
+		{"utf-16 surrogates", "😀", "", "utf-16le", false, []byte{0x3D, 0xD8, 0x00, 0xDE}, false},
+		{"latin1", "café", "", "latin1", false, []byte{'c', 'a', 'f', 0xE9}, false},
+		{"latin1 out of range", "€", "", "latin1", false, nil, true},
+		{"latin1 bom", "a", "", "latin1", true, nil, true},
+		{"unknown line ending", "a", "cr", "", false, nil, true},
+		{"unknown encoding", "a", "", "ebcdic", false, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeText([]byte(tt.in), tt.eol, tt.encoding, tt.bom)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, want error %v", err, tt.wantErr)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("got % X, want % X", got, tt.want)
+			}
+		})
+	}
+}
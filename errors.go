@@ -0,0 +1,87 @@
+package oradex
+
+import (
+	"context"
+	"errors"
+
+	"github.com/godror/godror"
+)
+
+// The causes of the failure to extract an object. The errors returned
+// for objects are ObjectErrors that wrap both the underlying (ORA) error
+// and, where it can be determined, one of these so that the cause may be
+// checked with errors.Is.
+var (
+	// ErrObjectNotFound is the error for objects that do not exist
+	ErrObjectNotFound = errors.New("object not found")
+
+	// ErrUnsupportedType is the error for object types that can not be
+	// extracted
+	ErrUnsupportedType = errors.New("unsupported object type")
+
+	// ErrInsufficientPrivilege is the error for objects, or data
+	// dictionary views, that the user lacks the privileges to access
+	// (see Preflight)
+	ErrInsufficientPrivilege = errors.New("insufficient privileges")
+)
+
+// oraErrCauses maps the ORA errors to the cause of the failure
+var oraErrCauses = map[int]error{
+	942:   ErrInsufficientPrivilege, // table or view does not exist
+	1031:  ErrInsufficientPrivilege, // insufficient privileges
+	31600: ErrUnsupportedType,       // invalid input value for parameter OBJECT_TYPE
+	31603: ErrObjectNotFound,        // object not found in schema
+}
+
+// causeError is an error along with the cause of the error. The message
+// is that of the error alone.
+type causeError struct {
+	cause error
+	err   error
+}
+
+func (e causeError) Error() string {
+	return e.err.Error()
+}
+
+func (e causeError) Unwrap() []error {
+	return []error{e.cause, e.err}
+}
+
+// withCause adds the cause, as determined from the ORA error code, to
+// the error
+func withCause(err error) error {
+
+	if err == nil {
+		return nil
+	}
+	for _, cause := range []error{ErrObjectNotFound, ErrUnsupportedType, ErrInsufficientPrivilege} {
+		if errors.Is(err, cause) {
+			return err
+		}
+	}
+
+	if oe, ok := godror.AsOraErr(err); ok {
+		if cause, ok := oraErrCauses[oe.Code()]; ok {
+			return causeError{cause: cause, err: err}
+		}
+	}
+	return err
+}
+
+// objectError wraps the error with the object that it occurred for and
+// the cause of the error. Errors that already identify the object, and
+// the cancellation of the context, are returned as is.
+func objectError(schema, name, objType string, err error) error {
+
+	if err == nil {
+		return nil
+	}
+
+	var oe ObjectError
+	if errors.As(err, &oe) || errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	return ObjectError{Schema: schema, Name: name, ObjType: objType, Err: withCause(err)}
+}
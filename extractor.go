@@ -0,0 +1,578 @@
+package oradex
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Extractor extracts DDL from a database using a common set of options
+type Extractor struct {
+	db        Querier
+	opts      ExportOptions
+	dbVersion OracleVersion
+}
+
+// NewExtractor returns an Extractor for the database using the specified
+// options
+func NewExtractor(db Querier, opts ExportOptions) *Extractor {
+	return &Extractor{db: db, opts: opts}
+}
+
+// DB returns the database that the Extractor extracts from
+func (e *Extractor) DB() Querier {
+	return e.db
+}
+
+// Options returns the options that the Extractor was created with
+func (e *Extractor) Options() ExportOptions {
+	return e.opts
+}
+
+// Init determines the database version, which the version dependent
+// queries and transformation parameters are gated on, and initializes
+// the DBMS_METADATA transformation parameters. The version is only
+// required when a target version is specified. As the transformation
+// parameters are set for a single session, a *sql.DB should either run
+// SessionInitSQL for each new session or be pinned to a single session
+// by using a *sql.Conn.
+func (e *Extractor) Init(ctx context.Context) error {
+
+	var err error
+	e.dbVersion, err = DbVersion(ctx, e.db)
+	if err != nil && !e.opts.TargetVersion.IsZero() {
+		return err
+	}
+
+	if e.opts.Transforms != nil {
+		p := *e.opts.Transforms
+		if p.CollationClause != "" && !e.dbVersion.hasFeature(FeatureCollationClause) {
+			logger.Warn("the collation clause transform is not supported, ignoring it", "requires", features[FeatureCollationClause])
+			p.CollationClause = ""
+		}
+		return InitTransforms(ctx, e.db, p)
+	}
+
+	return InitTransforms(ctx, e.db, e.opts.transformParams())
+}
+
+// DbVersion returns the version of the database, or the zero version if
+// it could not be determined
+func (e *Extractor) DbVersion() OracleVersion {
+	return e.dbVersion
+}
+
+// ExportObject determines the type of the specified object and returns
+// the DDL for it.
+func (e *Extractor) ExportObject(ctx context.Context, schema, name string) (Object, ObjectResult, error) {
+
+	objType, err := ObjType(ctx, e.db, schema, name)
+	if err != nil {
+		return Object{Owner: schema, Name: name}, ObjectResult{Schema: schema, Name: name}, objectError(schema, name, "", err)
+	}
+	if objType == "" {
+		return Object{Owner: schema, Name: name}, ObjectResult{Schema: schema, Name: name}, objectError(schema, name, "", ErrObjectNotFound)
+	}
+
+	return e.exportObject(ctx, schema, name, objType)
+}
+
+// ExportObjectOfType returns the DDL for the specified object of the
+// specified type.
+func (e *Extractor) ExportObjectOfType(ctx context.Context, schema, name, objType string) (Object, ObjectResult, error) {
+	return e.exportObject(ctx, schema, name, objType)
+}
+
+// ExportSchema returns the DDL for each of the objects in the specified
+// schema along with a summary of the extraction.
+func (e *Extractor) ExportSchema(ctx context.Context, schema string) ([]DDLResult, ExtractionResult, error) {
+
+	var results []DDLResult
+
+	start := time.Now()
+	summary := ExtractionResult{Schema: schema}
+
+	l, err := schemaObjects(ctx, e.db, schema)
+	if err != nil {
+		summary.Elapsed = time.Since(start)
+		return results, summary, err
+	}
+
+	if e.opts.SeparateTriggers {
+		t, err := listTriggers(ctx, e.db, schema, Filter{})
+		if err != nil {
+			summary.Elapsed = time.Since(start)
+			return results, summary, err
+		}
+		for _, o := range t {
+			l = append(l, DDLResult{Schema: o.Schema, Name: o.Name, ObjType: o.ObjType})
+		}
+	}
+
+	for _, v := range l {
+		if !e.opts.IncludeObject(v.Name, v.ObjType) {
+			continue
+		}
+
+		objDDL, res, err := e.ExportDDL(ctx, v.Schema, v.Name, v.ObjType)
+		summary.Add(res, err)
+		if err != nil {
+			if ctx.Err() != nil {
+				summary.Elapsed = time.Since(start)
+				return results, summary, ctx.Err()
+			}
+			logger.Error(err.Error())
+			continue
+		}
+
+		v.DDL = objDDL
+		results = append(results, v)
+	}
+
+	summary.Elapsed = time.Since(start)
+	return results, summary, nil
+}
+
+// ExportDDL pulls together, and returns, the DDL for the specified
+// object and all *supporting* objects and grants. The returned result
+// contains any non-fatal errors encountered along with the time taken.
+func (e *Extractor) ExportDDL(ctx context.Context, schema, name, objType string) (string, ObjectResult, error) {
+
+	o, res, err := e.exportObject(ctx, schema, name, objType)
+	if err != nil {
+		return "", res, err
+	}
+
+	return o.String(), res, nil
+}
+
+// exportObject retrieves the DDL for the specified object and all
+// *supporting* objects and grants, retrying on transient errors.
+func (e *Extractor) exportObject(ctx context.Context, schema, name, objType string) (Object, ObjectResult, error) {
+
+	var o Object
+	var res ObjectResult
+
+	retried, err := e.retry(ctx, func(ctx context.Context) error {
+		var err error
+		o, res, err = e.tryExportObject(ctx, schema, name, objType)
+		return err
+	})
+	res.Warnings = append(retried, res.Warnings...)
+	if err == nil {
+		logger.Debug("extracted object", append(res.logFields(), "elapsed", res.Elapsed)...)
+	}
+	return o, res, objectError(schema, name, objType, err)
+}
+
+// tryExportObject makes one attempt at retrieving the DDL for the
+// specified object and all *supporting* objects and grants.
+func (e *Extractor) tryExportObject(ctx context.Context, schema, name, objType string) (Object, ObjectResult, error) {
+
+	var err error
+
+	db := e.db
+
+	start := time.Now()
+	res := ObjectResult{Schema: schema, Name: name, ObjType: objType}
+	o := Object{Owner: schema, Name: name, Type: objType}
+
+	if e.opts.Drop {
+		o.DropDDL = DropDDL(schema, name, objType, e.opts.DropCascade)
+	}
+
+	switch objType {
+	case typeTable, typeView, typeMaterializedView:
+		err = exportTableView(ctx, db, &o, e.opts.SeparateTriggers, &res)
+	case typeTrigger:
+		o.CreateDDL, err = ObjTrigger(ctx, db, schema, name)
+	case typeQueue:
+		o.CreateDDL, err = ObjDDL(ctx, db, schema, name, objType)
+		if err == nil {
+			subscribers, serr := ObjQueueSubscribers(ctx, db, schema, name)
+			res.note(serr)
+			if subscribers != "" {
+				o.AlterDDL = append(o.AlterDDL, subscribers)
+			}
+		}
+	case typeRole:
+		// Roles have neither needed nor object grants
+		o.CreateDDL, err = ObjRole(ctx, db, name)
+		res.Elapsed = time.Since(start)
+		return o, res, err
+	default:
+		o.CreateDDL, err = ObjDDL(ctx, db, schema, name, objType)
+	}
+	if err != nil {
+		res.Elapsed = time.Since(start)
+		return o, res, err
+	}
+
+	o.LastDDLTime, err = ObjLastDDLTime(ctx, db, schema, name, objType)
+	res.note(err)
+
+	if e.opts.NeededGrants {
+		o.withNeededGrants = true
+		o.NeededGrants, err = ObjNeededPrivList(ctx, db, schema, name, objType)
+		res.note(err)
+	}
+
+	if e.opts.ResetSequences && objType == typeSequence {
+		o.CreateDDL = ResetSequenceStart(o.CreateDDL)
+	}
+
+	if e.opts.CurrentValue && objType == typeSequence {
+		restart, err := ObjSequenceRestart(ctx, db, schema, name)
+		res.note(err)
+		if err == nil {
+			o.AlterDDL = append(o.AlterDDL, restart)
+		}
+	}
+
+	// Grants
+	if e.opts.ObjectGrants {
+		o.withGrants = true
+		o.Grants, err = ObjGrantedPrivList(ctx, db, schema, name, objType)
+		res.note(err)
+	}
+
+	// Synonyms
+	if e.opts.Synonyms && objType != typeSynonym {
+		o.Synonyms, err = objExternalSynonyms(ctx, db, schema, name, e.opts.PublicSynonyms)
+		res.note(err)
+	}
+
+	res.Elapsed = time.Since(start)
+
+	// Errors for the supporting DDL are non-fatal, cancellation is not
+	if err = ctx.Err(); err != nil {
+		return o, res, err
+	}
+
+	o.CreateDDL = e.rewrite(schema, o.CreateDDL, &res)
+	o.Indexes = e.rewrite(schema, o.Indexes, &res)
+	o.Triggers = e.rewrite(schema, o.Triggers, &res)
+	for i, cmd := range o.AlterDDL {
+		o.AlterDDL[i] = e.rewrite(schema, cmd, &res)
+	}
+	if e.opts.ConstraintState != "" && objType == typeTable {
+		o.CreateDDL = SetConstraintState(o.CreateDDL, e.opts.ConstraintState)
+		for i, cmd := range o.AlterDDL {
+			o.AlterDDL[i] = SetConstraintState(cmd, e.opts.ConstraintState)
+		}
+	}
+	e.remap(&o)
+	if e.opts.Normalize {
+		normalize(&o)
+	}
+	if e.opts.Format {
+		format(&o, e.opts.KeywordCase)
+	}
+	e.applyRules(&o)
+
+	if !e.emitSchema() {
+		o.currentSchema = remapName(schema, e.opts.RemapSchemas)
+	}
+
+	return o, res, nil
+}
+
+// ExportMetadata returns the SXML or XML metadata for the specified
+// object (see ObjMetadata).
+func (e *Extractor) ExportMetadata(ctx context.Context, schema, name, objType, metadataFormat string) (string, ObjectResult, error) {
+
+	var m string
+
+	start := time.Now()
+	res := ObjectResult{Schema: schema, Name: name, ObjType: objType}
+
+	retried, err := e.retry(ctx, func(ctx context.Context) error {
+		var err error
+		m, err = ObjMetadata(ctx, e.db, schema, name, objType, metadataFormat)
+		return err
+	})
+	res.Warnings = retried
+	res.Elapsed = time.Since(start)
+	return m, res, objectError(schema, name, objType, err)
+}
+
+// ExportGrants returns the GRANT statements for the privileges granted
+// on the specified object, regardless of whether or not the object
+// grants are otherwise included. This allows for writing the grants
+// separately from the object DDL.
+func (e *Extractor) ExportGrants(ctx context.Context, schema, name, objType string) (string, error) {
+
+	o := Object{Owner: schema, Name: name, Type: objType}
+
+	var err error
+	o.Grants, err = ObjGrantedPrivList(ctx, e.db, schema, name, objType)
+	if err != nil {
+		return "", err
+	}
+	e.remap(&o)
+
+	return grantsDDL(o.Grants), nil
+}
+
+// ExportReceivedGrants returns the GRANT statements for the privileges
+// that the specified schema has received on the objects of other
+// schemas (see SchemaReceivedPrivs), remapped as per the export options.
+func (e *Extractor) ExportReceivedGrants(ctx context.Context, schema string) (string, error) {
+
+	grants, err := SchemaReceivedPrivs(ctx, e.db, schema)
+	if err != nil {
+		return "", err
+	}
+	if len(e.opts.RemapSchemas) > 0 {
+		grants = RemapSchemas(grants, e.opts.RemapSchemas)
+	}
+
+	return grants, nil
+}
+
+// ExportSequenceRestart returns the ALTER SEQUENCE statement for
+// restarting the specified sequence at its current value (see
+// ObjSequenceRestart). This allows for writing the restart statements
+// separately from the sequence DDL.
+func (e *Extractor) ExportSequenceRestart(ctx context.Context, schema, name string) (string, error) {
+
+	restart, err := ObjSequenceRestart(ctx, e.db, schema, name)
+	if err != nil {
+		return "", err
+	}
+
+	res := ObjectResult{Schema: schema, Name: name, ObjType: typeSequence}
+	restart = e.rewrite(schema, restart, &res)
+	return RewriteDDL(restart, schema, name, typeSequence, e.opts.RewriteRules), nil
+}
+
+// ExportTableDDL returns the DDL for the specified table, or materialized
+// view, separately from the DDL for its foreign key and check
+// constraints and for its indexes so that these may be created after
+// the data has been loaded. The constraints are only separated when they
+// are generated as ALTER TABLE statements (see ExportOptions.Alter).
+func (e *Extractor) ExportTableDDL(ctx context.Context, schema, name, objType string) (string, string, string, ObjectResult, error) {
+
+	o, res, err := e.exportObject(ctx, schema, name, objType)
+	if err != nil {
+		return "", "", "", res, err
+	}
+
+	var alter, constraints []string
+	for _, cmd := range o.AlterDDL {
+		if deferredConstraintRe.MatchString(cmd) {
+			constraints = append(constraints, cmd)
+		} else {
+			alter = append(alter, cmd)
+		}
+	}
+	o.AlterDDL = alter
+
+	indexes := o.Indexes
+	o.Indexes = ""
+
+	return o.String(), strings.Join(constraints, dblSpace()), trimString(indexes), res, nil
+}
+
+// ExportPackageDDL returns the DDL for the specification and the body of
+// the specified package separately. The needed and object grants are
+// included with the specification. Should the body not be retrievable
+// (wrapped, missing, etc.) then a warning is issued and an empty body is
+// returned.
+func (e *Extractor) ExportPackageDDL(ctx context.Context, schema, name string) (string, string, ObjectResult, error) {
+	return e.exportSpecBody(ctx, schema, name, typePackage)
+}
+
+// ExportTypeDDL returns the DDL for the specification and the body of
+// the specified object type separately so that the specification may be
+// deployed before any dependent tables and the body afterwards. The
+// needed and object grants are included with the specification. Types
+// without a body return an empty body.
+func (e *Extractor) ExportTypeDDL(ctx context.Context, schema, name string) (string, string, ObjectResult, error) {
+	return e.exportSpecBody(ctx, schema, name, typeType)
+}
+
+// exportSpecBody returns the DDL for the specification and the body of
+// the specified package or type separately, retrying on transient
+// errors
+func (e *Extractor) exportSpecBody(ctx context.Context, schema, name, objType string) (string, string, ObjectResult, error) {
+
+	var spec, body string
+	var res ObjectResult
+
+	retried, err := e.retry(ctx, func(ctx context.Context) error {
+		var err error
+		spec, body, res, err = e.tryExportSpecBody(ctx, schema, name, objType)
+		return err
+	})
+	res.Warnings = append(retried, res.Warnings...)
+	return spec, body, res, objectError(schema, name, objType, err)
+}
+
+// tryExportSpecBody makes one attempt at retrieving the DDL for the
+// specification and the body of the specified package or type
+func (e *Extractor) tryExportSpecBody(ctx context.Context, schema, name, objType string) (string, string, ObjectResult, error) {
+
+	var l []string
+
+	db := e.db
+
+	start := time.Now()
+	res := ObjectResult{Schema: schema, Name: name, ObjType: objType}
+
+	specDDL, err := ObjDDL(ctx, db, schema, name, objType+"_SPEC")
+	if err != nil {
+		res.Elapsed = time.Since(start)
+		return "", "", res, err
+	}
+
+	if e.opts.Drop {
+		l = appendLine(l, DropDDL(schema, name, objType, e.opts.DropCascade))
+	}
+
+	if e.opts.NeededGrants {
+		grants, err := ObjNeededPrivs(ctx, db, schema, name, objType)
+		res.note(err)
+		l = appendLine(l, grants)
+	}
+
+	l = appendLine(l, specDDL)
+
+	if e.opts.ObjectGrants {
+		grants, err := ObjGrantedPrivs(ctx, db, schema, name, objType)
+		res.note(err)
+		l = appendLine(l, grants)
+	}
+
+	var bodyDDL string
+	hasBody := true
+	if objType == typeType {
+		// Unlike packages, most types do not have a body
+		t, err := ObjLastDDLTime(ctx, db, schema, name, typeType+" BODY")
+		res.note(err)
+		hasBody = !t.IsZero()
+	}
+
+	if hasBody {
+		bodyDDL, err = ObjDDL(ctx, db, schema, name, objType+"_BODY")
+		if err != nil {
+			res.note(fmt.Errorf("skipping %s body for %q.%q: %s", strings.ToLower(objType), schema, name, err))
+			bodyDDL = ""
+		}
+	}
+
+	var synonyms string
+	if e.opts.Synonyms {
+		synonyms, err = objExternalSynonyms(ctx, db, schema, name, e.opts.PublicSynonyms)
+		res.note(err)
+	}
+
+	res.Elapsed = time.Since(start)
+
+	specDDL = e.rewrite(schema, strings.Join(l, dblSpace()), &res)
+	bodyDDL = e.rewrite(schema, bodyDDL, &res)
+
+	// The synonyms are owned by other schemas so are remapped but not
+	// stripped
+	if synonyms != "" {
+		specDDL = trimLine(specDDL) + dblSpace() + RemapSchemas(synonyms, e.opts.RemapSchemas)
+	}
+	if e.opts.Normalize {
+		specDDL = NormalizeDDL(specDDL, objType)
+		bodyDDL = NormalizeDDL(bodyDDL, objType)
+	}
+	if e.opts.Format {
+		specDDL = FormatDDL(specDDL, objType, e.opts.KeywordCase)
+		bodyDDL = FormatDDL(bodyDDL, objType+" BODY", e.opts.KeywordCase)
+	}
+	specDDL = RewriteDDL(specDDL, schema, name, objType, e.opts.RewriteRules)
+	bodyDDL = RewriteDDL(bodyDDL, schema, name, objType, e.opts.RewriteRules)
+
+	if !e.emitSchema() {
+		specDDL = currentSchemaDDL(remapName(schema, e.opts.RemapSchemas)) + dblSpace() + specDDL
+		if bodyDDL != "" {
+			bodyDDL = currentSchemaDDL(remapName(schema, e.opts.RemapSchemas)) + dblSpace() + bodyDDL
+		}
+	}
+	return specDDL, bodyDDL, res, nil
+}
+
+// rewrite downgrades the DDL to the target version, strips the schema
+// from the names of the objects in the schema, and remaps the schemas
+// and tablespaces, if any of these were specified
+func (e *Extractor) rewrite(schema, objDDL string, res *ObjectResult) string {
+
+	objDDL = e.downgrade(objDDL, res)
+	if !e.emitSchema() {
+		objDDL = StripSchema(objDDL, schema)
+	}
+	if len(e.opts.RemapSchemas) > 0 {
+		objDDL = RemapSchemas(objDDL, e.opts.RemapSchemas)
+	}
+	if len(e.opts.RemapTablespaces) > 0 {
+		objDDL = RemapTablespaces(objDDL, e.opts.RemapTablespaces)
+	}
+	return objDDL
+}
+
+// remap strips and remaps the schemas for the parts of the object that
+// are not downgraded. The schema is not stripped from the synonyms as
+// these are owned by other schemas.
+func (e *Extractor) remap(o *Object) {
+
+	if !e.emitSchema() {
+		o.DropDDL = StripSchema(o.DropDDL, o.Owner)
+		o.Comments = StripSchema(o.Comments, o.Owner)
+		o.ColumnComments = StripSchema(o.ColumnComments, o.Owner)
+		for i := range o.Grants {
+			if o.Grants[i].Schema == o.Owner {
+				o.Grants[i].Schema = ""
+			}
+		}
+	}
+
+	m := e.opts.RemapSchemas
+	if len(m) == 0 {
+		return
+	}
+
+	o.DropDDL = RemapSchemas(o.DropDDL, m)
+	o.Synonyms = RemapSchemas(o.Synonyms, m)
+	o.Comments = RemapSchemas(o.Comments, m)
+	o.ColumnComments = RemapSchemas(o.ColumnComments, m)
+	for _, l := range [][]Grant{o.NeededGrants, o.Grants} {
+		for i := range l {
+			if l[i].Schema != "" {
+				l[i].Schema = remapName(l[i].Schema, m)
+			}
+			l[i].Grantee = remapName(l[i].Grantee, m)
+		}
+	}
+}
+
+// emitSchema returns false if the schema is to be omitted from the
+// names of the objects in the DDL
+func (e *Extractor) emitSchema() bool {
+	if e.opts.Transforms != nil {
+		return e.opts.Transforms.EmitSchema
+	}
+	return e.opts.EmitSchema
+}
+
+// downgrade downgrades the DDL to the target version, if one was
+// specified, and records any warnings
+func (e *Extractor) downgrade(objDDL string, res *ObjectResult) string {
+
+	if e.opts.TargetVersion.IsZero() || objDDL == "" {
+		return objDDL
+	}
+
+	objDDL, warnings, err := DowngradeDDL(objDDL, e.dbVersion, e.opts.TargetVersion)
+	res.note(err)
+	for _, w := range warnings {
+		res.warn(w)
+	}
+
+	return objDDL
+}
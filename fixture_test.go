@@ -0,0 +1,175 @@
+package oradex
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fixture is a recorded query result. A query is answered by the first
+// fixture whose Match is contained in the query, ignoring differences in
+// white-space, and whose Args, if any, are those of the query. The value
+// of a single row, single column result may instead be read from File
+// (i.e. the DDL returned by DBMS_METADATA).
+type fixture struct {
+	Match   string          `json:"match"`
+	Args    []interface{}   `json:"args"`
+	Columns []string        `json:"columns"`
+	Rows    [][]interface{} `json:"rows"`
+	File    string          `json:"file"`
+}
+
+// openFixtures returns a database handle that answers the queries with
+// the fixtures recorded in the testdata/dictionary file, so that the
+// queries may be tested without an Oracle instance
+func openFixtures(t *testing.T, name string) *sql.DB {
+
+	t.Helper()
+
+	b, err := ioutil.ReadFile(filepath.Join("testdata", "dictionary", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var l []fixture
+	err = json.Unmarshal(b, &l)
+	if err != nil {
+		t.Fatalf("%s: %s", name, err)
+	}
+
+	for i, f := range l {
+		if f.File == "" {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join("testdata", filepath.FromSlash(f.File)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		l[i].Columns = []string{"DDL"}
+		l[i].Rows = [][]interface{}{{string(b)}}
+	}
+
+	db := sql.OpenDB(fixtureConnector(l))
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// fixtureConnector is the driver.Connector for the fixtures
+type fixtureConnector []fixture
+
+func (c fixtureConnector) Connect(context.Context) (driver.Conn, error) {
+	return fixtureConn(c), nil
+}
+
+func (c fixtureConnector) Driver() driver.Driver {
+	return fixtureDriver{}
+}
+
+// fixtureDriver is only needed to satisfy driver.Connector
+type fixtureDriver struct{}
+
+func (fixtureDriver) Open(string) (driver.Conn, error) {
+	return nil, fmt.Errorf("fixtures can only be opened with openFixtures")
+}
+
+// fixtureConn answers the queries made on the connection
+type fixtureConn []fixture
+
+func (c fixtureConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("fixtures do not support prepared statements")
+}
+
+func (c fixtureConn) Close() error {
+	return nil
+}
+
+func (c fixtureConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("fixtures do not support transactions")
+}
+
+// CheckNamedValue accepts the godror options (i.e. godror.LobAsReader)
+// along with the ordinary query arguments
+func (c fixtureConn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+func (c fixtureConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+
+	q := strings.Join(strings.Fields(query), " ")
+
+	for _, f := range c {
+		if !strings.Contains(q, strings.Join(strings.Fields(f.Match), " ")) || !f.matchArgs(args) {
+			continue
+		}
+		return &fixtureRows{columns: f.Columns, rows: f.Rows}, nil
+	}
+
+	return nil, fmt.Errorf("no fixture for the query %q %v", q, args)
+}
+
+// matchArgs returns true if the fixture has no arguments or if they are
+// those of the query, other than any godror options
+func (f fixture) matchArgs(args []driver.NamedValue) bool {
+
+	if len(f.Args) == 0 {
+		return true
+	}
+
+	var l []string
+	for _, a := range args {
+		switch v := a.Value.(type) {
+		case string, int, int64, float64:
+			l = append(l, fmt.Sprint(v))
+		}
+	}
+
+	if len(l) != len(f.Args) {
+		return false
+	}
+	for i, a := range f.Args {
+		if fmt.Sprint(a) != l[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// fixtureRows are the rows of a fixture
+type fixtureRows struct {
+	columns []string
+	rows    [][]interface{}
+	i       int
+}
+
+func (r *fixtureRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fixtureRows) Close() error {
+	return nil
+}
+
+func (r *fixtureRows) Next(dest []driver.Value) error {
+
+	if r.i >= len(r.rows) {
+		return io.EOF
+	}
+
+	for i, v := range r.rows[r.i] {
+		// JSON numbers are all float64
+		if f, ok := v.(float64); ok && f == float64(int64(f)) {
+			v = int64(f)
+		}
+		dest[i] = v
+	}
+	r.i++
+
+	return nil
+}
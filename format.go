@@ -0,0 +1,398 @@
+package oradex
+
+import (
+	"regexp"
+	"strings"
+)
+
+// The kinds of the tokens of the SQL that is formatted by FormatDDL
+const (
+	tkOther = iota
+	tkSpace
+	tkWord
+	tkQuoted
+	tkLiteral
+	tkComment
+)
+
+// sqlToken is a token of SQL text
+type sqlToken struct {
+	kind int
+	text string
+}
+
+// sourceTypes are the object types whose DDL is PL/SQL, or Java, source
+// that FormatDDL leaves as written
+var sourceTypes = map[string]bool{
+	"FUNCTION":     true,
+	"JAVA SOURCE":  true,
+	typePackage:    true,
+	"PACKAGE BODY": true,
+	"PROCEDURE":    true,
+	typeTrigger:    true,
+	typeType:       true,
+	"TYPE BODY":    true,
+}
+
+// statementKeywords are the keywords that start the statements of the
+// DDL generated by DBMS_METADATA
+var statementKeywords = map[string]bool{
+	"ALTER":   true,
+	"COMMENT": true,
+	"CREATE":  true,
+	"DROP":    true,
+	"GRANT":   true,
+}
+
+// sqlKeywords are the keywords that FormatDDL sets the case of
+var sqlKeywords = make(map[string]bool)
+
+func init() {
+	for _, k := range strings.Fields(`
+ADD ALL ALTER ALWAYS AND ANY AS ASC BEGIN BETWEEN BLOB BUFFER_POOL BUILD BY BYTE CACHE CASCADE
+CASE CELL_FLASH_CACHE CHAR CHECK CLOB COLUMN COMMENT COMPLETE COMPRESS COMPUTE CONSTRAINT CREATE
+CREATION CROSS CYCLE DATE DEFAULT DEFERRABLE DEFERRED DELETE DEMAND DESC DISABLE DISTINCT DROP
+EDITIONABLE ELSE ENABLE END EXISTS FAST FLASH_CACHE FLOAT FOR FORCE FOREIGN FREELIST FREELISTS
+FROM FULL GENERATED GLOBAL GRANT GROUP GROUPS HAVING IDENTITY IMMEDIATE IN INCREMENT INDEX
+INITIAL INITIALLY INITRANS INNER INSERT INTEGER INTERSECT INTERVAL INTO IS JOIN KEEP KEY LEFT
+LIKE LOGGING LONG MATERIALIZED MAXEXTENTS MAXTRANS MAXVALUE MINEXTENTS MINUS MINVALUE NCHAR
+NCLOB NEXT NOCACHE NOCOMPRESS NOCYCLE NOKEEP NOLOGGING NOMAXVALUE NOMINVALUE NONEDITIONABLE
+NOORDER NOPARALLEL NOSCALE NOT NOVALIDATE NULL NUMBER NVARCHAR2 ON OR ORDER ORGANIZATION OUTER
+PARALLEL PARTITION PCTFREE PCTINCREASE PCTUSED PRIMARY PUBLIC QUERY RANGE RAW REFERENCES
+REFRESH RELY REPLACE REWRITE RIGHT SEGMENT SELECT SEQUENCE SET START STORAGE SUBPARTITION
+STATISTICS SYNONYM TABLE TABLESPACE TEMPORARY THEN TIMESTAMP TO TRIGGER UNION UNIQUE UPDATE USING
+VALIDATE VALUES VARCHAR2 VIEW WHEN WHERE WITH ZONE
+`) {
+		sqlKeywords[k] = true
+	}
+}
+
+// sourceHeaderRe matches the indented CREATE and ALTER statements of the
+// DDL for PL/SQL source
+var sourceHeaderRe = regexp.MustCompile(`(?m)^[ \t]+(CREATE OR REPLACE |ALTER TRIGGER )`)
+
+// FormatDDL reformats the DDL for an object to be easier to read and to
+// compare. The statements are no longer indented, trailing white-space
+// is removed, tabs in the indentation are replaced with spaces, the
+// columns and constraints of CREATE TABLE statements are listed one per
+// line, and the clauses following the column list are indented. If
+// keywordCase is "upper" or "lower" then the SQL keywords, other than
+// those in quoted names, literals, and comments, are set to that case.
+// The DDL for PL/SQL and Java source is not reformatted beyond removing
+// the indentation of the CREATE statements.
+func FormatDDL(objDDL, objType, keywordCase string) string {
+
+	if objDDL == "" {
+		return objDDL
+	}
+	if sourceTypes[objType] {
+		return sourceHeaderRe.ReplaceAllString(objDDL, "${1}")
+	}
+
+	setCase := func(t sqlToken) string {
+		if t.kind != tkWord || !sqlKeywords[strings.ToUpper(t.text)] {
+			return t.text
+		}
+		switch keywordCase {
+		case "upper":
+			return strings.ToUpper(t.text)
+		case "lower":
+			return strings.ToLower(t.text)
+		}
+		return t.text
+	}
+
+	toks := tokenizeSQL(objDDL)
+
+	var b strings.Builder
+	depth := 0
+	stmtStart := true // the next word starts a statement
+	inCreate := false // in a CREATE statement, before any TABLE keyword
+	inTail := false   // after the column list of a CREATE TABLE statement
+
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+
+		switch t.kind {
+		case tkSpace:
+			startsStmt := false
+			if i+1 < len(toks) && toks[i+1].kind == tkWord && depth == 0 {
+				startsStmt = stmtStart || statementKeywords[strings.ToUpper(toks[i+1].text)]
+			}
+			k := strings.LastIndexByte(t.text, '\n')
+			if k < 0 {
+				if i == 0 && startsStmt {
+					continue
+				}
+				b.WriteString(t.text)
+				continue
+			}
+
+			for _, l := range splitLines(t.text[:k]) {
+				b.WriteString(trimLine(l) + "\n")
+			}
+			switch {
+			case startsStmt:
+				stmtStart = true
+			case inTail && depth == 0:
+				b.WriteString("    ")
+			default:
+				b.WriteString(strings.Replace(t.text[k+1:], "\t", "    ", -1))
+			}
+
+		case tkWord:
+			word := strings.ToUpper(t.text)
+			if stmtStart {
+				stmtStart = false
+				inCreate = word == "CREATE"
+				inTail = false
+			}
+			b.WriteString(setCase(t))
+
+			if inCreate && word == "TABLE" && depth == 0 {
+				inCreate = false
+				name, open, close := tableColumnList(toks, i+1)
+				if open < 0 {
+					continue
+				}
+
+				b.WriteString(" " + name + " (\n")
+				var cols []string
+				for _, l := range splitTopLevel(toks[open+1 : close]) {
+					var col strings.Builder
+					for _, c := range l {
+						if c.kind == tkSpace {
+							col.WriteString(" ")
+						} else {
+							col.WriteString(setCase(c))
+						}
+					}
+					if s := strings.TrimSpace(col.String()); s != "" {
+						cols = append(cols, "    "+s)
+					}
+				}
+				b.WriteString(strings.Join(cols, ",\n") + "\n)")
+
+				i = close
+				inTail = true
+				if i+1 < len(toks) && toks[i+1].kind == tkSpace && !strings.Contains(toks[i+1].text, "\n") {
+					if i+2 < len(toks) && toks[i+2].text != ";" {
+						b.WriteString("\n    ")
+					}
+					i++
+				}
+			}
+
+		case tkOther:
+			switch t.text {
+			case "(":
+				depth++
+			case ")":
+				depth--
+			case ";":
+				if depth == 0 {
+					stmtStart = true
+				}
+			}
+			b.WriteString(t.text)
+
+		default:
+			b.WriteString(t.text)
+		}
+	}
+
+	return b.String()
+}
+
+// tableColumnList returns the name of the table and the positions of the
+// parentheses of the column list of the CREATE TABLE statement whose
+// name starts at the token at position i. The positions are -1 if the
+// statement has no column list, or if the list contains comments.
+func tableColumnList(toks []sqlToken, i int) (name string, open, close int) {
+
+	skipSpace := func() {
+		for i < len(toks) && toks[i].kind == tkSpace {
+			i++
+		}
+	}
+	isName := func() bool {
+		return i < len(toks) && (toks[i].kind == tkWord || toks[i].kind == tkQuoted)
+	}
+
+	skipSpace()
+	if !isName() {
+		return "", -1, -1
+	}
+	name = toks[i].text
+	i++
+	if i+1 < len(toks) && toks[i].text == "." {
+		i++
+		if !isName() {
+			return "", -1, -1
+		}
+		name += "." + toks[i].text
+		i++
+	}
+	skipSpace()
+	if i >= len(toks) || toks[i].text != "(" {
+		return "", -1, -1
+	}
+
+	open = i
+	depth := 0
+	for ; i < len(toks); i++ {
+		switch {
+		case toks[i].kind == tkComment:
+			return "", -1, -1
+		case toks[i].text == "(":
+			depth++
+		case toks[i].text == ")":
+			depth--
+			if depth == 0 {
+				return name, open, i
+			}
+		}
+	}
+	return "", -1, -1
+}
+
+// splitTopLevel splits the tokens on the commas that are not enclosed in
+// parentheses
+func splitTopLevel(toks []sqlToken) [][]sqlToken {
+
+	var l [][]sqlToken
+	depth := 0
+	start := 0
+
+	for i, t := range toks {
+		switch t.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case ",":
+			if depth == 0 && t.kind == tkOther {
+				l = append(l, toks[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(l, toks[start:])
+}
+
+// tokenizeSQL splits SQL text into words, quoted names, literals,
+// comments, runs of white-space, and single characters of anything else
+func tokenizeSQL(s string) []sqlToken {
+
+	var l []sqlToken
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		j := i + 1
+		kind := tkOther
+
+		switch {
+		case isSpace(c):
+			for j < len(s) && isSpace(s[j]) {
+				j++
+			}
+			kind = tkSpace
+		case strings.HasPrefix(s[i:], "--"):
+			j = len(s)
+			if k := strings.IndexByte(s[i:], '\n'); k >= 0 {
+				j = i + k
+			}
+			kind = tkComment
+		case strings.HasPrefix(s[i:], "/*"):
+			j = len(s)
+			if k := strings.Index(s[i+2:], "*/"); k >= 0 {
+				j = i + 2 + k + 2
+			}
+			kind = tkComment
+		case c == '"':
+			j = closingQuote(s, i, '"')
+			kind = tkQuoted
+		case c == '\'':
+			j = closingQuote(s, i, '\'')
+			kind = tkLiteral
+		case isWordChar(c):
+			for j < len(s) && isWordChar(s[j]) {
+				j++
+			}
+			kind = tkWord
+			// alternative quoting, i.e. q'[...]'
+			w := strings.ToUpper(s[i:j])
+			if (w == "Q" || w == "NQ") && j+1 < len(s) && s[j] == '\'' {
+				j = closingAltQuote(s, j)
+				kind = tkLiteral
+			}
+		}
+
+		l = append(l, sqlToken{kind: kind, text: s[i:j]})
+		i = j
+	}
+
+	return l
+}
+
+// closingQuote returns the position following the quote that closes the
+// quoted text starting at position i, allowing for doubled quotes
+func closingQuote(s string, i int, q byte) int {
+	for k := i + 1; k < len(s); k++ {
+		if s[k] == q {
+			if k+1 < len(s) && s[k+1] == q {
+				k++
+				continue
+			}
+			return k + 1
+		}
+	}
+	return len(s)
+}
+
+// closingAltQuote returns the position following the end of the
+// alternatively quoted literal whose opening quote is at position i
+func closingAltQuote(s string, i int) int {
+
+	d := s[i+1]
+	switch d {
+	case '[':
+		d = ']'
+	case '{':
+		d = '}'
+	case '(':
+		d = ')'
+	case '<':
+		d = '>'
+	}
+
+	if k := strings.Index(s[i+2:], string(d)+"'"); k >= 0 {
+		return i + 2 + k + 2
+	}
+	return len(s)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+func isWordChar(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '$' || c == '#' || c >= 0x80
+}
+
+// format formats the DDL for the parts of the object (see FormatDDL)
+func format(o *Object, keywordCase string) {
+
+	o.DropDDL = FormatDDL(o.DropDDL, "", keywordCase)
+	o.CreateDDL = FormatDDL(o.CreateDDL, o.Type, keywordCase)
+	o.Indexes = FormatDDL(o.Indexes, "", keywordCase)
+	o.Comments = FormatDDL(o.Comments, "", keywordCase)
+	o.ColumnComments = FormatDDL(o.ColumnComments, "", keywordCase)
+	o.Triggers = FormatDDL(o.Triggers, typeTrigger, keywordCase)
+	o.Synonyms = FormatDDL(o.Synonyms, "", keywordCase)
+	for i, cmd := range o.AlterDDL {
+		o.AlterDDL[i] = FormatDDL(cmd, "", keywordCase)
+	}
+}
@@ -0,0 +1,61 @@
+package oradex
+
+import (
+	"testing"
+)
+
+func TestFormatDDL(t *testing.T) {
+
+	tests := []struct {
+		ddl         string
+		objType     string
+		keywordCase string
+		want        string
+	}{
+		{"objddl/app.orders.table.sql", typeTable, "", "format/app.orders.table.sql"},
+		{"objddl/app.orders.table.sql", typeTable, "lower", "format/app.orders.table.lower.sql"},
+		{"objddl/app.order_seq.sequence.sql", typeSequence, "lower", "format/app.order_seq.sequence.lower.sql"},
+		{"objddl/app.open_orders.view.sql", typeView, "lower", "format/app.open_orders.view.lower.sql"},
+		{"objddl/app.order_totals.mview.sql", typeMaterializedView, "", "format/app.order_totals.mview.sql"},
+		{"objddl/app.order_api.package.sql", typePackage, "lower", "format/app.order_api.package.sql"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got := FormatDDL(readFixture(t, tt.ddl), tt.objType, tt.keywordCase)
+			if want := readFixture(t, tt.want); got != want {
+				t.Errorf("differs:\n%s", UnifiedDiff("want", "got", want, got, 3))
+			}
+
+			// Formatting is idempotent
+			if again := FormatDDL(got, tt.objType, tt.keywordCase); again != got {
+				t.Errorf("formatted again:\n%s", UnifiedDiff("once", "twice", got, again, 3))
+			}
+		})
+	}
+}
+
+func TestFormatDDLKeywordCase(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		ddl         string
+		keywordCase string
+		want        string
+	}{
+		{"as is", `create view "V" as select 1 from dual`, "", `create view "V" as select 1 from dual`},
+		{"upper", `create view "V" as select 1 from dual`, "upper", `CREATE VIEW "V" AS SELECT 1 FROM dual`},
+		{"quoted names", `CREATE VIEW "select" AS SELECT "FROM" FROM t`, "lower", `create view "select" as select "FROM" from t`},
+		{"literals", `CREATE VIEW v AS SELECT 'SELECT' FROM t`, "lower", `create view v as select 'SELECT' from t`},
+		{"alternative quoting", `CREATE VIEW v AS SELECT q'[ A 'SELECT' ]' FROM t`, "lower", `create view v as select q'[ A 'SELECT' ]' from t`},
+		{"comments", "CREATE VIEW v AS SELECT 1 -- SELECT\n FROM t /* FROM */", "lower", "create view v as select 1 -- SELECT\n from t /* FROM */"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatDDL(tt.ddl, typeView, tt.keywordCase); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
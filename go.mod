@@ -3,12 +3,15 @@ module github.com/gsiems/oradex
 go 1.20
 
 require (
+	github.com/BurntSushi/toml v1.3.2
 	github.com/godror/godror v0.22.2
 	github.com/gsiems/orapass v1.0.0
+	golang.org/x/term v0.15.0
 )
 
 require (
 	github.com/go-logfmt/logfmt v0.5.0 // indirect
 	github.com/phayes/permbits v0.0.0-20190612203442-39d7c581d2ee // indirect
+	golang.org/x/sys v0.15.0 // indirect
 	golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543 // indirect
 )
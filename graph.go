@@ -0,0 +1,156 @@
+package oradex
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// GraphEdge is a dependency of one object on another. Kind is either
+// "dependency", for the dependencies recorded in dba_dependencies, or
+// "fk" for the foreign keys between tables.
+type GraphEdge struct {
+	From QualifiedName
+	To   QualifiedName
+	Kind string
+}
+
+// SchemaGraph returns the dependencies, and the foreign keys, of the
+// objects in the specified schema on the (non-system) objects in the
+// database. Dependencies of package and type bodies are attributed to
+// the package or type.
+func SchemaGraph(ctx context.Context, db Querier, schema string) ([]GraphEdge, error) {
+
+	var edges []GraphEdge
+
+	queries := map[string]string{
+		"dependency": fmt.Sprintf(`
+SELECT DISTINCT owner,
+        name,
+        referenced_owner,
+        referenced_name
+    FROM dba_dependencies
+    WHERE owner = :1
+        AND referenced_type IN (
+                'DATABASE LINK', 'FUNCTION', 'MATERIALIZED VIEW', 'PACKAGE', 'PROCEDURE', 'SEQUENCE', 'SYNONYM',
+                'TABLE', 'TYPE', 'VIEW' )
+        AND referenced_owner <> 'PUBLIC'
+        AND %s
+        AND NOT ( referenced_owner = owner
+            AND referenced_name = name )
+    ORDER BY owner,
+        name,
+        referenced_owner,
+        referenced_name
+`, ExcludedSchemaClause(ctx, "referenced_owner")),
+		"fk": `
+SELECT DISTINCT c.owner,
+        c.table_name,
+        p.owner,
+        p.table_name
+    FROM dba_constraints c
+    JOIN dba_constraints p
+        ON ( p.owner = c.r_owner
+            AND p.constraint_name = c.r_constraint_name )
+    WHERE c.owner = :1
+        AND c.constraint_type = 'R'
+        AND c.table_name NOT LIKE 'BIN$%'
+    ORDER BY c.owner,
+        c.table_name,
+        p.owner,
+        p.table_name
+`,
+	}
+
+	for _, kind := range []string{"dependency", "fk"} {
+		l, err := queryEdges(ctx, db, queries[kind], kind, schema)
+		if err != nil {
+			return edges, err
+		}
+		edges = append(edges, l...)
+	}
+
+	return edges, nil
+}
+
+// queryEdges returns the graph edges selected by a query
+func queryEdges(ctx context.Context, db Querier, query, kind, schema string) ([]GraphEdge, error) {
+
+	var l []GraphEdge
+
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, query), schema)
+	if err != nil {
+		return l, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	for rows.Next() {
+		e := GraphEdge{Kind: kind}
+		err = rows.Scan(&e.From.Schema, &e.From.Name, &e.To.Schema, &e.To.Name)
+		if err != nil {
+			return l, err
+		}
+		l = append(l, e)
+	}
+
+	return l, err
+}
+
+// WriteDOT writes the graph to the writer in the Graphviz DOT language.
+// Foreign keys are drawn as dashed edges.
+func WriteDOT(w io.Writer, edges []GraphEdge) error {
+
+	var l []string
+	l = append(l, "digraph dependencies {", "    rankdir=LR;", "    node [shape=box];")
+	for _, e := range edges {
+		attrs := ""
+		if e.Kind == "fk" {
+			attrs = " [style=dashed, label=\"fk\"]"
+		}
+		l = append(l, fmt.Sprintf("    %s -> %s%s;", dotID(e.From), dotID(e.To), attrs))
+	}
+	l = append(l, "}")
+
+	_, err := io.WriteString(w, strings.Join(l, "\n")+"\n")
+	return err
+}
+
+// dotID returns the quoted DOT identifier for an object
+func dotID(q QualifiedName) string {
+	return `"` + strings.Replace(q.Schema+"."+q.Name, `"`, `\"`, -1) + `"`
+}
+
+// WriteMermaid writes the graph to the writer as a Mermaid flowchart.
+// Foreign keys are drawn as dotted edges.
+func WriteMermaid(w io.Writer, edges []GraphEdge) error {
+
+	ids := make(map[QualifiedName]string)
+
+	var l []string
+	l = append(l, "graph LR")
+
+	node := func(q QualifiedName) string {
+		if id, ok := ids[q]; ok {
+			return id
+		}
+		id := fmt.Sprintf("n%d", len(ids)+1)
+		ids[q] = id
+		return fmt.Sprintf("%s[\"%s\"]", id, strings.Replace(q.Schema+"."+q.Name, `"`, "#quot;", -1))
+	}
+
+	for _, e := range edges {
+		arrow := "-->"
+		if e.Kind == "fk" {
+			arrow = "-.->|fk|"
+		}
+		l = append(l, fmt.Sprintf("    %s %s %s", node(e.From), arrow, node(e.To)))
+	}
+
+	_, err := io.WriteString(w, strings.Join(l, "\n")+"\n")
+	return err
+}
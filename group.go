@@ -0,0 +1,201 @@
+package oradex
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// ExportObjectGroup exports the DDL for a named set of objects, along
+// with the objects that they depend on, and writes the DDL to the
+// _group directory under baseDir. Objects are exported in dependency
+// order and the files are numbered so that they sort in that order. The
+// group is written to a temporary directory that only replaces any
+// existing _group directory once all of the objects have been exported,
+// so that neither a partial group nor the files of a previous group are
+// left behind.
+func ExportObjectGroup(ctx context.Context, db Querier, objects []QualifiedName, baseDir string, opts ExportOptions) ([]DDLResult, error) {
+
+	var results []DDLResult
+
+	ordered, err := dependencyOrder(ctx, db, objects)
+	if err != nil {
+		return results, err
+	}
+
+	// Initializing the transformation parameters applies the session
+	// level options (i.e. EmitSchema) to the extracted DDL
+	e := NewExtractor(db, opts)
+	err = e.Init(ctx)
+	if err != nil {
+		return results, err
+	}
+
+	dir := filepath.Join(baseDir, "_group")
+	err = os.MkdirAll(baseDir, 0700)
+	if err != nil {
+		return results, err
+	}
+
+	tmp, err := ioutil.TempDir(baseDir, "._group.*.tmp")
+	if err != nil {
+		return results, err
+	}
+	defer os.RemoveAll(tmp)
+
+	for i, v := range ordered {
+
+		objType, err := ObjType(ctx, db, v.Schema, v.Name)
+		if err != nil {
+			return results, err
+		}
+		if objType == "" {
+			logger.Warn("no object found", "schema", v.Schema, "name", v.Name)
+			continue
+		}
+
+		objDDL, _, err := e.ExportDDL(ctx, v.Schema, v.Name, objType)
+		if err != nil {
+			return results, err
+		}
+
+		filename := fmt.Sprintf("%04d_%s.%s.sql", i+1, SafeFileName(v.Schema), SafeFileName(v.Name))
+		err = ioutil.WriteFile(filepath.Join(tmp, filename), []byte(objDDL+"\n\n"), 0600)
+		if err != nil {
+			return results, err
+		}
+
+		results = append(results, DDLResult{
+			Schema:   v.Schema,
+			Name:     v.Name,
+			ObjType:  objType,
+			DDL:      objDDL,
+			Filename: filepath.Join(dir, filename),
+		})
+	}
+
+	err = replaceDir(tmp, dir)
+	if err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+// OrderByDependency returns the supplied objects ordered such that each
+// object follows the objects in the list that it depends on. Unlike
+// ExportObjectGroup, the objects that are depended on but are not in the
+// list are not included.
+func OrderByDependency(ctx context.Context, db Querier, objects []QualifiedName) ([]QualifiedName, error) {
+
+	var l []QualifiedName
+
+	ordered, err := dependencyOrder(ctx, db, objects)
+	if err != nil {
+		return l, err
+	}
+
+	wanted := make(map[QualifiedName]bool)
+	for _, q := range objects {
+		wanted[q] = true
+	}
+
+	for _, q := range ordered {
+		if wanted[q] {
+			l = append(l, q)
+		}
+	}
+
+	return l, nil
+}
+
+// dependencyOrder returns the dependency closure of the supplied objects
+// ordered such that each object follows the objects it depends on.
+// Circular dependencies are broken at the first object revisited.
+func dependencyOrder(ctx context.Context, db Querier, objects []QualifiedName) ([]QualifiedName, error) {
+
+	var ordered []QualifiedName
+	visited := make(map[QualifiedName]bool)
+
+	var visit func(q QualifiedName) error
+	visit = func(q QualifiedName) error {
+		if visited[q] {
+			return nil
+		}
+		visited[q] = true
+
+		deps, err := ObjDependencies(ctx, db, q.Schema, q.Name)
+		if err != nil {
+			return err
+		}
+		for _, d := range deps {
+			err = visit(d)
+			if err != nil {
+				return err
+			}
+		}
+
+		ordered = append(ordered, q)
+		return nil
+	}
+
+	for _, q := range objects {
+		err := visit(q)
+		if err != nil {
+			return ordered, err
+		}
+	}
+
+	return ordered, nil
+}
+
+// DependencyClosure returns the supplied objects along with all of the
+// (non-system) objects that they depend on, directly or otherwise,
+// ordered such that each object follows the objects it depends on. This
+// allows for extracting a self-contained script for an object.
+func DependencyClosure(ctx context.Context, db Querier, objects []QualifiedName) ([]QualifiedName, error) {
+	return dependencyOrder(ctx, db, objects)
+}
+
+// Dependents returns the supplied objects along with all of the
+// (non-system) objects that depend on them, directly or otherwise,
+// ordered such that each object follows the objects it depends on. This
+// allows for assessing, and regenerating, the objects affected by a
+// change to the supplied objects.
+func Dependents(ctx context.Context, db Querier, objects []QualifiedName) ([]QualifiedName, error) {
+
+	var l []QualifiedName
+	visited := make(map[QualifiedName]bool)
+
+	var visit func(q QualifiedName) error
+	visit = func(q QualifiedName) error {
+		if visited[q] {
+			return nil
+		}
+		visited[q] = true
+		l = append(l, q)
+
+		deps, err := ObjDependents(ctx, db, q.Schema, q.Name)
+		if err != nil {
+			return err
+		}
+		for _, d := range deps {
+			err = visit(d)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, q := range objects {
+		err := visit(q)
+		if err != nil {
+			return l, err
+		}
+	}
+
+	return OrderByDependency(ctx, db, l)
+}
@@ -0,0 +1,139 @@
+package oradex
+
+import (
+	"context"
+)
+
+// InstallOrder returns the objects ordered such that each object follows
+// the objects in the list that it depends on, to include the tables that
+// its foreign keys reference, regardless of the schemas of the objects
+// (see SchemaGraph). This allows for creating the objects of several
+// schemas in one pass. Circular dependencies are broken at the first
+// object revisited.
+func InstallOrder(ctx context.Context, db Querier, objects []ObjectEntry) ([]ObjectEntry, error) {
+
+	edges, err := installEdges(ctx, db, objects)
+	if err != nil {
+		return nil, err
+	}
+
+	return orderByGraph(objects, edges), nil
+}
+
+// InstallOrderBySchema returns the objects ordered as for InstallOrder
+// but grouped by schema, with each schema following the schemas that
+// its objects depend on. This allows for applying the grants of each
+// schema, once all of its objects are created, before the objects of
+// the schemas that depend on them. Circular dependencies between the
+// schemas are broken at the first schema revisited.
+func InstallOrderBySchema(ctx context.Context, db Querier, objects []ObjectEntry) ([]ObjectEntry, error) {
+
+	edges, err := installEdges(ctx, db, objects)
+	if err != nil {
+		return nil, err
+	}
+
+	return orderBySchema(orderByGraph(objects, edges), edges), nil
+}
+
+// orderBySchema groups the ordered objects by schema, with the schemas
+// ordered such that each schema follows the schemas that it depends on
+// as determined by the graph edges
+func orderBySchema(ordered []ObjectEntry, edges []GraphEdge) []ObjectEntry {
+
+	bySchema := make(map[string][]ObjectEntry)
+	var schemas []string
+	for _, o := range ordered {
+		if _, ok := bySchema[o.Schema]; !ok {
+			schemas = append(schemas, o.Schema)
+		}
+		bySchema[o.Schema] = append(bySchema[o.Schema], o)
+	}
+
+	deps := make(map[string][]string)
+	for _, e := range edges {
+		if e.From.Schema != e.To.Schema {
+			deps[e.From.Schema] = append(deps[e.From.Schema], e.To.Schema)
+		}
+	}
+
+	var l []ObjectEntry
+	visited := make(map[string]bool)
+
+	var visit func(schema string)
+	visit = func(schema string) {
+		if visited[schema] {
+			return
+		}
+		visited[schema] = true
+		for _, d := range deps[schema] {
+			visit(d)
+		}
+		l = append(l, bySchema[schema]...)
+	}
+	for _, schema := range schemas {
+		visit(schema)
+	}
+
+	return l
+}
+
+// installEdges returns the dependency graph edges of the schemas of the
+// objects
+func installEdges(ctx context.Context, db Querier, objects []ObjectEntry) ([]GraphEdge, error) {
+
+	var edges []GraphEdge
+	seen := make(map[string]bool)
+
+	for _, o := range objects {
+		if seen[o.Schema] {
+			continue
+		}
+		seen[o.Schema] = true
+
+		l, err := SchemaGraph(ctx, db, o.Schema)
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, l...)
+	}
+
+	return edges, nil
+}
+
+// orderByGraph orders the objects such that each object follows the
+// objects in the list that it depends on as determined by the graph
+// edges
+func orderByGraph(objects []ObjectEntry, edges []GraphEdge) []ObjectEntry {
+
+	deps := make(map[QualifiedName][]QualifiedName)
+	for _, e := range edges {
+		deps[e.From] = append(deps[e.From], e.To)
+	}
+
+	byName := make(map[QualifiedName][]ObjectEntry)
+	for _, o := range objects {
+		q := QualifiedName{Schema: o.Schema, Name: o.Name}
+		byName[q] = append(byName[q], o)
+	}
+
+	var ordered []ObjectEntry
+	visited := make(map[QualifiedName]bool)
+
+	var visit func(q QualifiedName)
+	visit = func(q QualifiedName) {
+		if visited[q] {
+			return
+		}
+		visited[q] = true
+		for _, d := range deps[q] {
+			visit(d)
+		}
+		ordered = append(ordered, byName[q]...)
+	}
+	for _, o := range objects {
+		visit(QualifiedName{Schema: o.Schema, Name: o.Name})
+	}
+
+	return ordered
+}
@@ -0,0 +1,113 @@
+package oradex
+
+import (
+	"context"
+	"time"
+)
+
+// InventoryItem describes an object in a schema without its DDL. The
+// status and times of package and type bodies are combined with those
+// of the specification: the object is INVALID if either is, and Lines is
+// the number of lines of source for both.
+type InventoryItem struct {
+	Schema      string    `json:"schema"`
+	Name        string    `json:"name"`
+	ObjType     string    `json:"type"`
+	Status      string    `json:"status"`
+	Created     time.Time `json:"created"`
+	LastDDLTime time.Time `json:"last_ddl_time"`
+	Lines       int64     `json:"lines"`
+}
+
+// SchemaInventory returns the inventory of the objects in the specified
+// schema, keyed by the object name and type (see InventoryKey)
+func SchemaInventory(ctx context.Context, db Querier, schema string) (map[string]InventoryItem, error) {
+
+	m := make(map[string]InventoryItem)
+
+	query := `
+WITH p AS (
+    SELECT :1 AS owner
+        FROM dual
+),
+src AS (
+    SELECT s.name,
+            CASE s.type
+                WHEN 'PACKAGE BODY' THEN 'PACKAGE'
+                WHEN 'TYPE BODY' THEN 'TYPE'
+                ELSE s.type
+                END AS object_type,
+            count (*) AS lines
+        FROM dba_source s
+        JOIN p
+            ON ( p.owner = s.owner )
+        GROUP BY s.name,
+            CASE s.type
+                WHEN 'PACKAGE BODY' THEN 'PACKAGE'
+                WHEN 'TYPE BODY' THEN 'TYPE'
+                ELSE s.type
+                END
+),
+objs AS (
+    SELECT o.owner,
+            o.object_name,
+            CASE o.object_type
+                WHEN 'PACKAGE BODY' THEN 'PACKAGE'
+                WHEN 'TYPE BODY' THEN 'TYPE'
+                ELSE o.object_type
+                END AS object_type,
+            max ( CASE WHEN o.status = 'VALID' THEN 0 ELSE 1 END ) AS invalid,
+            min ( o.created ) AS created,
+            max ( o.last_ddl_time ) AS last_ddl_time
+        FROM dba_objects o
+        JOIN p
+            ON ( p.owner = o.owner )
+        WHERE o.object_name NOT LIKE 'BIN$%'
+        GROUP BY o.owner,
+            o.object_name,
+            CASE o.object_type
+                WHEN 'PACKAGE BODY' THEN 'PACKAGE'
+                WHEN 'TYPE BODY' THEN 'TYPE'
+                ELSE o.object_type
+                END
+)
+SELECT o.owner,
+        o.object_name,
+        o.object_type,
+        CASE WHEN o.invalid = 1 THEN 'INVALID' ELSE 'VALID' END AS status,
+        o.created,
+        o.last_ddl_time,
+        coalesce ( s.lines, 0 ) AS lines
+    FROM objs o
+    LEFT JOIN src s
+        ON ( s.name = o.object_name
+            AND s.object_type = o.object_type )
+`
+
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, query), schema)
+	if err != nil {
+		return m, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	for rows.Next() {
+		var v InventoryItem
+		err = rows.Scan(&v.Schema, &v.Name, &v.ObjType, &v.Status, &v.Created, &v.LastDDLTime, &v.Lines)
+		if err != nil {
+			return m, err
+		}
+		m[InventoryKey(v.Name, v.ObjType)] = v
+	}
+
+	return m, err
+}
+
+// InventoryKey returns the key for an object in the map returned by
+// SchemaInventory
+func InventoryKey(name, objType string) string {
+	return objType + "\t" + name
+}
@@ -0,0 +1,275 @@
+package oradex
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// objectTypeList is the list, for the IN clause of the object queries,
+// of the types of objects that may be extracted
+const objectTypeList = `'CHAIN', 'DATABASE LINK', 'FUNCTION', 'JOB', 'MATERIALIZED VIEW', 'PACKAGE', 'PROCEDURE',
+                'PROGRAM', 'QUEUE', 'SCHEDULE', 'SEQUENCE', 'SYNONYM', 'TABLE', 'TYPE', 'VIEW'`
+
+// Filter limits the schemas, and the objects in them, that are listed
+type Filter struct {
+	// Schemas is the list of schemas to list. If empty then all of the
+	// schemas that are not excluded are listed.
+	Schemas []string
+	// ExcludeSchemas is the list of schemas to not list. Ignored if
+	// Schemas is specified.
+	ExcludeSchemas []string
+	// ExcludeSystemSchemas excludes the Oracle supplied schemas (see
+	// ExcludedSchemas)
+	ExcludeSystemSchemas bool
+
+	// ObjectTypes is the list of object types to list. If empty then
+	// all types that are not excluded are listed.
+	ObjectTypes []string
+	// ExcludeObjectTypes is the list of object types to not list
+	ExcludeObjectTypes []string
+	// IncludeNames is the list of patterns that the object names must
+	// match one of (see CompileNamePatterns). If empty then all names
+	// that are not excluded are listed.
+	IncludeNames []*regexp.Regexp
+	// ExcludeNames is the list of patterns for the object names to not
+	// list
+	ExcludeNames []*regexp.Regexp
+	// SeparateTriggers lists the triggers owned by the schema as
+	// objects in their own right rather than as part of their tables
+	SeparateTriggers bool
+}
+
+// IncludeSchema returns true if the schema is to be listed
+func (f Filter) IncludeSchema(schema string) bool {
+	if len(f.Schemas) > 0 {
+		return containsName(f.Schemas, schema)
+	}
+	return !containsName(f.ExcludeSchemas, schema)
+}
+
+// IncludeObject returns true if the object, by type and name, is to be
+// listed
+func (f Filter) IncludeObject(name, objType string) bool {
+
+	if !f.includeType(objType) {
+		return false
+	}
+	if len(f.IncludeNames) > 0 && !matchesAny(f.IncludeNames, name) {
+		return false
+	}
+	return !matchesAny(f.ExcludeNames, name)
+}
+
+// includeType returns true if the object type is to be listed
+func (f Filter) includeType(objType string) bool {
+	if len(f.ObjectTypes) > 0 && !containsType(f.ObjectTypes, objType) {
+		return false
+	}
+	return !containsType(f.ExcludeObjectTypes, objType)
+}
+
+// containsName returns true if the name is in the list of names
+func containsName(l []string, name string) bool {
+	for _, v := range l {
+		if trimString(v) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ObjectEntry identifies an object that may be extracted. DirName is the
+// object type as used for the name of the directory that the object is
+// written to.
+type ObjectEntry struct {
+	Schema  string `json:"schema"`
+	Name    string `json:"name"`
+	ObjType string `json:"type"`
+	DirName string `json:"-"`
+}
+
+// ListSchemas returns the list of the schemas that own objects that may
+// be extracted, as limited by the filter
+func ListSchemas(ctx context.Context, db Querier, f Filter) ([]string, error) {
+
+	var l []string
+
+	query := `
+SELECT DISTINCT owner
+    FROM dba_objects
+    WHERE object_type IN ( ` + objectTypeList + ` )
+        AND object_type <> 'SYNONYM'
+`
+	if f.ExcludeSystemSchemas {
+		query += fmt.Sprintf("        AND %s\n", ExcludedSchemaClause(ctx, "owner"))
+	}
+
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, query))
+	if err != nil {
+		return l, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	for rows.Next() {
+		var schema string
+		err = rows.Scan(&schema)
+		if err != nil {
+			return l, err
+		}
+		if f.IncludeSchema(schema) {
+			l = append(l, schema)
+		}
+	}
+
+	return l, err
+}
+
+// ListSchemas returns the list of the schemas that own objects that may
+// be extracted, limited to the specified schemas (if any) and excluding
+// the excluded schemas and, if ExcludeSystemSchemas is set, the system
+// schemas
+func (e *Extractor) ListSchemas(ctx context.Context, schemas, exclude []string) ([]string, error) {
+
+	f := Filter{
+		Schemas:              schemas,
+		ExcludeSchemas:       exclude,
+		ExcludeSystemSchemas: e.opts.ExcludeSystemSchemas,
+	}
+
+	return ListSchemas(ctx, e.db, f)
+}
+
+// ListObjects returns the list of the objects in the specified schema
+// that may be extracted, as limited by the filter. Package and type
+// bodies are not listed separately from their specifications, and the
+// tables of queue tables are listed as QUEUE TABLE objects.
+func ListObjects(ctx context.Context, db Querier, schema string, f Filter) ([]ObjectEntry, error) {
+	return listObjectsAt(ctx, db, schema, "", f)
+}
+
+// listObjectsAt returns the list of the objects, as for ListObjects, in
+// the database at the other end of the database link, or in the
+// connected database if no link is specified. Separate triggers are
+// always listed from the connected database.
+func listObjectsAt(ctx context.Context, db Querier, schema, link string, f Filter) ([]ObjectEntry, error) {
+
+	var l []ObjectEntry
+
+	query := `
+WITH objs AS (
+    SELECT o.owner,
+            o.object_name,
+            CASE
+                WHEN o.object_type = 'TABLE'
+                    AND EXISTS (
+                        SELECT 1
+                            FROM dba_queue_tables q
+                            WHERE q.owner = o.owner
+                                AND q.queue_table = o.object_name ) THEN 'QUEUE TABLE'
+                ELSE o.object_type
+                END AS object_type,
+            row_number () OVER (
+                PARTITION BY o.owner, o.object_name
+                ORDER BY CASE
+                        WHEN object_type = 'MATERIALIZED VIEW' THEN 1
+                        WHEN object_type = 'PACKAGE' THEN 1
+                        WHEN object_type = 'TYPE' THEN 1
+                        WHEN object_type = 'TABLE' THEN 2
+                        WHEN object_type = 'VIEW' THEN 3
+                        WHEN object_type = 'SEQUENCE' THEN 4
+                        ELSE 10
+                        END ) AS rn
+        FROM dba_objects o
+        WHERE object_type IN ( ` + objectTypeList + ` )
+            AND object_name NOT LIKE 'SYS_PLSQL%'
+            AND object_name <> 'CREATE$JAVA$LOB$TABLE'
+            -- exclude the tables and views generated for queue tables
+            AND object_name NOT LIKE 'AQ$%'
+)
+SELECT owner,
+        object_name,
+        object_type
+    FROM objs
+    WHERE owner = :1
+        AND rn = 1
+    ORDER BY object_type,
+        object_name
+`
+
+	rows, err := db.QueryContext(ctx, atLink(DictQuery(ctx, query), link), schema)
+	if err != nil {
+		return l, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	for rows.Next() {
+		var o ObjectEntry
+		err = rows.Scan(&o.Schema, &o.Name, &o.ObjType)
+		if err != nil {
+			return l, err
+		}
+		if f.IncludeObject(o.Name, o.ObjType) {
+			o.DirName = strings.Replace(o.ObjType, " ", "_", -1)
+			l = append(l, o)
+		}
+	}
+
+	if f.SeparateTriggers && f.includeType(typeTrigger) {
+		t, err := listTriggers(ctx, db, schema, f)
+		if err != nil {
+			return l, err
+		}
+		l = append(l, t...)
+	}
+
+	return l, nil
+}
+
+// listTriggers returns the list of triggers owned by the specified
+// schema, regardless of the schema of the table that they are on
+func listTriggers(ctx context.Context, db Querier, schema string, f Filter) ([]ObjectEntry, error) {
+
+	var l []ObjectEntry
+
+	query := `
+SELECT owner,
+        trigger_name
+    FROM dba_triggers
+    WHERE owner = :1
+        AND trigger_name NOT LIKE 'BIN$%'
+    ORDER BY trigger_name
+`
+
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, query), schema)
+	if err != nil {
+		return l, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	for rows.Next() {
+		o := ObjectEntry{ObjType: typeTrigger, DirName: typeTrigger}
+		err = rows.Scan(&o.Schema, &o.Name)
+		if err != nil {
+			return l, err
+		}
+		if f.IncludeObject(o.Name, o.ObjType) {
+			l = append(l, o)
+		}
+	}
+
+	return l, err
+}
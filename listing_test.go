@@ -0,0 +1,88 @@
+package oradex
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestListObjects(t *testing.T) {
+
+	db := openFixtures(t, "app.json")
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		f    Filter
+		want []string
+	}{
+		{
+			"all",
+			context.Background(),
+			Filter{},
+			[]string{
+				"MATERIALIZED_VIEW/ORDER_TOTALS", "PACKAGE/ORDER_API", "QUEUE_TABLE/ORDER_EVENTS_QT",
+				"SEQUENCE/ORDER_SEQ", "TABLE/CUSTOMERS", "TABLE/ORDERS", "TABLE/ORDERS_BKP", "VIEW/OPEN_ORDERS",
+			},
+		},
+		{
+			"types",
+			context.Background(),
+			Filter{ObjectTypes: []string{"TABLE", "VIEW"}},
+			[]string{"TABLE/CUSTOMERS", "TABLE/ORDERS", "TABLE/ORDERS_BKP", "VIEW/OPEN_ORDERS"},
+		},
+		{
+			"excluded types",
+			context.Background(),
+			Filter{ExcludeObjectTypes: []string{"TABLE", "QUEUE TABLE", "MATERIALIZED VIEW"}},
+			[]string{"PACKAGE/ORDER_API", "SEQUENCE/ORDER_SEQ", "VIEW/OPEN_ORDERS"},
+		},
+		{
+			"names",
+			context.Background(),
+			Filter{
+				IncludeNames: []*regexp.Regexp{regexp.MustCompile(`^ORDER`)},
+				ExcludeNames: []*regexp.Regexp{regexp.MustCompile(`_BKP$`), regexp.MustCompile(`_QT$`)},
+			},
+			[]string{"MATERIALIZED_VIEW/ORDER_TOTALS", "PACKAGE/ORDER_API", "SEQUENCE/ORDER_SEQ", "TABLE/ORDERS"},
+		},
+		{
+			"separate triggers",
+			context.Background(),
+			Filter{ObjectTypes: []string{"TABLE", "TRIGGER"}, SeparateTriggers: true},
+			[]string{"TABLE/CUSTOMERS", "TABLE/ORDERS", "TABLE/ORDERS_BKP", "TRIGGER/ORDERS_BIU"},
+		},
+		{
+			"separate triggers excluded",
+			context.Background(),
+			Filter{ObjectTypes: []string{"TABLE"}, SeparateTriggers: true},
+			[]string{"TABLE/CUSTOMERS", "TABLE/ORDERS", "TABLE/ORDERS_BKP"},
+		},
+		{
+			"all views",
+			WithDictionary(context.Background(), Dictionary{AllViews: true}),
+			Filter{},
+			[]string{"TABLE/ORDERS", "VIEW/OPEN_ORDERS"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := ListObjects(tt.ctx, db, "APP", tt.f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, o := range l {
+				if o.Schema != "APP" {
+					t.Errorf("%s.%s is not in APP", o.Schema, o.Name)
+				}
+				got = append(got, o.DirName+"/"+o.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package oradex
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Logger is the interface for the logging done while extracting. The
+// methods take a message followed by alternating key/value pairs of
+// context fields and match those of *slog.Logger, so that a slog logger
+// may be used by way of SetLogger.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// Level is the minimum level of the messages that are logged
+type Level int
+
+// Log levels
+const (
+	LevelDebug Level = iota - 1
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelOff
+)
+
+// String returns the name of the level
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	}
+	return "OFF"
+}
+
+// ParseLevel parses a level name (error, warn, info, debug, or off)
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(trimString(s)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	case "off", "none":
+		return LevelOff, nil
+	}
+	return LevelOff, fmt.Errorf("unknown log level %q", s)
+}
+
+// logger is the logger used by the package
+var logger Logger = NewLogger(os.Stderr, LevelInfo, false)
+
+// SetLogger sets the logger used by the package
+func SetLogger(l Logger) {
+	logger = l
+}
+
+// GetLogger returns the logger used by the package
+func GetLogger() Logger {
+	return logger
+}
+
+// streamLogger writes the messages at or above a level to a writer as
+// either logfmt style text or JSON lines
+type streamLogger struct {
+	mu    sync.Mutex
+	w     io.Writer
+	level Level
+	json  bool
+}
+
+// NewLogger returns a logger that writes the messages at or above the
+// level to the writer, one per line, as either logfmt style text or, if
+// asJSON is set, as JSON objects
+func NewLogger(w io.Writer, level Level, asJSON bool) Logger {
+	return &streamLogger{w: w, level: level, json: asJSON}
+}
+
+// Debug logs a message at the debug level
+func (l *streamLogger) Debug(msg string, args ...interface{}) {
+	l.log(LevelDebug, msg, args)
+}
+
+// Info logs a message at the info level
+func (l *streamLogger) Info(msg string, args ...interface{}) {
+	l.log(LevelInfo, msg, args)
+}
+
+// Warn logs a message at the warn level
+func (l *streamLogger) Warn(msg string, args ...interface{}) {
+	l.log(LevelWarn, msg, args)
+}
+
+// Error logs a message at the error level
+func (l *streamLogger) Error(msg string, args ...interface{}) {
+	l.log(LevelError, msg, args)
+}
+
+func (l *streamLogger) log(level Level, msg string, args []interface{}) {
+
+	if level < l.level {
+		return
+	}
+
+	now := time.Now().Format(time.RFC3339)
+
+	// Pair up the context fields, a trailing key without a value is
+	// logged with the key "!BADKEY" as slog does
+	var keys []string
+	var values []interface{}
+	for i := 0; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			keys = append(keys, "!BADKEY")
+			values = append(values, args[i])
+			break
+		}
+		keys = append(keys, fmt.Sprint(args[i]))
+		values = append(values, args[i+1])
+	}
+
+	var b strings.Builder
+	if l.json {
+		m := map[string]interface{}{"time": now, "level": level.String(), "msg": msg}
+		for i, k := range keys {
+			v := values[i]
+			if err, ok := v.(error); ok {
+				v = err.Error()
+			}
+			m[k] = v
+		}
+		j, err := json.Marshal(m)
+		if err != nil {
+			j, _ = json.Marshal(map[string]interface{}{"time": now, "level": level.String(), "msg": msg})
+		}
+		b.Write(j)
+	} else {
+		fmt.Fprintf(&b, "time=%s level=%s msg=%s", now, level, logfmtValue(msg))
+		for i, k := range keys {
+			fmt.Fprintf(&b, " %s=%s", k, logfmtValue(fmt.Sprint(values[i])))
+		}
+	}
+	b.WriteByte('\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	io.WriteString(l.w, b.String())
+}
+
+// logfmtValue quotes a value if it is empty or contains spaces, quotes,
+// or equals signs
+func logfmtValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\r\n\"=") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
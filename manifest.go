@@ -0,0 +1,68 @@
+package oradex
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// ManifestFile is the name of the manifest file that is written to the
+// base directory of an extraction
+const ManifestFile = "manifest.json"
+
+// Manifest lists the files written by extractions along with the
+// checksum of each so that downstream pipelines may verify the files and
+// determine the changes between snapshots
+type Manifest struct {
+	Created time.Time       `json:"created"`
+	Objects []ManifestEntry `json:"objects"`
+}
+
+// ManifestEntry is a file written for an object. File is relative to the
+// base directory and uses forward slashes. Extracted is the time that the
+// object was extracted at.
+type ManifestEntry struct {
+	Schema      string    `json:"schema"`
+	Name        string    `json:"name"`
+	ObjType     string    `json:"type"`
+	File        string    `json:"file"`
+	SHA256      string    `json:"sha256"`
+	LastDDLTime time.Time `json:"last_ddl_time"`
+	Extracted   time.Time `json:"extracted"`
+}
+
+// Checksum returns the hex encoded SHA-256 checksum of the data
+func Checksum(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// ReadManifest reads a manifest file. A missing file is an empty
+// manifest.
+func ReadManifest(filename string) (Manifest, error) {
+
+	var m Manifest
+
+	b, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return m, err
+	}
+
+	err = json.Unmarshal(b, &m)
+	return m, err
+}
+
+// Marshal returns the manifest as indented JSON
+func (m Manifest) Marshal() ([]byte, error) {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return b, err
+	}
+	return append(b, '\n'), nil
+}
@@ -0,0 +1,110 @@
+package oradex
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// the START WITH clause of a sequence, which DBMS_METADATA sets to
+	// the next value of the sequence
+	seqStartRe = regexp.MustCompile(`(?i)\s+START\s+WITH\s+-?\d+`)
+
+	seqIncrementRe = regexp.MustCompile(`(?i)\bINCREMENT\s+BY\s+(-?\d+)`)
+	seqMinRe       = regexp.MustCompile(`(?i)\bMINVALUE\s+(-?\d+)`)
+	seqMaxRe       = regexp.MustCompile(`(?i)\bMAXVALUE\s+(-?\d+)`)
+
+	// the START WITH clause of a materialized view refresh, which
+	// DBMS_METADATA sets to the time of the next refresh
+	mviewStartRe = regexp.MustCompile(`(?is)(\bREFRESH\b[^;]*?)\s+START\s+WITH\s+(TO_DATE\s*\([^)]*\)|SYSDATE\s*\+\s*0)`)
+)
+
+// NormalizeDDL removes the volatile parts of the DDL for an object so
+// that extracting the same object twice yields the same DDL even when
+// the object has been used in between. Line endings are converted to
+// *nix line endings, trailing white-space is removed from each line,
+// runs of blank lines are reduced to a single blank line, the START WITH
+// clause is removed from sequences, and the next refresh time is removed
+// from materialized views. The white-space within literals and quoted
+// names is left as is, as is the DDL for PL/SQL and Java source.
+func NormalizeDDL(objDDL, objType string) string {
+
+	if objDDL == "" || sourceTypes[objType] {
+		return objDDL
+	}
+
+	switch objType {
+	case typeSequence:
+		objDDL = seqStartRe.ReplaceAllString(objDDL, "")
+	case typeMaterializedView:
+		objDDL = mviewStartRe.ReplaceAllString(objDDL, "${1}")
+	}
+
+	var b strings.Builder
+	for _, t := range tokenizeSQL(objDDL) {
+		switch t.kind {
+		case tkSpace:
+			t.text = normalizeSpace(t.text)
+		case tkComment:
+			if strings.HasPrefix(t.text, "--") {
+				t.text = trimLine(t.text)
+			}
+		}
+		b.WriteString(t.text)
+	}
+
+	return strings.TrimRight(b.String(), "\t\r ")
+}
+
+// normalizeSpace normalizes a run of white-space: any trailing
+// white-space before each line ending is removed, line endings are
+// converted to *nix line endings, and no more than one blank line is
+// kept.
+func normalizeSpace(s string) string {
+
+	l := splitLines(s)
+	if len(l) == 1 {
+		return s
+	}
+
+	n := len(l) - 1
+	if n > 2 {
+		n = 2
+	}
+	return strings.Repeat("\n", n) + l[len(l)-1]
+}
+
+// ResetSequenceStart rewrites the START WITH clause of the DDL for a
+// sequence, which DBMS_METADATA sets to the next value of the sequence,
+// to start the sequence at its MINVALUE or, for descending sequences, at
+// its MAXVALUE so that the DDL reflects the original definition of the
+// sequence rather than its current state.
+func ResetSequenceStart(objDDL string) string {
+
+	start := "1"
+	if m := seqIncrementRe.FindStringSubmatch(objDDL); m != nil && strings.HasPrefix(m[1], "-") {
+		start = "-1"
+		if m := seqMaxRe.FindStringSubmatch(objDDL); m != nil {
+			start = m[1]
+		}
+	} else if m := seqMinRe.FindStringSubmatch(objDDL); m != nil {
+		start = m[1]
+	}
+
+	return seqStartRe.ReplaceAllString(objDDL, " START WITH "+start)
+}
+
+// normalize normalizes the DDL for the parts of the object (see
+// NormalizeDDL)
+func normalize(o *Object) {
+
+	o.CreateDDL = NormalizeDDL(o.CreateDDL, o.Type)
+	o.Indexes = NormalizeDDL(o.Indexes, "")
+	o.Comments = NormalizeDDL(o.Comments, "")
+	o.ColumnComments = NormalizeDDL(o.ColumnComments, "")
+	o.Triggers = NormalizeDDL(o.Triggers, typeTrigger)
+	o.Synonyms = NormalizeDDL(o.Synonyms, "")
+	for i, cmd := range o.AlterDDL {
+		o.AlterDDL[i] = NormalizeDDL(cmd, "")
+	}
+}
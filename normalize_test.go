@@ -0,0 +1,97 @@
+package oradex
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeDDL(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		ddl     string
+		objType string
+		want    string
+	}{
+		{"empty", "", typeTable, ""},
+		{
+			"sequence start",
+			"\n   CREATE SEQUENCE  \"APP\".\"ORDER_SEQ\"  MINVALUE 100 MAXVALUE 9999999999999999999999999999 INCREMENT BY 1 START WITH 4821 CACHE 20 NOORDER  NOCYCLE  NOKEEP  NOSCALE  GLOBAL \n",
+			typeSequence,
+			"\n   CREATE SEQUENCE  \"APP\".\"ORDER_SEQ\"  MINVALUE 100 MAXVALUE 9999999999999999999999999999 INCREMENT BY 1 CACHE 20 NOORDER  NOCYCLE  NOKEEP  NOSCALE  GLOBAL\n",
+		},
+		{
+			"negative sequence start",
+			`CREATE SEQUENCE "S" INCREMENT BY -1 START WITH -42 NOCACHE`,
+			typeSequence,
+			`CREATE SEQUENCE "S" INCREMENT BY -1 NOCACHE`,
+		},
+		{
+			"mview next refresh",
+			"CREATE MATERIALIZED VIEW \"MV\"\n  REFRESH COMPLETE ON DEMAND START WITH TO_DATE('2026-10-18 02:00:00', 'YYYY-MM-DD HH24:MI:SS') NEXT SYSDATE + 1\n  AS SELECT 1 FROM dual",
+			typeMaterializedView,
+			"CREATE MATERIALIZED VIEW \"MV\"\n  REFRESH COMPLETE ON DEMAND NEXT SYSDATE + 1\n  AS SELECT 1 FROM dual",
+		},
+		{
+			"crlf and trailing white-space",
+			"CREATE TABLE \"T\" \r\n   ( \"C\" NUMBER )\t\r\n",
+			typeTable,
+			"CREATE TABLE \"T\"\n   ( \"C\" NUMBER )\n",
+		},
+		{
+			"blank lines",
+			"GRANT SELECT ON \"T\" TO \"A\"\n\n\n\n  GRANT SELECT ON \"T\" TO \"B\"",
+			typeTable,
+			"GRANT SELECT ON \"T\" TO \"A\"\n\n  GRANT SELECT ON \"T\" TO \"B\"",
+		},
+		{
+			"literals",
+			"COMMENT ON TABLE \"T\" IS 'a  \n\n\n\nb'  \n",
+			"",
+			"COMMENT ON TABLE \"T\" IS 'a  \n\n\n\nb'\n",
+		},
+		{
+			"line comment",
+			"SELECT 1 -- one   \n  FROM dual",
+			typeView,
+			"SELECT 1 -- one\n  FROM dual",
+		},
+		{
+			"source",
+			"CREATE OR REPLACE PACKAGE \"P\" AS\n    s VARCHAR2 ( 10 ) := 'a  \n\n\nb' ;\nEND ;  \n",
+			typePackage,
+			"CREATE OR REPLACE PACKAGE \"P\" AS\n    s VARCHAR2 ( 10 ) := 'a  \n\n\nb' ;\nEND ;  \n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeDDL(tt.ddl, tt.objType); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResetSequenceStart(t *testing.T) {
+
+	tests := []struct {
+		name string
+		ddl  string
+		want string
+	}{
+		{"ascending", `CREATE SEQUENCE "S" MINVALUE 100 MAXVALUE 9999 INCREMENT BY 1 START WITH 4821 CACHE 20`, "START WITH 100 "},
+		{"descending", `CREATE SEQUENCE "S" MINVALUE 1 MAXVALUE 1000 INCREMENT BY -1 START WITH 977 NOCACHE`, "START WITH 1000 "},
+		{"no min value", `CREATE SEQUENCE "S" INCREMENT BY 5 START WITH 85`, "START WITH 1"},
+		{"descending, no max value", `CREATE SEQUENCE "S" INCREMENT BY -5 START WITH 85`, "START WITH -1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResetSequenceStart(tt.ddl)
+			if !strings.Contains(got, tt.want) || strings.Count(got, "START WITH") != 1 {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
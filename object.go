@@ -0,0 +1,244 @@
+package oradex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Grant is a privilege grant on an object
+type Grant struct {
+	Privileges []string `json:"privileges"`
+	Schema     string   `json:"schema"`
+	ObjectName string   `json:"object_name"`
+	Grantee    string   `json:"grantee"`
+	Grantable  bool     `json:"grantable"`
+}
+
+// String returns the GRANT statement for the grant
+func (g Grant) String() string {
+
+	s := fmt.Sprintf("GRANT %s ON \"%s\".\"%s\" TO \"%s\"", strings.Join(g.Privileges, ", "), g.Schema, g.ObjectName, g.Grantee)
+	if g.Schema == "" {
+		s = fmt.Sprintf("GRANT %s ON \"%s\" TO \"%s\"", strings.Join(g.Privileges, ", "), g.ObjectName, g.Grantee)
+	}
+	if g.Grantable {
+		return s + " WITH GRANT OPTION ;"
+	}
+	return s + " ;"
+}
+
+// grantsDDL returns the GRANT statements for a list of grants
+func grantsDDL(grants []Grant) string {
+	var l []string
+	for _, g := range grants {
+		l = appendLine(l, g.String())
+	}
+	return strings.Join(l, dblSpace())
+}
+
+// queryGrants returns the grants selected by a query
+func queryGrants(ctx context.Context, db Querier, query, schema, name string) ([]Grant, error) {
+
+	var l []Grant
+
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, query), schema, name)
+	if err != nil {
+		return l, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	for rows.Next() {
+		var g Grant
+		var privs, grantable string
+		err = rows.Scan(&privs, &g.Schema, &g.ObjectName, &g.Grantee, &grantable)
+		if err != nil {
+			return l, err
+		}
+		g.Privileges = strings.Split(privs, ", ")
+		g.Grantable = grantable == "YES"
+		l = append(l, g)
+	}
+
+	return l, err
+}
+
+// Object contains the DDL, and the DDL for the supporting objects and
+// grants, for a database object.
+type Object struct {
+	Owner          string
+	Name           string
+	Type           string
+	LastDDLTime    time.Time
+	DropDDL        string
+	CreateDDL      string
+	AlterDDL       []string
+	Indexes        string
+	Comments       string
+	ColumnComments string
+	Triggers       string
+	NeededGrants   []Grant
+	Grants         []Grant
+	Synonyms       string
+
+	// whether or not the grants were requested, so that String renders
+	// the same sections that ExportDDL always has
+	withNeededGrants bool
+	withGrants       bool
+
+	// the schema to set as the current schema, if the schema is omitted
+	// from the object names
+	currentSchema string
+}
+
+// String renders the object as the concatenated DDL returned by ExportDDL
+func (o Object) String() string {
+
+	head, tail := o.sections()
+
+	l := head
+	switch o.Type {
+	case typeTable, typeView, typeMaterializedView:
+		var t []string
+		t = appendLine(t, o.CreateDDL)
+		if o.Type != typeView {
+			t = appendLine(t, o.Indexes)
+		}
+		for _, cmd := range o.AlterDDL {
+			t = appendLine(t, cmd)
+		}
+		t = appendLine(t, o.Comments)
+		t = appendLine(t, o.ColumnComments)
+		t = appendLine(t, o.Triggers)
+		l = appendLine(l, strings.Join(t, dblSpace()))
+	default:
+		l = appendLine(l, o.CreateDDL)
+	}
+	l = append(l, tail...)
+
+	return strings.Join(l, dblSpace())
+}
+
+// sections returns the DDL that String renders before, and after, that
+// of the object itself. For objects other than tables and views the
+// ALTER commands follow the object DDL.
+func (o Object) sections() (head, tail []string) {
+
+	if o.currentSchema != "" {
+		head = appendLine(head, currentSchemaDDL(o.currentSchema))
+	}
+
+	if o.DropDDL != "" {
+		head = appendLine(head, o.DropDDL)
+	}
+
+	if o.withNeededGrants {
+		head = appendLine(head, grantsDDL(o.NeededGrants))
+	}
+
+	switch o.Type {
+	case typeTable, typeView, typeMaterializedView:
+	default:
+		for _, cmd := range o.AlterDDL {
+			tail = appendLine(tail, cmd)
+		}
+	}
+
+	if o.withGrants {
+		tail = appendLine(tail, grantsDDL(o.Grants))
+	}
+
+	if o.Synonyms != "" {
+		tail = appendLine(tail, o.Synonyms)
+	}
+
+	return head, tail
+}
+
+// MarshalJSON renders the object as a JSON document containing the
+// owner, name, type, DDL (as rendered by String), grants, and the time
+// that DDL was last applied to the object.
+func (o Object) MarshalJSON() ([]byte, error) {
+
+	doc := struct {
+		Owner        string     `json:"owner"`
+		Name         string     `json:"name"`
+		Type         string     `json:"type"`
+		DDL          string     `json:"ddl"`
+		Grants       []Grant    `json:"grants"`
+		NeededGrants []Grant    `json:"needed_grants,omitempty"`
+		LastDDLTime  *time.Time `json:"last_ddl_time,omitempty"`
+	}{
+		Owner:        o.Owner,
+		Name:         o.Name,
+		Type:         o.Type,
+		DDL:          o.String(),
+		Grants:       o.Grants,
+		NeededGrants: o.NeededGrants,
+	}
+	if doc.Grants == nil {
+		doc.Grants = []Grant{}
+	}
+	if !o.LastDDLTime.IsZero() {
+		doc.LastDDLTime = &o.LastDDLTime
+	}
+
+	return json.Marshal(doc)
+}
+
+// exportTableView populates the object with the DDL for a table, view,
+// or materialized view along with the indices, comments and, unless
+// they are exported separately, triggers
+func exportTableView(ctx context.Context, db Querier, o *Object, separateTriggers bool, res *ObjectResult) error {
+
+	// ObjectDDL
+	objDDL, err := ObjDDL(ctx, db, o.Owner, o.Name, o.Type)
+	if err != nil {
+		return err
+	}
+
+	// Split the CREATE DDL from the ALTER DDL so they may be output separately
+	s := regexp.MustCompile("[\n\r\t ]*ALTER ").Split(objDDL, -1)
+	o.CreateDDL = s[0]
+
+	// Indices
+	switch o.Type {
+	case typeTable, typeMaterializedView:
+		o.Indexes, err = ObjIndices(ctx, db, o.Owner, o.Name, o.Type)
+		res.note(err)
+	}
+
+	// Alter object commands from the object DDL
+	if len(s) > 1 {
+		sorted := s[1:]
+		sort.Strings(sorted)
+		for _, cmd := range sorted {
+			o.AlterDDL = append(o.AlterDDL, trimLine("ALTER "+cmd))
+		}
+	}
+
+	// Comments
+	o.Comments, err = ObjComments(ctx, db, o.Owner, o.Name, o.Type)
+	res.note(err)
+
+	// Column Comments
+	o.ColumnComments, err = ColComments(ctx, db, o.Owner, o.Name, o.Type)
+	res.note(err)
+
+	// Triggers
+	if separateTriggers {
+		return nil
+	}
+	o.Triggers, err = ObjTriggers(ctx, db, o.Owner, o.Name, o.Type, false)
+	res.note(err)
+
+	return nil
+}
@@ -2,23 +2,208 @@
 package oradex
 
 import (
-	"database/sql"
-	"errors"
+	"context"
 	"fmt"
-	"log"
 	"regexp"
 	"runtime"
 	"sort"
 	"strings"
+	"time"
 
 	//
 	_ "github.com/godror/godror"
 )
 
+const typeChain = "CHAIN"
 const typeDatabaseLink = "DATABASE LINK"
+const typeJob = "JOB"
+const typeJobClass = "JOB CLASS"
 const typeMaterializedView = "MATERIALIZED VIEW"
+const typePackage = "PACKAGE"
+const typeProgram = "PROGRAM"
+const typeQueue = "QUEUE"
+const typeQueueTable = "QUEUE TABLE"
+const typeRole = "ROLE"
+const typeSchedule = "SCHEDULE"
+const typeSequence = "SEQUENCE"
+const typeSynonym = "SYNONYM"
 const typeTable = "TABLE"
+const typeTrigger = "TRIGGER"
+const typeType = "TYPE"
 const typeView = "VIEW"
+const typeWindow = "WINDOW"
+
+// QualifiedName identifies a database object by schema and name
+type QualifiedName struct {
+	Schema string
+	Name   string
+}
+
+// ExportOptions determines what is included when exporting DDL.
+// Storage, Force, Alter, and EmitSchema are applied at the session
+// level by Extractor.Init unless Transforms is set.
+type ExportOptions struct {
+	NeededGrants bool
+	ObjectGrants bool
+	Storage      bool
+	Force        bool
+	Alter        bool
+	EmitSchema   bool
+
+	// Drop prepends the DROP statement for each object to the DDL, with
+	// DropCascade determining if CASCADE CONSTRAINTS/FORCE is used
+	Drop        bool
+	DropCascade bool
+
+	// CurrentValue adds an ALTER SEQUENCE ... RESTART to sequence DDL so
+	// that recreated sequences continue from their current value
+	CurrentValue bool
+
+	// ResetSequences resets the START WITH value of sequences to the
+	// original definition of the sequence (see ResetSequenceStart)
+	// rather than the next value of the sequence
+	ResetSequences bool
+
+	// ExcludeSystemSchemas determines whether or not the Oracle
+	// supplied schemas (see ExcludedSchemas) are excluded
+	ExcludeSystemSchemas bool
+
+	// TargetVersion, when set, is the Oracle version that the exported
+	// DDL is downgraded to (see DowngradeDDL)
+	TargetVersion OracleVersion
+
+	// ObjectTypes, when set, restricts schema extraction to the listed
+	// object types while ExcludeObjectTypes lists the object types to
+	// skip. Types may use underscores in place of spaces.
+	ObjectTypes        []string
+	ExcludeObjectTypes []string
+
+	// IncludeNames, when set, restricts schema extraction to the objects
+	// with names matching one of the patterns while ExcludeNames lists
+	// the patterns for the names of objects to skip (see
+	// CompileNamePatterns)
+	IncludeNames []*regexp.Regexp
+	ExcludeNames []*regexp.Regexp
+
+	// RemapSchemas, when set, maps the names of the schemas in the
+	// exported DDL to new names (see RemapSchemas) so that the DDL may be
+	// deployed to a different schema
+	RemapSchemas map[string]string
+
+	// RemapTablespaces, when set, maps the tablespaces in the exported
+	// DDL to new names or substitution variables (see RemapTablespaces)
+	RemapTablespaces map[string]string
+
+	// Transforms, when set, are the DBMS_METADATA transform parameters
+	// to use in place of those derived from Storage, Force, Alter, and
+	// EmitSchema
+	Transforms *TransformParams
+
+	// SeparateTriggers exports triggers as objects in their own right
+	// rather than inline with the table or view that they are on
+	SeparateTriggers bool
+
+	// Normalize removes the volatile parts of the DDL (see NormalizeDDL)
+	// so that only actual changes to the objects show up when comparing
+	// extracts
+	Normalize bool
+
+	// Format reformats the DDL to be easier to read (see FormatDDL)
+	Format bool
+
+	// KeywordCase is the case, "upper" or "lower", to set the SQL
+	// keywords to when formatting the DDL. The case is left as is if
+	// empty.
+	KeywordCase string
+
+	// Synonyms adds the synonyms on each object that are owned by other
+	// schemas, or by PUBLIC, to the DDL for the object and includes the
+	// private synonyms of a schema as objects in their own right
+	Synonyms bool
+
+	// PublicSynonyms determines whether or not the PUBLIC synonyms on
+	// each object are included with the synonyms. Creating PUBLIC
+	// synonyms requires additional privileges and they are often
+	// specific to an environment.
+	PublicSynonyms bool
+
+	// ConstraintState, when set, is the state (see ParseConstraintState)
+	// that all table constraints are set to in place of the state that
+	// the constraints have in the database, i.e. ENABLE NOVALIDATE for
+	// loading data that may not satisfy the constraints
+	ConstraintState string
+
+	// Retries is the number of times that the extraction of an object is
+	// retried after a transient error (see IsTransient), waiting
+	// RetryBackoff (or DefaultRetryBackoff), doubled for each subsequent
+	// retry, between attempts
+	Retries      int
+	RetryBackoff time.Duration
+
+	// ObjectTimeout, when set, limits the time spent extracting each
+	// object. Objects that take longer are skipped with ErrObjectTimeout.
+	ObjectTimeout time.Duration
+
+	// RewriteRules are applied, in order, to the DDL of each object
+	// after all of the other rewriting (see RewriteRule)
+	RewriteRules []RewriteRule
+}
+
+// NewExportOptions returns the default export options
+func NewExportOptions() ExportOptions {
+	return ExportOptions{EmitSchema: true, ExcludeSystemSchemas: true, PublicSynonyms: true}
+}
+
+// IncludeType returns true if objects of the specified type are to be
+// extracted. Triggers are only extracted in their own right when
+// SeparateTriggers is set, otherwise they are extracted with the table,
+// or view, that they are on.
+func (o ExportOptions) IncludeType(objType string) bool {
+
+	if objType == typeSynonym && !o.Synonyms {
+		return false
+	}
+	if objType == typeTrigger && !o.SeparateTriggers {
+		return false
+	}
+	if len(o.ObjectTypes) > 0 && !containsType(o.ObjectTypes, objType) {
+		return false
+	}
+	return !containsType(o.ExcludeObjectTypes, objType)
+}
+
+// IncludeObject returns true if the object, by type and name, is to be
+// extracted
+func (o ExportOptions) IncludeObject(name, objType string) bool {
+
+	if !o.IncludeType(objType) {
+		return false
+	}
+	if len(o.IncludeNames) > 0 && !matchesAny(o.IncludeNames, name) {
+		return false
+	}
+	return !matchesAny(o.ExcludeNames, name)
+}
+
+// containsType returns true if the object type is in the list of types
+func containsType(l []string, objType string) bool {
+	for _, v := range l {
+		if strings.Replace(strings.ToUpper(trimString(v)), "_", " ", -1) == objType {
+			return true
+		}
+	}
+	return false
+}
+
+// DDLResult contains the DDL exported for an object along with where,
+// if anywhere, it was written to
+type DDLResult struct {
+	Schema   string
+	Name     string
+	ObjType  string
+	DDL      string
+	Filename string
+}
 
 // newLine returns an OS-aware new line
 func newLine() string {
@@ -65,35 +250,17 @@ func boolToText(b bool) string {
 }
 
 // InitDbmsMetadata initialized the DBMS_METADATA transormation parameters.
-func InitDbmsMetadata(db *sql.DB, storage, force, constraints bool) (bool, error) {
-
-	storageArg := boolToText(storage)
-	forceArg := boolToText(force)
-	constraintsArg := boolToText(constraints)
-
-	query := fmt.Sprintf(`
-BEGIN
-    DBMS_METADATA.SET_TRANSFORM_PARAM
-        ( DBMS_METADATA.SESSION_TRANSFORM, 'DEFAULT' );
-    DBMS_METADATA.SET_TRANSFORM_PARAM
-        ( DBMS_METADATA.SESSION_TRANSFORM, 'CONSTRAINTS', TRUE );
-    DBMS_METADATA.SET_TRANSFORM_PARAM
-        ( DBMS_METADATA.SESSION_TRANSFORM, 'REF_CONSTRAINTS', TRUE );
-    DBMS_METADATA.SET_TRANSFORM_PARAM
-        ( DBMS_METADATA.SESSION_TRANSFORM, 'CONSTRAINTS_AS_ALTER', %s );
-    DBMS_METADATA.SET_TRANSFORM_PARAM
-        ( DBMS_METADATA.SESSION_TRANSFORM, 'FORCE', %s );
-    DBMS_METADATA.SET_TRANSFORM_PARAM
-        ( DBMS_METADATA.SESSION_TRANSFORM, 'STORAGE', %s );
-    DBMS_METADATA.SET_TRANSFORM_PARAM
-        ( DBMS_METADATA.SESSION_TRANSFORM, 'SEGMENT_ATTRIBUTES', %s );
-    DBMS_METADATA.SET_TRANSFORM_PARAM
-        ( DBMS_METADATA.SESSION_TRANSFORM, 'SQLTERMINATOR', TRUE );
-    DBMS_METADATA.SET_TRANSFORM_PARAM
-        ( DBMS_METADATA.SESSION_TRANSFORM, 'PRETTY', TRUE );
-END; `, constraintsArg, forceArg, storageArg, storageArg)
-
-	_, err := db.Exec(query)
+// See InitTransforms, or Extractor.Init with the ExportOptions, for
+// control over the full set of transform parameters (i.e. EmitSchema).
+func InitDbmsMetadata(ctx context.Context, db Querier, storage, force, constraints bool) (bool, error) {
+
+	p := DefaultTransformParams()
+	p.Storage = storage
+	p.SegmentAttributes = storage
+	p.Force = force
+	p.ConstraintsAsAlter = constraints
+
+	err := InitTransforms(ctx, db, p)
 	if err != nil {
 		return false, err
 	}
@@ -103,14 +270,22 @@ END; `, constraintsArg, forceArg, storageArg, storageArg)
 
 // ObjType determines the type of object to extract DDL for so the user
 // doesn't have to specify it.
-func ObjType(db *sql.DB, schema, name string) (string, error) {
+func ObjType(ctx context.Context, db Querier, schema, name string) (string, error) {
 
 	// Note: ORDER BY primarily for disambiguating between materialized
 	//      views and the underlying table for the materialized view
 	query := `
 WITH x AS (
-    SELECT object_type
-        FROM dba_objects
+    SELECT CASE
+                WHEN o.object_type = 'TABLE'
+                    AND EXISTS (
+                        SELECT 1
+                            FROM dba_queue_tables q
+                            WHERE q.owner = o.owner
+                                AND q.queue_table = o.object_name ) THEN 'QUEUE TABLE'
+                ELSE o.object_type
+                END AS object_type
+        FROM dba_objects o
         WHERE owner = :1
             AND object_name = :2
         ORDER BY CASE
@@ -127,7 +302,7 @@ SELECT object_type
 `
 
 	var objType string
-	rows, err := db.Query(query, schema, name)
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, query), schema, name)
 	if err != nil {
 		return objType, err
 	}
@@ -139,28 +314,20 @@ SELECT object_type
 
 	if rows.Next() {
 		err = rows.Scan(&objType)
+		return objType, err
 	}
-	return objType, err
-}
 
-// ObjDDL retrieves the DDL (to include comments, grants and supporting
-// objects such as triggers, indicis, etc.) for the specified object
-func ObjDDL(db *sql.DB, schema, name, objType string) (string, error) {
+	// Roles are not owned by a schema and are not in dba_objects
+	return roleType(ctx, db, name)
+}
 
-	// match the type for use by dbms_metadata
-	var ddlType string
-	switch objType {
-	case typeDatabaseLink:
-		ddlType = "DB_LINK"
-	case typeMaterializedView:
-		ddlType = "MATERIALIZED_VIEW"
-	default:
-		ddlType = objType
-	}
+// roleType returns ROLE if the name is that of a database role
+func roleType(ctx context.Context, db Querier, name string) (string, error) {
 
-	rows, err := db.Query("SELECT dbms_metadata.get_ddl ( :1, :2, :3 ) FROM DUAL", ddlType, name, schema)
+	var objType string
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, "SELECT 'ROLE' FROM dba_roles WHERE role = :1"), name)
 	if err != nil {
-		return "", err
+		return objType, err
 	}
 	defer func() {
 		if cerr := rows.Close(); cerr != nil && err == nil {
@@ -168,38 +335,88 @@ func ObjDDL(db *sql.DB, schema, name, objType string) (string, error) {
 		}
 	}()
 
-	var DDL string
 	if rows.Next() {
-		err = rows.Scan(&DDL)
-		if err != nil {
-			return DDL, err
-		}
+		err = rows.Scan(&objType)
+	}
+	return objType, err
+}
+
+// metadataType matches the object type for use by dbms_metadata
+func metadataType(objType string) string {
+	switch objType {
+	case typeDatabaseLink:
+		return "DB_LINK"
+	case typeMaterializedView:
+		return "MATERIALIZED_VIEW"
+	case typeQueue:
+		return "AQ_QUEUE"
+	case typeQueueTable:
+		return "AQ_QUEUE_TABLE"
+	case typeChain, typeJob, typeJobClass, typeProgram, typeSchedule, typeWindow:
+		// Scheduler objects are all procedural objects
+		return "PROCOBJ"
+	default:
+		return objType
+	}
+}
+
+// ObjMetadata retrieves the metadata for the specified object in either
+// the SXML or the XML format. SXML is the simplified, canonical, format
+// that is intended for comparison while XML is the full metadata.
+func ObjMetadata(ctx context.Context, db Querier, schema, name, objType, metadataFormat string) (string, error) {
+
+	var fn string
+	switch strings.ToLower(metadataFormat) {
+	case "sxml":
+		fn = "get_sxml"
+	case "xml":
+		fn = "get_xml"
+	default:
+		return "", fmt.Errorf("unknown metadata format %q", metadataFormat)
+	}
+
+	query := fmt.Sprintf("SELECT dbms_metadata.%s ( :1, :2, :3 ) FROM DUAL", fn)
+
+	m, err := queryLob(ctx, db, query, metadataType(objType), name, schema)
+	return trimLine(m), err
+}
 
-		DDL = trimString(DDL)
+// ObjDDL retrieves the DDL (to include comments, grants and supporting
+// objects such as triggers, indicis, etc.) for the specified object
+func ObjDDL(ctx context.Context, db Querier, schema, name, objType string) (string, error) {
 
-		switch objType {
-		case typeView, typeMaterializedView:
-			// Ensure that there is a semicolon at the end of views and
-			// materialized views-- these don't appear to work correctly if
-			// the last line is a comment
+	// The DDL is read in chunks as that for package bodies and for
+	// partitioned tables may run to several megabytes
+	var b strings.Builder
+	w := newDDLWriter(&b, objType)
+	_, err := queryLobTo(ctx, w, db, "SELECT dbms_metadata.get_ddl ( :1, :2, :3 ) FROM DUAL", metadataType(objType), name, schema)
+	if err != nil {
+		return b.String(), err
+	}
+	err = w.Flush()
+	DDL := b.String()
 
+	switch objType {
+	case typeView, typeMaterializedView:
+		// Ensure that there is a semicolon at the end of views and
+		// materialized views-- these don't appear to work correctly if
+		// the last line is a comment
+		if DDL != "" {
 			s := splitLines(DDL)
 			chk := regexp.MustCompile("--").FindString(s[len(s)-1])
 			if chk != "" {
 				s = append(s, ";")
 				DDL = strings.Join(s, newLine())
 			}
-		default:
-			// Remove any excess trailing white space from the end of PL/SQL blocks
-			DDL = regexp.MustCompile("[\n\r\t ]+/\n").ReplaceAllString(DDL, "\n/\n")
 		}
 	}
 
-	return DDL, nil
+	return DDL, err
 }
 
-// ObjTriggers returns the triggers for the specified object.
-func ObjTriggers(db *sql.DB, schema, name, objType string, quiet bool) (string, error) {
+// ObjTriggers returns the triggers for the specified object. The quiet
+// flag is ignored as for ExportDDL.
+func ObjTriggers(ctx context.Context, db Querier, schema, name, objType string, quiet bool) (string, error) {
 
 	var triggers []string
 	//triggers = append(triggers, "")
@@ -214,7 +431,7 @@ SELECT dbms_metadata.get_ddl ( 'TRIGGER', trigger_name, owner )
         trigger_name
 `
 
-	rows, err := db.Query(query, schema, name)
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, query), schema, name)
 	if err != nil {
 		return "", err
 	}
@@ -230,39 +447,7 @@ SELECT dbms_metadata.get_ddl ( 'TRIGGER', trigger_name, owner )
 			return "", err
 		}
 
-		rslt = trimString(rslt)
-
-		// Ensure that the ON <table_name> clause contains a schema for
-		//  the table... not all do.
-		// ASSERTION: it is somewhat unlikely that the trigger owner and
-		//  the table owner are different... and if they are then the
-		//  table owner will already be specified.
-		//
-		//  Until I can get a better handle on regexp in Go...
-		s := regexp.MustCompile("[\n\r\t ][Oo][Nn][\n\r\t ]+").Split(rslt, 2)
-		if len(s) > 1 {
-			t := regexp.MustCompile("[\n\r\t ]+").Split(s[1], 2)
-			u := strings.Split(t[0], ".")
-			if len(u) == 1 && len(s) > 1 {
-				//  no table owner seen
-				rslt = fmt.Sprintf("%s ON \"%s\".%s", s[0], schema, s[1])
-			}
-		} else {
-			carp(quiet, errors.New(fmt.Sprintf("Funky triggers for %q.%q??\n", schema, name)))
-		}
-
-		// Remove any excess trailing white space from the end of the PL/SQL block
-		v := strings.Split(rslt, "ALTER TRIGGER")
-		if len(s) > 1 {
-			triggers = append(triggers, strings.TrimRight(v[0], "\n\r\t /")+newLine()+"/")
-
-			for _, x := range v[1:] {
-				triggers = append(triggers, "ALTER TRIGGER"+strings.TrimRight(x, "\n\r\t /"))
-			}
-		} else {
-			rslt = strings.TrimRight(rslt, "\n\r\t /") + newLine() + "/"
-			triggers = append(triggers, rslt)
-		}
+		triggers = append(triggers, formatTrigger(rslt, schema, name)...)
 	}
 
 	DDL := strings.Join(triggers, dblSpace())
@@ -270,108 +455,158 @@ SELECT dbms_metadata.get_ddl ( 'TRIGGER', trigger_name, owner )
 	return DDL, nil
 }
 
-// ExportDDL pulls together, and returns, the DDL for the specified
-// object and all *supporting* objects and grants.
-func ExportDDL(db *sql.DB, schema, name, objType string, quiet, neededGrants, objectGrants bool) (string, error) {
+// ObjTrigger returns the DDL for the specified trigger. Unlike
+// ObjTriggers this includes triggers owned by the schema that are on
+// objects in other schemas.
+func ObjTrigger(ctx context.Context, db Querier, schema, name string) (string, error) {
 
-	var grants string
-	var objDDL string
-	var l []string
-	var err error
+	var rslt string
+	var tableOwner string
+	var tableName string
 
-	switch objType {
-	case typeTable, typeView, typeMaterializedView:
-		objDDL, err = exportTableView(db, schema, name, objType, quiet)
-	default:
-		objDDL, err = ObjDDL(db, schema, name, objType)
-	}
+	query := `
+SELECT dbms_metadata.get_ddl ( 'TRIGGER', trigger_name, owner ),
+        coalesce ( table_owner, owner ),
+        coalesce ( table_name, trigger_name )
+    FROM sys.all_triggers
+    WHERE owner = :1
+        AND trigger_name = :2
+`
+
+	err := db.QueryRowContext(ctx, DictQuery(ctx, query), schema, name).Scan(&rslt, &tableOwner, &tableName)
 	if err != nil {
 		return "", err
 	}
 
-	if neededGrants {
-		grants, err = ObjNeededPrivs(db, schema, name, objType)
-		carp(quiet, err)
-		l = appendLine(l, grants)
-	}
+	return strings.Join(formatTrigger(rslt, tableOwner, tableName), dblSpace()), nil
+}
 
-	l = appendLine(l, objDDL)
+// ObjTriggerTable returns the table, or view, that the specified trigger
+// is on along with the type of the object. The name is empty for
+// triggers that are not on a table or view (i.e. schema triggers).
+func ObjTriggerTable(ctx context.Context, db Querier, schema, name string) (QualifiedName, string, error) {
 
-	// Grants
-	if objectGrants {
-		objDDL, err = ObjGrantedPrivs(db, schema, name, objType)
-		carp(quiet, err)
-		l = appendLine(l, objDDL)
+	var q QualifiedName
+	var objType string
+
+	// The table of a materialized view is reported as the materialized
+	// view
+	query := `
+SELECT t.table_owner,
+        t.table_name,
+        o.object_type
+    FROM dba_triggers t
+    JOIN dba_objects o
+        ON ( o.owner = t.table_owner
+            AND o.object_name = t.table_name )
+    WHERE t.owner = :1
+        AND t.trigger_name = :2
+        AND t.base_object_type IN ( 'TABLE', 'VIEW' )
+        AND o.object_type IN ( 'TABLE', 'VIEW', 'MATERIALIZED VIEW' )
+    ORDER BY CASE
+            WHEN o.object_type = 'MATERIALIZED VIEW' THEN 1
+            ELSE 2
+            END
+`
+
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, query), schema, name)
+	if err != nil {
+		return q, objType, err
 	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
 
-	DDL := strings.Join(l, dblSpace())
-	return DDL, err
+	if rows.Next() {
+		err = rows.Scan(&q.Schema, &q.Name, &objType)
+	}
+	return q, objType, err
 }
 
-func exportTableView(db *sql.DB, schema, name, objType string, quiet bool) (string, error) {
-
-	var l []string
+// formatTrigger cleans up the DDL for a trigger, ensuring that the table
+// the trigger is on is schema qualified and splitting out any ALTER
+// TRIGGER commands.
+func formatTrigger(rslt, schema, name string) []string {
 
-	// ObjectDDL
-	objDDL, err := ObjDDL(db, schema, name, objType)
-	if err != nil {
-		return "", err
-	}
+	var triggers []string
 
-	// Split the CREATE DDL from the ALTER DDL so they may be output separately
-	s := regexp.MustCompile("[\n\r\t ]*ALTER ").Split(objDDL, -1)
-	l = appendLine(l, s[0])
+	rslt = trimString(rslt)
 
-	// Indices
-	switch objType {
-	case typeTable, typeMaterializedView:
-		objDDL, err = ObjIndices(db, schema, name, objType)
-		carp(quiet, err)
-		l = appendLine(l, objDDL)
+	// Ensure that the ON <table_name> clause contains a schema for
+	//  the table... not all do.
+	// ASSERTION: it is somewhat unlikely that the trigger owner and
+	//  the table owner are different... and if they are then the
+	//  table owner will already be specified.
+	//
+	//  Until I can get a better handle on regexp in Go...
+	s := regexp.MustCompile("[\n\r\t ][Oo][Nn][\n\r\t ]+").Split(rslt, 2)
+	if len(s) > 1 {
+		t := regexp.MustCompile("[\n\r\t ]+").Split(s[1], 2)
+		u := strings.Split(t[0], ".")
+		if len(u) == 1 && len(s) > 1 {
+			//  no table owner seen
+			rslt = fmt.Sprintf("%s ON \"%s\".%s", s[0], schema, s[1])
+		}
+	} else {
+		logger.Warn("unable to find the table of the trigger", "schema", schema, "name", name)
 	}
 
-	// Alter object commands from the object DDL
+	// Remove any excess trailing white space from the end of the PL/SQL block
+	v := strings.Split(rslt, "ALTER TRIGGER")
 	if len(s) > 1 {
-		sorted := s[1:]
-		sort.Strings(sorted)
-		for _, cmd := range sorted {
-			l = appendLine(l, "ALTER "+cmd)
+		triggers = append(triggers, strings.TrimRight(v[0], "\n\r\t /")+newLine()+"/")
+
+		for _, x := range v[1:] {
+			triggers = append(triggers, "ALTER TRIGGER"+strings.TrimRight(x, "\n\r\t /"))
 		}
+	} else {
+		rslt = strings.TrimRight(rslt, "\n\r\t /") + newLine() + "/"
+		triggers = append(triggers, rslt)
 	}
 
-	// Comments
-	objDDL, err = ObjComments(db, schema, name, objType)
-	carp(quiet, err)
-	l = appendLine(l, objDDL)
+	return triggers
+}
 
-	// Column Comments
-	objDDL, err = ColComments(db, schema, name, objType)
-	carp(quiet, err)
-	l = appendLine(l, objDDL)
+// ExportDDL pulls together, and returns, the DDL for the specified
+// object and all *supporting* objects and grants. The quiet flag is
+// ignored, which errors are logged is determined by the logger (see
+// SetLogger). See Extractor.ExportDDL for the other export options and
+// for the result of the extraction.
+func ExportDDL(ctx context.Context, db Querier, schema, name, objType string, quiet, neededGrants, objectGrants bool) (string, error) {
+
+	opts := NewExportOptions()
+	opts.NeededGrants = neededGrants
+	opts.ObjectGrants = objectGrants
+
+	objDDL, _, err := NewExtractor(db, opts).ExportDDL(ctx, schema, name, objType)
+	return objDDL, err
+}
 
-	// Triggers
-	objDDL, err = ObjTriggers(db, schema, name, objType, quiet)
-	carp(quiet, err)
-	l = appendLine(l, objDDL)
+// ExportPackageDDL returns the DDL for the specification and the body of
+// the specified package separately. The quiet flag is ignored as for
+// ExportDDL. See Extractor.ExportPackageDDL.
+func ExportPackageDDL(ctx context.Context, db Querier, schema, name string, quiet, neededGrants, objectGrants bool) (string, string, error) {
 
-	DDL := strings.Join(l, dblSpace())
-	return DDL, err
+	opts := NewExportOptions()
+	opts.NeededGrants = neededGrants
+	opts.ObjectGrants = objectGrants
+
+	spec, body, _, err := NewExtractor(db, opts).ExportPackageDDL(ctx, schema, name)
+	return spec, body, err
 }
 
-func carp(quiet bool, err error) {
-	if err != nil {
-		if !quiet {
-			log.Println(err)
-		}
-	}
+func runQuery(ctx context.Context, db Querier, query, schema, name string) (string, error) {
+	return runQueryArgs(ctx, db, query, schema, name)
 }
 
-func runQuery(db *sql.DB, query, schema, name string) (string, error) {
+func runQueryArgs(ctx context.Context, db Querier, query string, args ...interface{}) (string, error) {
 
 	var l []string
 	var rslt string
 
-	rows, err := db.Query(query, schema, name)
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, query), args...)
 	if err != nil {
 		return "", err
 	}
@@ -393,3 +628,88 @@ func runQuery(db *sql.DB, query, schema, name string) (string, error) {
 
 	return r, err
 }
+
+// GetChangedObjects returns the names of the objects in the specified
+// schema that have had DDL applied to them since the specified time.
+// Package and type bodies are included so that a change to only the body
+// is reported as a change to the package or type.
+func GetChangedObjects(ctx context.Context, db Querier, schema string, since time.Time) ([]string, error) {
+
+	var l []string
+
+	m, err := GetChangedObjectTimes(ctx, db, schema, since)
+	for name := range m {
+		l = append(l, name)
+	}
+	sort.Strings(l)
+
+	return l, err
+}
+
+// GetChangedObjectTimes returns the names, as for GetChangedObjects,
+// along with the time that DDL was last applied to each of the objects
+// (or to the body, if later). As the last DDL time is only to the
+// second, objects changed during the second of the since time are
+// included, so that consecutive calls, each since the time of the
+// previous one, report these objects twice. The times allow for callers
+// to skip the objects that they have already seen.
+func GetChangedObjectTimes(ctx context.Context, db Querier, schema string, since time.Time) (map[string]time.Time, error) {
+
+	m := make(map[string]time.Time)
+
+	query := `
+SELECT object_name,
+        max ( last_ddl_time ) AS last_ddl_time
+    FROM dba_objects
+    WHERE owner = :1
+        AND last_ddl_time >= :2
+        AND object_type IN ( ` + objectTypeList + `, 'PACKAGE BODY', 'TYPE BODY' )
+        AND object_name NOT LIKE 'SYS_PLSQL%'
+        AND object_name <> 'CREATE$JAVA$LOB$TABLE'
+        AND object_name NOT LIKE 'AQ$%'
+    GROUP BY object_name
+`
+
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, query), schema, since)
+	if err != nil {
+		return m, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	for rows.Next() {
+		var name string
+		var t time.Time
+		err = rows.Scan(&name, &t)
+		if err != nil {
+			return m, err
+		}
+		m[name] = t
+	}
+
+	return m, err
+}
+
+// schemaObjects returns the objects, for which DDL can be extracted, in
+// the specified schema
+func schemaObjects(ctx context.Context, db Querier, schema string) ([]DDLResult, error) {
+	return schemaObjectsAt(ctx, db, schema, "")
+}
+
+// schemaObjectsAt returns the list of objects for the specified schema
+// in the database at the other end of the database link, or in the
+// connected database if no link is specified
+func schemaObjectsAt(ctx context.Context, db Querier, schema, link string) ([]DDLResult, error) {
+
+	var l []DDLResult
+
+	objs, err := listObjectsAt(ctx, db, schema, link, Filter{})
+	for _, o := range objs {
+		l = append(l, DDLResult{Schema: o.Schema, Name: o.Name, ObjType: o.ObjType})
+	}
+
+	return l, err
+}
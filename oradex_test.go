@@ -0,0 +1,125 @@
+package oradex
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// readFixture returns the content of the testdata file
+func readFixture(t *testing.T, filename string) string {
+	t.Helper()
+	b, err := ioutil.ReadFile(filepath.Join("testdata", filepath.FromSlash(filename)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestObjType(t *testing.T) {
+
+	db := openFixtures(t, "app.json")
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"ORDERS", typeTable},
+		{"ORDER_TOTALS", typeMaterializedView},
+		{"APP_READER", typeRole},
+		{"MISSING", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ObjType(context.Background(), db, "APP", tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObjDDL(t *testing.T) {
+
+	db := openFixtures(t, "app.json")
+
+	tests := []struct {
+		name    string
+		objType string
+		want    string
+	}{
+		{"ORDERS", typeTable, "objddl/app.orders.table.sql"},
+		{"ORDER_SEQ", typeSequence, "objddl/app.order_seq.sequence.sql"},
+		{"OPEN_ORDERS", typeView, "objddl/app.open_orders.view.sql"},
+		{"ORDER_TOTALS", typeMaterializedView, "objddl/app.order_totals.mview.sql"},
+		{"ORDER_API", typePackage, "objddl/app.order_api.package.sql"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ObjDDL(context.Background(), db, "APP", tt.name, tt.objType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := readFixture(t, tt.want); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestObjTriggerTable(t *testing.T) {
+
+	db := openFixtures(t, "app.json")
+
+	tests := []struct {
+		name    string
+		want    QualifiedName
+		objType string
+	}{
+		{"ORDERS_BIU", QualifiedName{"APP", "ORDERS"}, typeTable},
+		{"APP_LOGON", QualifiedName{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, objType, err := ObjTriggerTable(context.Background(), db, "APP", tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want || objType != tt.objType {
+				t.Errorf("got %v %q, want %v %q", got, objType, tt.want, tt.objType)
+			}
+		})
+	}
+}
+
+func TestIncludeType(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		opts    ExportOptions
+		objType string
+		want    bool
+	}{
+		{"table", ExportOptions{}, typeTable, true},
+		{"inline triggers", ExportOptions{}, typeTrigger, false},
+		{"separate triggers", ExportOptions{SeparateTriggers: true}, typeTrigger, true},
+		{"synonyms", ExportOptions{}, typeSynonym, false},
+		{"excluded", ExportOptions{ExcludeObjectTypes: []string{typeView}}, typeView, false},
+		{"not listed", ExportOptions{ObjectTypes: []string{typeTable}}, typeView, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.IncludeType(tt.objType); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
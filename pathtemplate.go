@@ -0,0 +1,107 @@
+package oradex
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// DefaultPathTemplate is the default layout for extracted files:
+// <schema>/<object_type>/<object_name>.sql
+const DefaultPathTemplate = "{{.Schema}}/{{.Type}}/{{.Name}}.{{.Ext}}"
+
+// ObjectInfo contains the values available to path templates. Type is
+// the object type with spaces replaced by underscores (i.e.
+// MATERIALIZED_VIEW). Owner is the same as Schema.
+type ObjectInfo struct {
+	Schema string
+	Owner  string
+	Name   string
+	Type   string
+	Ext    string
+}
+
+// pathFuncs are the functions available to path templates, i.e.
+// {{.Owner | lower}}/{{.Type | lower}}/{{.Name | replace "$" "_"}}.{{.Ext}}
+var pathFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"replace": func(old, new, s string) string {
+		return strings.Replace(s, old, new, -1)
+	},
+}
+
+// FileNamer determines the path that the DDL for an object is written to
+type FileNamer interface {
+	Render(o ObjectInfo) (string, error)
+}
+
+// PathTemplate is a FileNamer that uses a text/template to determine the
+// (relative) path for an object
+type PathTemplate struct {
+	tmpl *template.Template
+}
+
+// NewPathTemplate parses, and validates, a path template
+func NewPathTemplate(s string) (*PathTemplate, error) {
+
+	if s == "" {
+		s = DefaultPathTemplate
+	}
+
+	tmpl, err := template.New("path").Funcs(pathFuncs).Parse(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &PathTemplate{tmpl: tmpl}
+
+	// Ensure that the template only uses the available fields
+	_, err = p.Render(ObjectInfo{Schema: "S", Name: "N", Type: "T"})
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// UsesExt returns true if the path depends on the file extension, as
+// is needed for the files of an object that differ only by extension
+// (i.e. the .csv and .ctl files for the data of a table) to not be
+// written to the same path
+func (p *PathTemplate) UsesExt() bool {
+	o := ObjectInfo{Schema: "S", Name: "N", Type: "T"}
+	a, _ := p.Render(o)
+	o.Ext = "ext"
+	b, _ := p.Render(o)
+	return a != b
+}
+
+// Render returns the path for the object. If no extension is specified
+// then "sql" is used. The path must be relative and may not refer to the
+// parent directory so that the files are always written under the base
+// directory.
+func (p *PathTemplate) Render(o ObjectInfo) (string, error) {
+
+	if o.Ext == "" {
+		o.Ext = "sql"
+	}
+	if o.Owner == "" {
+		o.Owner = o.Schema
+	}
+
+	var b bytes.Buffer
+	err := p.tmpl.Execute(&b, o)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Clean(filepath.FromSlash(b.String()))
+	if filepath.IsAbs(path) || path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("the path %q for %q.%q is not under the base directory", path, o.Schema, o.Name)
+	}
+
+	return path, nil
+}
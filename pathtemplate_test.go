@@ -0,0 +1,93 @@
+package oradex
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestNewPathTemplate(t *testing.T) {
+
+	tests := []struct {
+		tmpl    string
+		wantErr bool
+	}{
+		{"", false},
+		{"{{.Owner | lower}}/{{.Type | lower}}/{{.Name | replace \"$\" \"_\"}}.{{.Ext}}", false},
+		{"{{.Schema}/{{.Name}}", true},
+		{"{{.Schema}}/{{.Table}}", true},
+		{"{{.Schema | title}}", true},
+		{"../{{.Name}}", true},
+		{"/{{.Name}}", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			_, err := NewPathTemplate(tt.tmpl)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPathTemplateRender(t *testing.T) {
+
+	tests := []struct {
+		tmpl    string
+		obj     ObjectInfo
+		want    string
+		wantErr bool
+	}{
+		{"", ObjectInfo{Schema: "APP", Name: "ORDERS", Type: "TABLE"}, "APP/TABLE/ORDERS.sql", false},
+		{"", ObjectInfo{Schema: "APP", Name: "ORDERS", Type: "TABLE", Ext: "csv"}, "APP/TABLE/ORDERS.csv", false},
+		{
+			"{{.Owner | lower}}/{{.Type | lower}}/{{.Name | replace \"$\" \"_\"}}.{{.Ext}}",
+			ObjectInfo{Schema: "APP", Name: "APEX$TEAM", Type: "MATERIALIZED_VIEW"},
+			"app/materialized_view/APEX_TEAM.sql",
+			false,
+		},
+		{"{{.Schema}}/./{{.Name}}", ObjectInfo{Schema: "APP", Name: "X"}, "APP/X", false},
+		{"{{.Schema}}/../{{.Name}}", ObjectInfo{Schema: "APP", Name: "X"}, "X", false},
+		{"{{.Name}}", ObjectInfo{Schema: "APP", Name: ".."}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			p, err := NewPathTemplate(tt.tmpl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := p.Render(tt.obj)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, want error %v", err, tt.wantErr)
+			}
+			if got != filepath.FromSlash(tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathTemplateUsesExt(t *testing.T) {
+
+	tests := []struct {
+		tmpl string
+		want bool
+	}{
+		{"", true},
+		{"{{.Schema}}/{{.Name}}.{{.Ext | upper}}", true},
+		{"{{.Schema}}/{{.Name}}.sql", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			p, err := NewPathTemplate(tt.tmpl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.UsesExt(); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package oradex
+
+import (
+	"regexp"
+	"strings"
+)
+
+// CompileNamePatterns compiles a list of object name patterns. Unless
+// regex is set the patterns are SQL LIKE patterns where % matches any
+// number of characters and _ matches a single character.
+func CompileNamePatterns(patterns []string, regex bool) ([]*regexp.Regexp, error) {
+
+	var l []*regexp.Regexp
+
+	for _, p := range patterns {
+		p = trimString(p)
+		if p == "" {
+			continue
+		}
+
+		if !regex {
+			p = likeToRegexp(p)
+		}
+
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return l, err
+		}
+		l = append(l, re)
+	}
+
+	return l, nil
+}
+
+// likeToRegexp converts a SQL LIKE pattern into an anchored regular
+// expression
+func likeToRegexp(p string) string {
+
+	var b strings.Builder
+
+	b.WriteString("^")
+	for _, r := range p {
+		switch r {
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+
+	return b.String()
+}
+
+// matchesAny returns true if the name matches any of the patterns
+func matchesAny(l []*regexp.Regexp, name string) bool {
+	for _, re := range l {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
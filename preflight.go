@@ -0,0 +1,67 @@
+package oradex
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// PreflightCheck is the result of checking one of the privileges needed
+// for extracting the DDL. Grant is the grant, or grants, that are needed
+// if the check fails.
+type PreflightCheck struct {
+	Name  string
+	OK    bool
+	Grant string
+	Err   error
+}
+
+// Preflight checks that the connected user is able to query the data
+// dictionary views, and to use DBMS_METADATA, as needed for extracting
+// the DDL for the objects of other schemas
+func Preflight(ctx context.Context, db Querier) ([]PreflightCheck, error) {
+
+	var l []PreflightCheck
+
+	for _, v := range dictViews {
+		var n int
+		err := db.QueryRowContext(ctx, fmt.Sprintf("SELECT count (*) FROM %s WHERE rownum = 1", v)).Scan(&n)
+		if ctx.Err() != nil {
+			return l, ctx.Err()
+		}
+		l = append(l, PreflightCheck{
+			Name:  "query " + v,
+			OK:    err == nil,
+			Grant: fmt.Sprintf("GRANT SELECT ON sys.%s TO <user> ; -- or SELECT_CATALOG_ROLE", v),
+			Err:   err,
+		})
+	}
+
+	// DBMS_METADATA is executable by PUBLIC but requires the
+	// SELECT_CATALOG_ROLE for extracting the objects of other schemas
+	var s sql.NullString
+	err := db.QueryRowContext(ctx, "SELECT dbms_metadata.get_ddl ( 'USER', user ) FROM dual").Scan(&s)
+	if ctx.Err() != nil {
+		return l, ctx.Err()
+	}
+	l = append(l, PreflightCheck{
+		Name:  "execute dbms_metadata",
+		OK:    err == nil,
+		Grant: "GRANT EXECUTE ON sys.dbms_metadata TO <user> ;",
+		Err:   err,
+	})
+
+	var n int
+	err = db.QueryRowContext(ctx, "SELECT count (*) FROM session_roles WHERE role = 'SELECT_CATALOG_ROLE'").Scan(&n)
+	if ctx.Err() != nil {
+		return l, ctx.Err()
+	}
+	l = append(l, PreflightCheck{
+		Name:  "dbms_metadata for other schemas (SELECT_CATALOG_ROLE)",
+		OK:    err == nil && n > 0,
+		Grant: "GRANT SELECT_CATALOG_ROLE TO <user> ; -- must be a default role",
+		Err:   err,
+	})
+
+	return l, nil
+}
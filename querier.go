@@ -0,0 +1,17 @@
+package oradex
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Querier is the database handle that the DDL is extracted with. It is
+// satisfied by *sql.DB, *sql.Conn, and *sql.Tx so that the extraction
+// may be pinned to a single session, and so that the
+// database may be replaced, i.e. by a database/sql driver that returns
+// canned results, when developing without an Oracle instance.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
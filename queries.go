@@ -1,9 +1,14 @@
 package oradex
 
-import "database/sql"
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
 
 // ColComments returns the column comments for the specified object.
-func ColComments(db *sql.DB, schema, name, objType string) (string, error) {
+func ColComments(ctx context.Context, db Querier, schema, name, objType string) (string, error) {
 
 	query := `
 SELECT 'COMMENT ON COLUMN "'
@@ -27,13 +32,35 @@ SELECT 'COMMENT ON COLUMN "'
         c.table_name,
         c.column_id
 `
-	return runQuery(db, query, schema, name)
+	return runQuery(ctx, db, query, schema, name)
 }
 
-// ObjGrantedPrivs returns the privs granted on the speciifed object.
-func ObjGrantedPrivs(db *sql.DB, schema, name, objType string) (string, error) {
+// grantStmtQuery selects the GRANT statements from the grants CTE
+const grantStmtQuery = `SELECT 'GRANT ' || privs || ' ON "' || schema || '"."' || object_name || '" TO "' || grantee || '"'
+            || CASE
+                WHEN grantable = 'YES' THEN ' WITH GRANT OPTION ;'
+                ELSE ' ;'
+                END AS stmt
+    FROM grants
+    ORDER BY 1
+`
 
-	query := `
+// grantColsQuery selects the grants from the grants CTE
+const grantColsQuery = `SELECT privs,
+        schema,
+        object_name,
+        grantee,
+        grantable
+    FROM grants
+    ORDER BY privs,
+        schema,
+        object_name,
+        grantee
+`
+
+// grantedPrivsQuery selects the privs granted on an object into the
+// grants CTE
+const grantedPrivsQuery = `
 WITH privs AS (
     SELECT p.privilege,
             p.owner AS schema,
@@ -74,19 +101,20 @@ grants AS (
             grantee,
             grantable
 )
-SELECT 'GRANT ' || privs || ' ON "' || schema || '"."' || object_name || '" TO "' || grantee || '"'
-            || CASE
-                WHEN grantable = 'YES' THEN ' WITH GRANT OPTION ;'
-                ELSE ' ;'
-                END AS stmt
-    FROM grants
-    ORDER BY 1
 `
-	return runQuery(db, query, schema, name)
+
+// ObjGrantedPrivs returns the privs granted on the speciifed object.
+func ObjGrantedPrivs(ctx context.Context, db Querier, schema, name, objType string) (string, error) {
+	return runQuery(ctx, db, grantedPrivsQuery+grantStmtQuery, schema, name)
+}
+
+// ObjGrantedPrivList returns the privs granted on the speciifed object.
+func ObjGrantedPrivList(ctx context.Context, db Querier, schema, name, objType string) ([]Grant, error) {
+	return queryGrants(ctx, db, grantedPrivsQuery+grantColsQuery, schema, name)
 }
 
 // ObjIndices returns the indices for the specified object.
-func ObjIndices(db *sql.DB, schema, name, objType string) (string, error) {
+func ObjIndices(ctx context.Context, db Querier, schema, name, objType string) (string, error) {
 
 	query := `
 SELECT dbms_metadata.get_ddl ( 'INDEX', i.index_name, i.owner )
@@ -102,16 +130,28 @@ SELECT dbms_metadata.get_ddl ( 'INDEX', i.index_name, i.owner )
     ORDER BY i.owner,
         i.index_name
 `
-	return runQuery(db, query, schema, name)
+	return runQuery(ctx, db, query, schema, name)
 }
 
-// ObjNeededPrivs attempts to return the privileges needed by the
-// specified object. It should be noted that it may return more
-// privileges than are actually needed.
-func ObjNeededPrivs(db *sql.DB, schema, name, objType string) (string, error) {
-
-	query := `
-WITH objs AS (
+// neededPrivsQuery selects the privs needed by an object into the
+// grants CTE
+const neededPrivsQuery = `
+WITH p AS (
+    SELECT :1 AS owner,
+            :2 AS name
+        FROM dual
+),
+grantees AS (
+    -- the schema and the roles, direct or otherwise, granted to the schema
+    SELECT owner AS grantee
+        FROM p
+    UNION
+    SELECT granted_role
+        FROM dba_role_privs
+        START WITH grantee = ( SELECT owner FROM p )
+        CONNECT BY NOCYCLE PRIOR granted_role = grantee
+),
+objs AS (
     SELECT owner,
             object_name,
             object_type,
@@ -130,25 +170,29 @@ privs AS (
     SELECT tp.privilege,
             d.referenced_owner AS schema,
             d.referenced_name AS object_name,
-            tp.grantee,
+            d.owner AS grantee,
             CASE
-                WHEN tp.grantable = 'YES' AND o.object_type = 'VIEW' THEN 'YES'
+                WHEN tp.grantable = 'YES' AND tp.grantee = d.owner AND o.object_type = 'VIEW' THEN 'YES'
                 ELSE 'NO'
                 END AS grantable
             -- VIEWS only need select, execute
             -- TABLES only need references... ONLY tables need references
+            -- Privileges received via roles are not usable by views and
+            -- definer rights PL/SQL so they are needed as direct grants
         FROM dba_tab_privs tp
+        JOIN grantees g
+            ON ( g.grantee = tp.grantee )
         JOIN dba_dependencies d
-            ON ( d.owner = tp.grantee
-                AND d.referenced_owner = tp.owner
+            ON ( d.referenced_owner = tp.owner
                 AND d.referenced_name = tp.table_name )
+        JOIN p
+            ON ( p.owner = d.owner
+                AND p.name = d.name )
         JOIN objs o
             ON ( o.owner = d.owner
                 AND o.object_name = d.name
                 AND o.rn = 1 )
         WHERE d.owner <> d.referenced_owner
-            AND d.owner = :1
-            AND d.name = :2
             AND ( ( o.object_type IN ( 'VIEW', 'MATERIALIZED VIEW' )
                     AND tp.privilege IN ( 'SELECT', 'EXECUTE' ) )
                 OR ( o.object_type = 'TABLE'
@@ -180,21 +224,59 @@ grants AS (
             grantee,
             grantable
 )
-SELECT 'GRANT ' || privs || ' ON "' || schema || '"."' || object_name || '" TO "' || grantee || '"'
-            || CASE
-                WHEN grantable = 'YES' THEN ' WITH GRANT OPTION ;'
-                ELSE ' ;'
-                END AS stmt
-    FROM grants
-    ORDER BY 1
 `
-	return runQuery(db, query, schema, name)
+
+// ObjNeededPrivs attempts to return the privileges needed by the
+// specified object. Privileges that the schema has received via roles
+// are included as direct grants to the schema since views and definer
+// rights PL/SQL are unable to use privileges received via roles. It
+// should be noted that it may return more privileges than are actually
+// needed.
+func ObjNeededPrivs(ctx context.Context, db Querier, schema, name, objType string) (string, error) {
+	return runQuery(ctx, db, neededPrivsQuery+grantStmtQuery, schema, name)
 }
 
-// ObjSynonyms returns the synonyms created on the specified object.
-func ObjSynonyms(db *sql.DB, schema, name, objType string) (string, error) {
+// ObjNeededPrivList returns the privileges needed by the specified
+// object. See ObjNeededPrivs.
+func ObjNeededPrivList(ctx context.Context, db Querier, schema, name, objType string) ([]Grant, error) {
+	return queryGrants(ctx, db, neededPrivsQuery+grantColsQuery, schema, name)
+}
+
+// ObjQueueSubscribers returns the calls for adding the subscribers to
+// the specified queue.
+func ObjQueueSubscribers(ctx context.Context, db Querier, schema, name string) (string, error) {
 
 	query := `
+SELECT 'BEGIN' || chr ( 10 )
+            || '    dbms_aqadm.add_subscriber (' || chr ( 10 )
+            || '        queue_name => ''"' || owner || '"."' || queue_name || '"'',' || chr ( 10 )
+            || '        subscriber => sys.aq$_agent ( '
+            || CASE
+                WHEN consumer_name IS NULL THEN 'NULL'
+                ELSE '''' || consumer_name || ''''
+                END
+            || ', '
+            || CASE
+                WHEN address IS NULL THEN 'NULL'
+                ELSE '''' || address || ''''
+                END
+            || ', '
+            || coalesce ( to_char ( protocol ), 'NULL' )
+            || ' ) ) ;' || chr ( 10 )
+            || 'END ;' || chr ( 10 )
+            || '/' AS stmt
+    FROM dba_queue_subscribers
+    WHERE owner = :1
+        AND queue_name = :2
+    ORDER BY consumer_name,
+        address
+`
+	return runQuery(ctx, db, query, schema, name)
+}
+
+// synonymsQuery selects the CREATE SYNONYM statements for the synonyms
+// on an object
+const synonymsQuery = `
 SELECT 'CREATE '
             || CASE
                 WHEN owner = 'PUBLIC' THEN 'PUBLIC '
@@ -204,26 +286,43 @@ SELECT 'CREATE '
                 WHEN owner = 'PUBLIC' THEN '"' || synonym_name || '"'
                 ELSE '"' || owner || '"."' || synonym_name || '"'
                 END
-            || ' ON "' || table_owner || '"."' || table_name || '" ;'
+            || ' FOR "' || table_owner || '"."' || table_name || '" ;'
     FROM dba_synonyms
     WHERE table_owner = :1
         AND table_name = :2
-    ORDER BY 1
+        AND db_link IS NULL
 `
-	return runQuery(db, query, schema, name)
+
+// ObjSynonyms returns the synonyms created on the specified object.
+func ObjSynonyms(ctx context.Context, db Querier, schema, name, objType string) (string, error) {
+	return runQuery(ctx, db, synonymsQuery+"    ORDER BY 1\n", schema, name)
+}
+
+// objExternalSynonyms returns the synonyms created on the specified
+// object that are owned by other schemas or, if public is set, by
+// PUBLIC. The private synonyms owned by the same schema as the object
+// are extracted as objects in their own right.
+func objExternalSynonyms(ctx context.Context, db Querier, schema, name string, public bool) (string, error) {
+
+	query := synonymsQuery + "        AND owner <> table_owner\n"
+	if !public {
+		query += "        AND owner <> 'PUBLIC'\n"
+	}
+
+	return runQuery(ctx, db, query+"    ORDER BY 1\n", schema, name)
 }
 
 // ObjComments returns the comments for the specified object.
-func ObjComments(db *sql.DB, schema, name, objType string) (string, error) {
+func ObjComments(ctx context.Context, db Querier, schema, name, objType string) (string, error) {
 	if objType == typeMaterializedView {
-		return MViewComments(db, schema, name, objType)
+		return MViewComments(ctx, db, schema, name, objType)
 	} else {
-		return TableComments(db, schema, name, objType)
+		return TableComments(ctx, db, schema, name, objType)
 	}
 }
 
 // MViewComments returns the comments for the specified materialized view.
-func MViewComments(db *sql.DB, schema, name, objType string) (string, error) {
+func MViewComments(ctx context.Context, db Querier, schema, name, objType string) (string, error) {
 
 	query := `
 SELECT 'COMMENT ON MATERIALIZED VIEW "'
@@ -240,11 +339,11 @@ SELECT 'COMMENT ON MATERIALIZED VIEW "'
     ORDER BY u.owner,
         u.mview_name
 `
-	return runQuery(db, query, schema, name)
+	return runQuery(ctx, db, query, schema, name)
 }
 
 // TableComments returns the comments for the specified table/view.
-func TableComments(db *sql.DB, schema, name, objType string) (string, error) {
+func TableComments(ctx context.Context, db Querier, schema, name, objType string) (string, error) {
 
 	query := `
 SELECT 'COMMENT ON TABLE "'
@@ -261,5 +360,546 @@ SELECT 'COMMENT ON TABLE "'
     ORDER BY u.owner,
         u.table_name
 `
-	return runQuery(db, query, schema, name)
+	return runQuery(ctx, db, query, schema, name)
+}
+
+// ObjDependencies returns the (non-system) objects that the specified
+// object depends on.
+func ObjDependencies(ctx context.Context, db Querier, schema, name string) ([]QualifiedName, error) {
+
+	var l []QualifiedName
+
+	query := fmt.Sprintf(`
+SELECT DISTINCT referenced_owner,
+        referenced_name
+    FROM dba_dependencies
+    WHERE owner = :1
+        AND name = :2
+        AND type <> 'PACKAGE BODY'
+        AND referenced_type IN (
+                'DATABASE LINK', 'FUNCTION', 'MATERIALIZED VIEW', 'PACKAGE', 'PROCEDURE', 'SEQUENCE', 'TABLE', 'TYPE', 'VIEW' )
+        AND referenced_owner <> 'PUBLIC'
+        AND %s
+        AND NOT ( referenced_owner = owner
+            AND referenced_name = name )
+    ORDER BY referenced_owner,
+        referenced_name
+`, ExcludedSchemaClause(ctx, "referenced_owner"))
+
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, query), schema, name)
+	if err != nil {
+		return l, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	for rows.Next() {
+		var q QualifiedName
+		err = rows.Scan(&q.Schema, &q.Name)
+		if err != nil {
+			return l, err
+		}
+		l = append(l, q)
+	}
+
+	return l, err
+}
+
+// ObjDependents returns the (non-system) objects that depend directly
+// on the specified object. Package and type bodies are returned as the
+// package or type.
+func ObjDependents(ctx context.Context, db Querier, schema, name string) ([]QualifiedName, error) {
+
+	var l []QualifiedName
+
+	query := fmt.Sprintf(`
+SELECT DISTINCT owner,
+        name
+    FROM dba_dependencies
+    WHERE referenced_owner = :1
+        AND referenced_name = :2
+        AND type IN (
+                'FUNCTION', 'MATERIALIZED VIEW', 'PACKAGE', 'PACKAGE BODY', 'PROCEDURE', 'SYNONYM', 'TABLE',
+                'TRIGGER', 'TYPE', 'TYPE BODY', 'VIEW' )
+        AND owner <> 'PUBLIC'
+        AND %s
+        AND NOT ( referenced_owner = owner
+            AND referenced_name = name )
+    ORDER BY owner,
+        name
+`, ExcludedSchemaClause(ctx, "owner"))
+
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, query), schema, name)
+	if err != nil {
+		return l, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	for rows.Next() {
+		var q QualifiedName
+		err = rows.Scan(&q.Schema, &q.Name)
+		if err != nil {
+			return l, err
+		}
+		l = append(l, q)
+	}
+
+	return l, err
+}
+
+// ObjRole returns the DDL for creating the specified role, the system
+// privileges granted to the role, the grants of the role to users and
+// other roles, and the object and column privileges granted to the role.
+func ObjRole(ctx context.Context, db Querier, name string) (string, error) {
+
+	queries := []string{`
+SELECT 'CREATE ROLE "' || role || '" ;'
+    FROM dba_roles
+    WHERE role = :1
+`, sysPrivsQuery, `
+SELECT 'GRANT "' || granted_role || '" TO "' || grantee || '"'
+            || CASE
+                WHEN admin_option = 'YES' THEN ' WITH ADMIN OPTION ;'
+                ELSE ' ;'
+                END AS stmt
+    FROM dba_role_privs
+    WHERE granted_role = :1
+    ORDER BY 1
+`, objPrivsQuery, colPrivsQuery}
+
+	return runQueries(ctx, db, queries, name)
+}
+
+// objPrivsQuery selects the GRANT statements for the object privileges
+// granted to a user or role on the objects of other schemas
+const objPrivsQuery = `
+SELECT 'GRANT ' || listagg ( privilege, ', ' ) WITHIN GROUP ( ORDER BY privilege )
+            || CASE
+                WHEN type = 'DIRECTORY' THEN ' ON DIRECTORY "' || table_name || '"'
+                ELSE ' ON "' || owner || '"."' || table_name || '"'
+                END
+            || ' TO "' || grantee || '"'
+            || CASE
+                WHEN grantable = 'YES' THEN ' WITH GRANT OPTION ;'
+                ELSE ' ;'
+                END AS stmt
+    FROM dba_tab_privs
+    WHERE grantee = :1
+        AND owner <> grantee
+    GROUP BY owner,
+        table_name,
+        type,
+        grantee,
+        grantable
+    ORDER BY 1
+`
+
+// colPrivsQuery selects the GRANT statements for the column privileges
+// granted to a user or role on the objects of other schemas
+const colPrivsQuery = `
+SELECT 'GRANT ' || privilege
+            || ' ( ' || listagg ( '"' || column_name || '"', ', ' ) WITHIN GROUP ( ORDER BY column_name ) || ' )'
+            || ' ON "' || owner || '"."' || table_name || '"'
+            || ' TO "' || grantee || '"'
+            || CASE
+                WHEN grantable = 'YES' THEN ' WITH GRANT OPTION ;'
+                ELSE ' ;'
+                END AS stmt
+    FROM dba_col_privs
+    WHERE grantee = :1
+        AND owner <> grantee
+    GROUP BY owner,
+        table_name,
+        privilege,
+        grantee,
+        grantable
+    ORDER BY 1
+`
+
+// SchemaReceivedPrivs returns the GRANT statements for the object and
+// column privileges that the specified schema has received on the
+// objects of other schemas. Unlike the grants on the objects in a
+// schema, these are the grants that need to be re-issued in order for
+// the objects in the schema to work when the schema is rebuilt.
+func SchemaReceivedPrivs(ctx context.Context, db Querier, schema string) (string, error) {
+	return runQueries(ctx, db, []string{objPrivsQuery, colPrivsQuery}, schema)
+}
+
+// sysPrivsQuery selects the GRANT statements for the system privileges
+// granted to a user or role
+const sysPrivsQuery = `
+SELECT 'GRANT ' || privilege || ' TO "' || grantee || '"'
+            || CASE
+                WHEN admin_option = 'YES' THEN ' WITH ADMIN OPTION ;'
+                ELSE ' ;'
+                END AS stmt
+    FROM dba_sys_privs
+    WHERE grantee = :1
+    ORDER BY 1
+`
+
+// SchemaSysPrivs returns the GRANT statements for the system privileges
+// (CREATE VIEW, CREATE JOB, etc.) held directly by the specified schema.
+func SchemaSysPrivs(ctx context.Context, db Querier, schema string) (string, error) {
+	return runQueryArgs(ctx, db, sysPrivsQuery, schema)
+}
+
+// ObjUser returns the DDL for creating the specified user along with
+// the tablespace quotas, granted roles, and system privileges for the
+// user. As passwords are not extracted, users that are identified by
+// password are created with a SQL*Plus substitution variable for the
+// password.
+func ObjUser(ctx context.Context, db Querier, name string) (string, error) {
+
+	// user_users, which replaces dba_users, has neither the profile nor
+	// the authentication type
+	if err := requireDbaView(ctx, "dba_users"); err != nil {
+		return "", err
+	}
+
+	queries := []string{`
+SELECT 'CREATE USER "' || username || '"'
+            || CASE
+                WHEN authentication_type = 'NONE' THEN ' NO AUTHENTICATION'
+                WHEN authentication_type = 'EXTERNAL' THEN ' IDENTIFIED EXTERNALLY'
+                WHEN authentication_type = 'GLOBAL' THEN ' IDENTIFIED GLOBALLY'
+                ELSE ' IDENTIFIED BY "&&' || username || '_PASSWORD"'
+                END
+            || chr ( 10 ) || '    DEFAULT TABLESPACE "' || default_tablespace || '"'
+            || chr ( 10 ) || '    TEMPORARY TABLESPACE "' || temporary_tablespace || '"'
+            || chr ( 10 ) || '    PROFILE "' || profile || '" ;'
+    FROM dba_users
+    WHERE username = :1
+`, `
+SELECT 'ALTER USER "' || username || '" QUOTA '
+            || CASE
+                WHEN max_bytes = -1 THEN 'UNLIMITED'
+                ELSE to_char ( max_bytes )
+                END
+            || ' ON "' || tablespace_name || '" ;'
+    FROM dba_ts_quotas
+    WHERE username = :1
+    ORDER BY tablespace_name
+`, `
+SELECT 'GRANT "' || granted_role || '" TO "' || grantee || '"'
+            || CASE
+                WHEN admin_option = 'YES' THEN ' WITH ADMIN OPTION ;'
+                ELSE ' ;'
+                END AS stmt
+    FROM dba_role_privs
+    WHERE grantee = :1
+    ORDER BY 1
+`, sysPrivsQuery}
+
+	return runQueries(ctx, db, queries, name)
+}
+
+// ObjDirectory returns the DDL for creating the specified directory
+// along with the grants on the directory.
+func ObjDirectory(ctx context.Context, db Querier, name string) (string, error) {
+
+	queries := []string{`
+SELECT 'CREATE OR REPLACE DIRECTORY "' || directory_name || '" AS '''
+            || regexp_replace ( directory_path, '''', '''''' )
+            || ''' ;'
+    FROM dba_directories
+    WHERE directory_name = :1
+`, `
+SELECT 'GRANT ' || listagg ( privilege, ', ' ) WITHIN GROUP ( ORDER BY privilege )
+            || ' ON DIRECTORY "' || table_name || '" TO "' || grantee || '"'
+            || CASE
+                WHEN grantable = 'YES' THEN ' WITH GRANT OPTION ;'
+                ELSE ' ;'
+                END AS stmt
+    FROM dba_tab_privs
+    WHERE table_name = :1
+        AND type = 'DIRECTORY'
+    GROUP BY table_name,
+        grantee,
+        grantable
+    ORDER BY 1
+`}
+
+	return runQueries(ctx, db, queries, name)
+}
+
+// SchemaDirectories returns the names of the directories referenced by
+// the specified schema, either by the external tables in the schema or
+// by way of grants on the directory to the schema.
+func SchemaDirectories(ctx context.Context, db Querier, schema string) ([]string, error) {
+
+	query := `
+WITH p AS (
+    SELECT :1 AS owner
+        FROM dual
+)
+SELECT t.default_directory_name
+    FROM dba_external_tables t
+    JOIN p
+        ON ( p.owner = t.owner )
+UNION
+SELECT x.directory_name
+    FROM dba_external_locations x
+    JOIN p
+        ON ( p.owner = x.owner )
+UNION
+SELECT g.table_name
+    FROM dba_tab_privs g
+    JOIN p
+        ON ( p.owner = g.grantee )
+    WHERE g.type = 'DIRECTORY'
+ORDER BY 1
+`
+
+	return queryNames(ctx, db, query, schema)
+}
+
+// SchemaRoles returns the names of the (non-Oracle maintained) roles
+// granted, either directly or by way of other roles, to the specified
+// schema.
+func SchemaRoles(ctx context.Context, db Querier, schema string) ([]string, error) {
+
+	query := `
+WITH granted AS (
+    SELECT DISTINCT granted_role
+        FROM dba_role_privs
+        START WITH grantee = :1
+        CONNECT BY NOCYCLE PRIOR granted_role = grantee
+)
+SELECT r.role
+    FROM dba_roles r
+    JOIN granted g
+        ON ( g.granted_role = r.role )
+    WHERE %s
+    ORDER BY r.role
+`
+
+	return queryNames(ctx, db, fmt.Sprintf(query, OracleMaintainedClause(ctx, "r", "role")), schema)
+}
+
+// SchemaTablespaces returns the names of the tablespaces referenced by
+// the specified schema, either by the segments owned by the schema, the
+// quotas for the schema, or as the default tablespace for the schema.
+func SchemaTablespaces(ctx context.Context, db Querier, schema string) ([]string, error) {
+
+	query := `
+WITH p AS (
+    SELECT :1 AS owner
+        FROM dual
+)
+SELECT s.tablespace_name
+    FROM dba_segments s
+    JOIN p
+        ON ( p.owner = s.owner )
+UNION
+SELECT q.tablespace_name
+    FROM dba_ts_quotas q
+    JOIN p
+        ON ( p.owner = q.username )
+UNION
+SELECT u.default_tablespace
+    FROM dba_users u
+    JOIN p
+        ON ( p.owner = u.username )
+ORDER BY 1
+`
+
+	return queryNames(ctx, db, query, schema)
+}
+
+// ObjTablespace returns the DDL for creating the specified tablespace.
+func ObjTablespace(ctx context.Context, db Querier, name string) (string, error) {
+	return ObjDDL(ctx, db, "", name, "TABLESPACE")
+}
+
+// queryNames returns the list of names returned by a query
+func queryNames(ctx context.Context, db Querier, query string, args ...interface{}) ([]string, error) {
+
+	var l []string
+
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, query), args...)
+	if err != nil {
+		return l, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	for rows.Next() {
+		var name string
+		err = rows.Scan(&name)
+		if err != nil {
+			return l, err
+		}
+		l = append(l, name)
+	}
+
+	return l, err
+}
+
+// runQueries runs each of the queries with the same arguments and
+// returns the combined results
+func runQueries(ctx context.Context, db Querier, queries []string, args ...interface{}) (string, error) {
+
+	var l []string
+
+	for _, query := range queries {
+		rslt, err := runQueryArgs(ctx, db, query, args...)
+		if err != nil {
+			return "", err
+		}
+		if rslt != "" {
+			l = appendLine(l, rslt)
+		}
+	}
+
+	return strings.Join(l, dblSpace()), nil
+}
+
+// ObjLockdownProfile returns the DDL for creating the specified PDB
+// lockdown profile along with the rules for the profile.
+func ObjLockdownProfile(ctx context.Context, db Querier, name string) (string, error) {
+
+	if err := requireDbaView(ctx, "dba_lockdown_profiles"); err != nil {
+		return "", err
+	}
+
+	query := `
+WITH p AS (
+    SELECT *
+        FROM dba_lockdown_profiles
+        WHERE profile_name = :1
+),
+rules AS (
+    SELECT 'ALTER LOCKDOWN PROFILE "' || profile_name || '" '
+                || status || ' '
+                || rule_type || ' = ( ''' || rule || ''' )'
+                || CASE
+                    WHEN clause IS NOT NULL THEN ' CLAUSE = ( ''' || clause || ''' )'
+                    END
+                || CASE
+                    WHEN clause_option IS NOT NULL THEN ' OPTION = ( ''' || clause_option || ''' )'
+                    END
+                || CASE
+                    WHEN users IN ( 'COMMON', 'LOCAL' ) THEN ' USERS = ' || users
+                    END
+                || ' ;' AS stmt,
+            2 AS seq
+        FROM p
+        WHERE rule IS NOT NULL
+    UNION
+    SELECT 'CREATE LOCKDOWN PROFILE "' || profile_name || '" ;' AS stmt,
+            1 AS seq
+        FROM p
+)
+SELECT stmt
+    FROM rules
+    ORDER BY seq,
+        stmt
+`
+	return runQueryArgs(ctx, db, query, name)
+}
+
+// ObjSequenceCurrentValue returns the value that the specified sequence
+// should be restarted with in order to not re-issue any values that may
+// already have been used. For cached sequences dba_sequences.last_number
+// is the high-water mark of the current cache so the cache size is
+// added. For NOCACHE and ORDER sequences last_number is the next value
+// to be issued.
+func ObjSequenceCurrentValue(ctx context.Context, db Querier, schema, name string) (int64, error) {
+
+	query := `
+SELECT CASE
+            WHEN cache_size = 0 OR order_flag = 'Y' THEN last_number
+            ELSE last_number + ( cache_size * increment_by )
+            END AS restart_value
+    FROM dba_sequences
+    WHERE sequence_owner = :1
+        AND sequence_name = :2
+`
+
+	var value int64
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, query), schema, name)
+	if err != nil {
+		return value, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	if rows.Next() {
+		err = rows.Scan(&value)
+	} else {
+		err = fmt.Errorf("no sequence found for %q.%q", schema, name)
+	}
+	return value, err
+}
+
+// ObjSequenceRestart returns the ALTER SEQUENCE statement for restarting
+// the specified sequence at its current value (see
+// ObjSequenceCurrentValue).
+func ObjSequenceRestart(ctx context.Context, db Querier, schema, name string) (string, error) {
+
+	value, err := ObjSequenceCurrentValue(ctx, db, schema, name)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("ALTER SEQUENCE \"%s\".\"%s\" RESTART WITH %d ;", schema, name, value), nil
+}
+
+// ObjLastDDLTime returns the time that DDL was last applied to the
+// specified object.
+func ObjLastDDLTime(ctx context.Context, db Querier, schema, name, objType string) (time.Time, error) {
+
+	query := `
+SELECT last_ddl_time
+    FROM dba_objects
+    WHERE owner = :1
+        AND object_name = :2
+        AND object_type = :3
+`
+
+	// Queue tables are tables as far as dba_objects is concerned
+	if objType == typeQueueTable {
+		objType = typeTable
+	}
+
+	var t time.Time
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, query), schema, name, objType)
+	if err != nil {
+		return t, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	if rows.Next() {
+		err = rows.Scan(&t)
+	}
+	return t, err
+}
+
+// ObjJobClass returns the DDL for creating the specified scheduler job
+// class. Job classes are always owned by SYS.
+func ObjJobClass(ctx context.Context, db Querier, name string) (string, error) {
+	return ObjDDL(ctx, db, "SYS", name, typeJobClass)
+}
+
+// ObjWindow returns the DDL for creating the specified scheduler
+// window. Windows are always owned by SYS.
+func ObjWindow(ctx context.Context, db Querier, name string) (string, error) {
+	return ObjDDL(ctx, db, "SYS", name, typeWindow)
 }
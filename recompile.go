@@ -0,0 +1,115 @@
+package oradex
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// InvalidObjects returns the objects in the specified schema that are
+// INVALID. Package and type bodies are returned separately from the
+// package or type specification.
+func InvalidObjects(ctx context.Context, db Querier, schema string) ([]DDLResult, error) {
+
+	var l []DDLResult
+
+	query := `
+SELECT owner,
+        object_name,
+        object_type
+    FROM dba_objects
+    WHERE owner = :1
+        AND status = 'INVALID'
+        AND object_name NOT LIKE 'BIN$%'
+    ORDER BY object_name,
+        object_type
+`
+
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, query), schema)
+	if err != nil {
+		return l, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	for rows.Next() {
+		var v DDLResult
+		err = rows.Scan(&v.Schema, &v.Name, &v.ObjType)
+		if err != nil {
+			return l, err
+		}
+		l = append(l, v)
+	}
+
+	return l, err
+}
+
+// CompileDDL returns the ALTER ... COMPILE statement for recompiling the
+// specified object
+func CompileDDL(schema, name, objType string) string {
+
+	switch objType {
+	case "PACKAGE BODY", "TYPE BODY":
+		return fmt.Sprintf("ALTER %s \"%s\".\"%s\" COMPILE BODY ;", strings.TrimSuffix(objType, " BODY"), schema, name)
+	case "JAVA CLASS":
+		return fmt.Sprintf("ALTER JAVA CLASS \"%s\".\"%s\" RESOLVE ;", schema, name)
+	case typeSynonym:
+		if schema == "PUBLIC" {
+			return fmt.Sprintf("ALTER PUBLIC SYNONYM \"%s\" COMPILE ;", name)
+		}
+	}
+
+	return fmt.Sprintf("ALTER %s \"%s\".\"%s\" COMPILE ;", objType, schema, name)
+}
+
+// CompileSchemaCommand returns the SQL*Plus command for compiling the
+// invalid objects of the schema using DBMS_UTILITY
+func CompileSchemaCommand(schema string) string {
+	return fmt.Sprintf("EXEC dbms_utility.compile_schema ( schema => %s, compile_all => FALSE )", quoteLiteral(schema))
+}
+
+// RecompileScript returns the script for recompiling the invalid objects
+// in the specified schema. The objects are recompiled in dependency
+// order using ALTER ... COMPILE statements or, if utlRecomp is set, by
+// using UTL_RECOMP to recompile the schema. An empty string is returned
+// if there are no invalid objects.
+func RecompileScript(ctx context.Context, db Querier, schema string, utlRecomp bool) (string, error) {
+
+	invalid, err := InvalidObjects(ctx, db, schema)
+	if err != nil || len(invalid) == 0 {
+		return "", err
+	}
+
+	if utlRecomp {
+		return fmt.Sprintf("BEGIN\n    utl_recomp.recomp_serial ( %s ) ;\nEND ;\n/", quoteLiteral(schema)), nil
+	}
+
+	// Objects of different types may share a name (i.e. a package and
+	// the package body) so there may be more than one object for a name
+	var names []QualifiedName
+	objs := make(map[QualifiedName][]DDLResult)
+	for _, v := range invalid {
+		q := QualifiedName{Schema: v.Schema, Name: v.Name}
+		if _, ok := objs[q]; !ok {
+			names = append(names, q)
+		}
+		objs[q] = append(objs[q], v)
+	}
+
+	ordered, err := OrderByDependency(ctx, db, names)
+	if err != nil {
+		return "", err
+	}
+
+	var l []string
+	for _, q := range ordered {
+		for _, v := range objs[q] {
+			l = append(l, CompileDDL(v.Schema, v.Name, v.ObjType))
+		}
+	}
+
+	return strings.Join(l, "\n"), nil
+}
@@ -0,0 +1,121 @@
+package oradex
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ParseSchemaRemap parses a comma separated list of OLD:NEW schema
+// mappings (i.e. PROD_APP:DEV_APP,PROD_API:DEV_API) into a map.
+func ParseSchemaRemap(s string) (map[string]string, error) {
+	return parseRemap(s, "schema")
+}
+
+// ParseTablespaceRemap parses a comma separated list of OLD:NEW
+// tablespace mappings (i.e. USERS:&&DATA_TS,*:APP_DATA) into a map (see
+// RemapTablespaces).
+func ParseTablespaceRemap(s string) (map[string]string, error) {
+	return parseRemap(s, "tablespace")
+}
+
+// parseRemap parses a comma separated list of OLD:NEW mappings of the
+// specified kind into a map.
+func parseRemap(s, kind string) (map[string]string, error) {
+
+	m := make(map[string]string)
+
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		p := strings.Split(v, ":")
+		if len(p) != 2 || p[0] == "" || p[1] == "" {
+			return m, fmt.Errorf("invalid %s remap %q, expected OLD:NEW", kind, v)
+		}
+		m[strings.ToUpper(p[0])] = strings.ToUpper(p[1])
+	}
+
+	return m, nil
+}
+
+// RemapSchemas rewrites the schema qualified names, and the grantees of
+// grants, in the DDL according to the remap (old schema to new schema).
+// Only quoted identifiers, as generated by DBMS_METADATA and by the
+// other queries, are rewritten. All of the schemas are remapped in a
+// single pass so that the result does not depend on the order of the
+// remap (i.e. when swapping two schemas).
+func RemapSchemas(ddl string, remap map[string]string) string {
+
+	if len(remap) == 0 {
+		return ddl
+	}
+
+	var l []string
+	for from := range remap {
+		l = append(l, regexp.QuoteMeta(from))
+	}
+	sort.Strings(l)
+	names := `"(` + strings.Join(l, "|") + `)"`
+
+	// "OLD"."OBJECT"
+	ddl = regexp.MustCompile(names+`\.`).ReplaceAllStringFunc(ddl, func(s string) string {
+		return `"` + remap[s[1:len(s)-2]] + `".`
+	})
+
+	// GRANT ... TO "OLD", REVOKE ... FROM "OLD"
+	re := regexp.MustCompile(`(?i)\b(TO|FROM)(\s+)` + names)
+	return re.ReplaceAllStringFunc(ddl, func(s string) string {
+		m := re.FindStringSubmatch(s)
+		return m[1] + m[2] + `"` + remap[m[3]] + `"`
+	})
+}
+
+// RemapTablespaces rewrites the TABLESPACE clauses in the DDL according
+// to the remap (old tablespace to new tablespace). A remap from "*"
+// applies to all tablespaces not otherwise remapped. New tablespace names
+// starting with "&" are taken to be SQL*Plus substitution variables (i.e.
+// &&DATA_TS) and are not quoted.
+func RemapTablespaces(ddl string, remap map[string]string) string {
+
+	re := regexp.MustCompile(`(?i)\bTABLESPACE(\s+)"([^"]+)"`)
+
+	return re.ReplaceAllStringFunc(ddl, func(s string) string {
+		m := re.FindStringSubmatch(s)
+
+		to, ok := remap[m[2]]
+		if !ok {
+			to, ok = remap["*"]
+		}
+		if !ok {
+			return s
+		}
+
+		if strings.HasPrefix(to, "&") {
+			return "TABLESPACE" + m[1] + to
+		}
+		return "TABLESPACE" + m[1] + `"` + to + `"`
+	})
+}
+
+// StripSchema removes the schema qualification from the names of the
+// objects in the specified schema so that the DDL may be deployed to
+// whichever schema is current.
+func StripSchema(ddl, schema string) string {
+	return strings.Replace(ddl, `"`+schema+`".`, "", -1)
+}
+
+// currentSchemaDDL returns the statement for setting the current schema
+func currentSchemaDDL(schema string) string {
+	return fmt.Sprintf("ALTER SESSION SET CURRENT_SCHEMA = \"%s\" ;", schema)
+}
+
+// remapName returns the new schema name for a schema
+func remapName(schema string, remap map[string]string) string {
+	if to, ok := remap[schema]; ok {
+		return to
+	}
+	return schema
+}
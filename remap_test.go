@@ -0,0 +1,118 @@
+package oradex
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSchemaRemap(t *testing.T) {
+
+	tests := []struct {
+		in      string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"", map[string]string{}, false},
+		{"prod_app:dev_app", map[string]string{"PROD_APP": "DEV_APP"}, false},
+		{" A:B , C:D ,", map[string]string{"A": "B", "C": "D"}, false},
+		{"A", nil, true},
+		{"A:", nil, true},
+		{":B", nil, true},
+		{"A:B:C", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseSchemaRemap(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemapSchemas(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		ddl   string
+		remap map[string]string
+		want  string
+	}{
+		{
+			"none",
+			`CREATE TABLE "APP"."T" ( "C" NUMBER )`,
+			nil,
+			`CREATE TABLE "APP"."T" ( "C" NUMBER )`,
+		},
+		{
+			"qualified names",
+			`CREATE VIEW "APP"."V" AS SELECT * FROM "APP"."T", "APPX"."T"`,
+			map[string]string{"APP": "DEV"},
+			`CREATE VIEW "DEV"."V" AS SELECT * FROM "DEV"."T", "APPX"."T"`,
+		},
+		{
+			"grantees",
+			`GRANT SELECT ON "APP"."T" TO "API"` + "\n" + `REVOKE SELECT ON "APP"."T" FROM  "API"`,
+			map[string]string{"API": "DEV_API"},
+			`GRANT SELECT ON "APP"."T" TO "DEV_API"` + "\n" + `REVOKE SELECT ON "APP"."T" FROM  "DEV_API"`,
+		},
+		{
+			"swap",
+			`ALTER TABLE "A"."T" ADD CONSTRAINT "FK" FOREIGN KEY ("C") REFERENCES "B"."T" ("C")`,
+			map[string]string{"A": "B", "B": "A"},
+			`ALTER TABLE "B"."T" ADD CONSTRAINT "FK" FOREIGN KEY ("C") REFERENCES "A"."T" ("C")`,
+		},
+		{
+			"names with $",
+			`GRANT EXECUTE ON "APEX$TEAM"."P" TO "APEX$TEAM"`,
+			map[string]string{"APEX$TEAM": "DEV$TEAM"},
+			`GRANT EXECUTE ON "DEV$TEAM"."P" TO "DEV$TEAM"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RemapSchemas(tt.ddl, tt.remap); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemapTablespaces(t *testing.T) {
+
+	ddl := `CREATE TABLE "APP"."T" ( "C" NUMBER )
+  TABLESPACE "USERS"
+  LOB ( "D" ) STORE AS SECUREFILE ( TABLESPACE "USERS" )`
+
+	tests := []struct {
+		name  string
+		remap string
+		want  string
+	}{
+		{"none", "", `TABLESPACE "USERS"`},
+		{"named", "USERS:APP_DATA", `TABLESPACE "APP_DATA"`},
+		{"all", "*:APP_DATA", `TABLESPACE "APP_DATA"`},
+		{"named before all", "USERS:APP_DATA,*:OTHER", `TABLESPACE "APP_DATA"`},
+		{"other", "SYSAUX:APP_DATA", `TABLESPACE "USERS"`},
+		{"substitution variable", "*:&&DATA_TS", `TABLESPACE &&DATA_TS`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remap, err := ParseTablespaceRemap(tt.remap)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := RemapTablespaces(ddl, remap)
+			if n := strings.Count(got, tt.want); n != 2 {
+				t.Errorf("found %q %d times, want 2, in %q", tt.want, n, got)
+			}
+		})
+	}
+}
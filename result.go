@@ -0,0 +1,144 @@
+package oradex
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ObjectError is an error encountered while exporting an object.
+// Partial is set when the DDL for the object itself was exported and
+// only some of the supporting DDL is missing, in which case Err wraps
+// each of the errors encountered.
+type ObjectError struct {
+	Schema  string
+	Name    string
+	ObjType string
+	Err     error
+	Partial bool
+}
+
+func (e ObjectError) Error() string {
+	if e.ObjType == "" {
+		return fmt.Sprintf("%q.%q: %s", e.Schema, e.Name, e.Err)
+	}
+	return fmt.Sprintf("%s %q.%q: %s", e.ObjType, e.Schema, e.Name, e.Err)
+}
+
+func (e ObjectError) Unwrap() error {
+	return e.Err
+}
+
+// ObjectResult describes the export of a single object. Errors contains
+// the non-fatal errors encountered while retrieving the supporting DDL
+// (grants, comments, indices, etc.) for the object. Warnings contains
+// any warnings from downgrading the DDL.
+type ObjectResult struct {
+	Schema   string
+	Name     string
+	ObjType  string
+	Errors   []error
+	Warnings []string
+	Elapsed  time.Duration
+}
+
+// note records, and reports, a non-fatal error
+func (r *ObjectResult) note(err error) {
+	if err != nil {
+		logger.Error(err.Error(), r.logFields()...)
+		r.Errors = append(r.Errors, err)
+	}
+}
+
+// warn records, and reports, a warning
+func (r *ObjectResult) warn(w string) {
+	logger.Warn(w, r.logFields()...)
+	r.Warnings = append(r.Warnings, w)
+}
+
+// logFields returns the context fields that identify the object for
+// logging
+func (r *ObjectResult) logFields() []interface{} {
+	return []interface{}{"schema", r.Schema, "name", r.Name, "type", r.ObjType}
+}
+
+// ExtractionResult summarizes the extraction of the objects for a schema.
+// ObjectTypes is the count of the objects by type and Skipped is the
+// count of the objects that were deliberately not extracted (i.e. when
+// resuming an interrupted extraction).
+type ExtractionResult struct {
+	Schema        string
+	ObjectCount   int
+	ObjectTypes   map[string]int
+	Skipped       int
+	FailedObjects []ObjectError
+	Elapsed       time.Duration
+}
+
+// Add adds the result of exporting an object to the extraction result.
+// The object is counted as failed, once, if err is not nil or if any
+// non-fatal errors were encountered while exporting it, in which case
+// it is only partially failed. Errors that are already ObjectErrors are
+// recorded as is.
+func (r *ExtractionResult) Add(o ObjectResult, err error) {
+
+	r.ObjectCount++
+	if r.ObjectTypes == nil {
+		r.ObjectTypes = make(map[string]int)
+	}
+	r.ObjectTypes[o.ObjType]++
+
+	switch {
+	case err != nil:
+		var oe ObjectError
+		if !errors.As(err, &oe) {
+			oe = ObjectError{Schema: o.Schema, Name: o.Name, ObjType: o.ObjType, Err: err}
+		}
+		r.FailedObjects = append(r.FailedObjects, oe)
+	case len(o.Errors) > 0:
+		r.FailedObjects = append(r.FailedObjects, ObjectError{
+			Schema:  o.Schema,
+			Name:    o.Name,
+			ObjType: o.ObjType,
+			Err:     errors.Join(o.Errors...),
+			Partial: true,
+		})
+	}
+}
+
+// Missing returns the failed objects for which the DDL of the object
+// itself could not be exported, as opposed to those that are only
+// missing some of the supporting DDL
+func (r ExtractionResult) Missing() []ObjectError {
+	var l []ObjectError
+	for _, e := range r.FailedObjects {
+		if !e.Partial {
+			l = append(l, e)
+		}
+	}
+	return l
+}
+
+// TimedOut returns the objects that were skipped as they exceeded the
+// object timeout
+func (r ExtractionResult) TimedOut() []ObjectError {
+	var l []ObjectError
+	for _, e := range r.FailedObjects {
+		if errors.Is(e.Err, ErrObjectTimeout) {
+			l = append(l, e)
+		}
+	}
+	return l
+}
+
+// String returns a one line summary of the extraction result
+func (r ExtractionResult) String() string {
+	s := fmt.Sprintf("%s: %d objects, %d failed", r.Schema, r.ObjectCount, len(r.FailedObjects))
+	if n := len(r.FailedObjects) - len(r.Missing()); n > 0 {
+		s += fmt.Sprintf(" (%d partially)", n)
+	}
+	if n := len(r.TimedOut()); n > 0 {
+		s += fmt.Sprintf(" (%d timed out)", n)
+	}
+	return s + fmt.Sprintf(", %s", r.Elapsed.Round(time.Millisecond))
+}
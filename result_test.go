@@ -0,0 +1,40 @@
+package oradex
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExtractionResultAdd(t *testing.T) {
+
+	errGrants := errors.New("grants")
+	errComments := errors.New("comments")
+	errDDL := errors.New("ddl")
+
+	var r ExtractionResult
+	r.Add(ObjectResult{Schema: "APP", Name: "ORDERS", ObjType: typeTable}, nil)
+	r.Add(ObjectResult{Schema: "APP", Name: "OPEN_ORDERS", ObjType: typeView, Errors: []error{errGrants, errComments}}, nil)
+	r.Add(ObjectResult{Schema: "APP", Name: "ORDER_API", ObjType: typePackage}, objectError("APP", "ORDER_API", typePackage, errDDL))
+
+	if r.ObjectCount != 3 {
+		t.Errorf("got %d objects, want 3", r.ObjectCount)
+	}
+
+	// One failure per object
+	if n := len(r.FailedObjects); n != 2 {
+		t.Fatalf("got %d failed objects, want 2", n)
+	}
+
+	partial := r.FailedObjects[0]
+	if !partial.Partial || partial.Name != "OPEN_ORDERS" {
+		t.Errorf("got %+v, want OPEN_ORDERS partially failed", partial)
+	}
+	if !errors.Is(partial, errGrants) || !errors.Is(partial, errComments) {
+		t.Errorf("got %v, want both of the supporting DDL errors", partial.Err)
+	}
+
+	missing := r.Missing()
+	if len(missing) != 1 || missing[0].Name != "ORDER_API" || !errors.Is(missing[0], errDDL) {
+		t.Errorf("got %v, want ORDER_API missing", missing)
+	}
+}
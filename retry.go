@@ -0,0 +1,105 @@
+package oradex
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/godror/godror"
+)
+
+// DefaultRetryBackoff is the wait before the first retry when no backoff
+// is specified. The wait doubles for each subsequent retry.
+const DefaultRetryBackoff = 2 * time.Second
+
+// transientOraCodes are the ORA errors that are expected to succeed when
+// retried: library cache locks and pins, deadlocks, discarded package
+// state, user requested cancels, and lost connections
+var transientOraCodes = map[int]bool{
+	60:    true, // deadlock detected while waiting for resource
+	1013:  true, // user requested cancel of current operation
+	3113:  true, // end-of-file on communication channel
+	3114:  true, // not connected to ORACLE
+	3135:  true, // connection lost contact
+	4020:  true, // deadlock detected while trying to lock object
+	4021:  true, // timeout occurred while waiting to lock object
+	4061:  true, // existing state of package has been invalidated
+	4068:  true, // existing state of packages has been discarded
+	12537: true, // TNS:connection closed
+	12570: true, // TNS:packet reader failure
+	25408: true, // can not safely replay call
+}
+
+// IsTransient returns true if the error is one that may succeed if the
+// operation is retried
+func IsTransient(err error) bool {
+
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	if oe, ok := godror.AsOraErr(err); ok {
+		return transientOraCodes[oe.Code()]
+	}
+	return false
+}
+
+// ErrObjectTimeout is the error for objects that were skipped as the
+// extraction exceeded the object timeout (see ExportOptions.ObjectTimeout)
+var ErrObjectTimeout = errors.New("timed out extracting the object")
+
+// retry calls fn until it succeeds, fails with an error that is not
+// transient, or the retries (see ExportOptions.Retries) are exhausted,
+// waiting with an exponential backoff between attempts. Attempts that
+// time out are not retried. The transient errors that were retried are
+// returned for reporting as warnings.
+func (e *Extractor) retry(ctx context.Context, fn func(context.Context) error) ([]string, error) {
+
+	var retried []string
+
+	wait := e.opts.RetryBackoff
+	if wait <= 0 {
+		wait = DefaultRetryBackoff
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := e.attempt(ctx, fn)
+		if err == nil || attempt >= e.opts.Retries || !IsTransient(err) || ctx.Err() != nil {
+			return retried, err
+		}
+
+		retried = append(retried, fmt.Sprintf("retrying after transient error: %s", err))
+		logger.Warn("retrying after transient error", "attempt", attempt+1, "wait", wait, "error", err)
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return retried, ctx.Err()
+		case <-t.C:
+		}
+		wait *= 2
+	}
+}
+
+// attempt calls fn, limited to the object timeout if one is set. The
+// error for an attempt that times out is ErrObjectTimeout.
+func (e *Extractor) attempt(ctx context.Context, fn func(context.Context) error) error {
+
+	if e.opts.ObjectTimeout <= 0 {
+		return fn(ctx)
+	}
+
+	tctx, cancel := context.WithTimeout(ctx, e.opts.ObjectTimeout)
+	defer cancel()
+
+	err := fn(tctx)
+	if err != nil && ctx.Err() == nil && errors.Is(tctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s", ErrObjectTimeout, e.opts.ObjectTimeout)
+	}
+	return err
+}
@@ -0,0 +1,117 @@
+package oradex
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// RewriteRule is a user defined rewrite of the extracted DDL, i.e. for
+// stripping COLLATE clauses, renaming database links, or replacing
+// environment specific strings. The replacement may refer to the
+// submatches of the pattern ($1, ${name}) and, as a text/template, to
+// the {{.Schema}}, {{.Name}}, and {{.Type}} of the object. Types, when
+// set, limits the rule to the objects of those types.
+type RewriteRule struct {
+	Pattern *regexp.Regexp
+	Replace string
+	Types   []string
+
+	tmpl *template.Template
+}
+
+// NewRewriteRule compiles a rewrite rule
+func NewRewriteRule(pattern, replace string, types []string) (RewriteRule, error) {
+
+	var r RewriteRule
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return r, fmt.Errorf("invalid rewrite pattern %q: %s", pattern, err)
+	}
+	r = RewriteRule{Pattern: re, Replace: replace, Types: types}
+
+	if strings.Contains(replace, "{{") {
+		r.tmpl, err = template.New("rewrite").Option("missingkey=error").Parse(replace)
+		if err != nil {
+			return r, fmt.Errorf("invalid rewrite replacement %q: %s", replace, err)
+		}
+		// Ensure that the template only uses the available fields
+		_, err = r.replacement("S", "N", "T")
+		if err != nil {
+			return r, fmt.Errorf("invalid rewrite replacement %q: %s", replace, err)
+		}
+	}
+
+	return r, nil
+}
+
+// replacement returns the replacement for the object. The values of the
+// template fields have any $ escaped so that names such as APEX$TEAM are
+// not taken as references to submatches of the pattern.
+func (r RewriteRule) replacement(schema, name, objType string) (string, error) {
+
+	if r.tmpl == nil {
+		return r.Replace, nil
+	}
+
+	esc := func(s string) string {
+		return strings.Replace(s, "$", "$$", -1)
+	}
+
+	var b bytes.Buffer
+	err := r.tmpl.Execute(&b, struct {
+		Schema string
+		Name   string
+		Type   string
+	}{esc(schema), esc(name), esc(objType)})
+	return b.String(), err
+}
+
+// RewriteDDL applies the rewrite rules, in order, to the DDL for the
+// specified object
+func RewriteDDL(ddl, schema, name, objType string, rules []RewriteRule) string {
+
+	if ddl == "" {
+		return ddl
+	}
+
+	for _, r := range rules {
+		if r.Pattern == nil || (len(r.Types) > 0 && !containsType(r.Types, objType)) {
+			continue
+		}
+		replace, err := r.replacement(schema, name, objType)
+		if err != nil {
+			continue
+		}
+		ddl = r.Pattern.ReplaceAllString(ddl, replace)
+	}
+
+	return ddl
+}
+
+// applyRules applies the rewrite rules to all of the DDL for the object
+func (e *Extractor) applyRules(o *Object) {
+
+	rules := e.opts.RewriteRules
+	if len(rules) == 0 {
+		return
+	}
+
+	rewrite := func(s string) string {
+		return RewriteDDL(s, o.Owner, o.Name, o.Type, rules)
+	}
+
+	o.DropDDL = rewrite(o.DropDDL)
+	o.CreateDDL = rewrite(o.CreateDDL)
+	for i, cmd := range o.AlterDDL {
+		o.AlterDDL[i] = rewrite(cmd)
+	}
+	o.Indexes = rewrite(o.Indexes)
+	o.Comments = rewrite(o.Comments)
+	o.ColumnComments = rewrite(o.ColumnComments)
+	o.Triggers = rewrite(o.Triggers)
+	o.Synonyms = rewrite(o.Synonyms)
+}
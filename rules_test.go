@@ -0,0 +1,108 @@
+package oradex
+
+import "testing"
+
+func TestNewRewriteRule(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		pattern string
+		replace string
+		wantErr bool
+	}{
+		{"plain", `COLLATE "USING_NLS_COMP"`, "", false},
+		{"template", `@PROD\b`, "@{{.Schema}}_LINK", false},
+		{"invalid pattern", `(`, "", true},
+		{"invalid template", `x`, "{{.Schema", true},
+		{"unknown field", `x`, "{{.Owner}}", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRewriteRule(tt.pattern, tt.replace, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRewriteDDL(t *testing.T) {
+
+	rule := func(pattern, replace string, types ...string) RewriteRule {
+		r, err := NewRewriteRule(pattern, replace, types)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	tests := []struct {
+		name    string
+		ddl     string
+		schema  string
+		objName string
+		objType string
+		rules   []RewriteRule
+		want    string
+	}{
+		{
+			"no rules",
+			`CREATE TABLE "APP"."T" ( "C" VARCHAR2(10) COLLATE "USING_NLS_COMP" )`,
+			"APP", "T", typeTable,
+			nil,
+			`CREATE TABLE "APP"."T" ( "C" VARCHAR2(10) COLLATE "USING_NLS_COMP" )`,
+		},
+		{
+			"strip",
+			`CREATE TABLE "APP"."T" ( "C" VARCHAR2(10) COLLATE "USING_NLS_COMP" )`,
+			"APP", "T", typeTable,
+			[]RewriteRule{rule(`\s+COLLATE "USING_NLS_COMP"`, "")},
+			`CREATE TABLE "APP"."T" ( "C" VARCHAR2(10) )`,
+		},
+		{
+			"submatch",
+			`SELECT * FROM t@prod_link`,
+			"APP", "V", typeView,
+			[]RewriteRule{rule(`@(\w+)_link`, "@${1}_dev")},
+			`SELECT * FROM t@prod_dev`,
+		},
+		{
+			"in order",
+			`a`,
+			"APP", "V", typeView,
+			[]RewriteRule{rule(`a`, "b"), rule(`b`, "c")},
+			`c`,
+		},
+		{
+			"other type",
+			`SELECT * FROM t@prod_link`,
+			"APP", "V", typeView,
+			[]RewriteRule{rule(`@prod_link`, "", typeTable)},
+			`SELECT * FROM t@prod_link`,
+		},
+		{
+			"template",
+			`-- owner`,
+			"APP", "V", typeView,
+			[]RewriteRule{rule(`owner`, "{{.Schema}}.{{.Name}} ({{.Type}})")},
+			`-- APP.V (VIEW)`,
+		},
+		{
+			"template with $",
+			`-- owner`,
+			"APEX$TEAM", "T$1", typeTable,
+			[]RewriteRule{rule(`(owner)`, "{{.Schema}}.{{.Name}} $1")},
+			`-- APEX$TEAM.T$1 owner`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RewriteDDL(tt.ddl, tt.schema, tt.objName, tt.objType, tt.rules)
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
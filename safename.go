@@ -0,0 +1,105 @@
+package oradex
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// MaxFileNameLength is the length, in bytes, that escaped names are
+// shortened to so that, along with the suffix and extension, the file
+// names stay within the 255 byte limit of most file systems
+const MaxFileNameLength = 200
+
+// MaxPathLength is the length, in bytes, of the longest path that files
+// are written to, being the PATH_MAX of Linux less the terminating NUL
+const MaxPathLength = 4095
+
+// windowsReservedNames are the device names that can not be used as file
+// names on Windows, with or without an extension
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// SafeFileName escapes a (schema or object) name for use in a file path.
+// Characters other than ASCII letters, digits, and _ $ # - . are percent
+// encoded, as are leading and trailing periods and the last character of
+// Windows reserved names, so that names containing slashes, spaces,
+// quotes, or multibyte characters can neither break nor escape the path.
+// The escaping is reversible (see UnescapeFileName) unless the name is
+// longer than MaxFileNameLength, in which case it is shortened and a
+// hash of the full name is appended to keep it unique.
+func SafeFileName(name string) string {
+
+	s := escapeFileName(name)
+	if len(s) > MaxFileNameLength {
+		// Avoid splitting an escaped character
+		cut := MaxFileNameLength - 17
+		if i := strings.LastIndexByte(s[:cut], '%'); i >= 0 && i > cut-3 {
+			cut = i
+		}
+		sum := sha256.Sum256([]byte(name))
+		s = fmt.Sprintf("%s~%x", s[:cut], sum[:8])
+	}
+
+	return s
+}
+
+// escapeFileName escapes a name as for SafeFileName without shortening
+// it
+func escapeFileName(name string) string {
+
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+			b.WriteByte(c)
+		case c == '_' || c == '$' || c == '#' || c == '-':
+			b.WriteByte(c)
+		case c == '.' && i > 0 && i < len(name)-1:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	s := b.String()
+
+	stem := strings.ToUpper(strings.SplitN(s, ".", 2)[0])
+	if windowsReservedNames[stem] {
+		n := len(stem) - 1
+		s = s[:n] + fmt.Sprintf("%%%02X", s[n]) + s[n+1:]
+	}
+
+	return s
+}
+
+// CaseSafeFileName escapes a name as for SafeFileName and, if the name
+// has any lower case letters, appends a hash of the name so that quoted
+// names that differ only in case (i.e. "Foo" and FOO) do not collide on
+// case-insensitive file systems or when the file names are lowercased.
+// Shortened names already end with a hash of the name.
+func CaseSafeFileName(name string) string {
+
+	s := escapeFileName(name)
+	if len(s) > MaxFileNameLength {
+		return SafeFileName(name)
+	}
+	if strings.IndexFunc(name, func(r rune) bool { return r >= 'a' && r <= 'z' }) < 0 {
+		return s
+	}
+
+	sum := sha256.Sum256([]byte(name))
+	return fmt.Sprintf("%s~%x", s, sum[:4])
+}
+
+// UnescapeFileName returns the name that was escaped by SafeFileName.
+// Shortened names can not be unescaped.
+func UnescapeFileName(s string) (string, error) {
+	return url.PathUnescape(s)
+}
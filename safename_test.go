@@ -0,0 +1,84 @@
+package oradex
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSafeFileName(t *testing.T) {
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"ORDERS", "ORDERS"},
+		{"APEX$TEAM#1-X", "APEX$TEAM#1-X"},
+		{"BIN$abc==$0", "BIN$abc%3D%3D$0"},
+		{"a/b", "a%2Fb"},
+		{"../x", "%2E.%2Fx"},
+		{"x.", "x%2E"},
+		{"a.b", "a.b"},
+		{"My Table", "My%20Table"},
+		{"CON", "CO%4E"},
+		{"com1.sql", "com%31.sql"},
+		{"CONSOLE", "CONSOLE"},
+		{"CAFÉ", "CAF%C3%89"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SafeFileName(tt.name)
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+			name, err := UnescapeFileName(got)
+			if err != nil || name != tt.name {
+				t.Errorf("unescaped to %q (%v)", name, err)
+			}
+		})
+	}
+}
+
+func TestSafeFileNameLength(t *testing.T) {
+
+	long := strings.Repeat("é", 128)
+	a := SafeFileName(long)
+	b := SafeFileName(long[:len(long)-2] + "e")
+
+	if len(a) > MaxFileNameLength || len(b) > MaxFileNameLength {
+		t.Fatalf("too long: %d, %d", len(a), len(b))
+	}
+	if a == b {
+		t.Errorf("shortened names collide: %q", a)
+	}
+	if i := strings.IndexByte(a, '~'); i < 0 || strings.LastIndexByte(a[:i], '%') > i-3 {
+		t.Errorf("split escaped character: %q", a)
+	}
+}
+
+func TestCaseSafeFileName(t *testing.T) {
+
+	tests := []struct {
+		a, b string
+	}{
+		{"Foo", "FOO"},
+		{"Foo", "foo"},
+		{"Foo", "fOO"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			a, b := CaseSafeFileName(tt.a), CaseSafeFileName(tt.b)
+			if strings.EqualFold(a, b) {
+				t.Errorf("%q and %q collide", a, b)
+			}
+		})
+	}
+
+	if got := CaseSafeFileName("FOO"); got != "FOO" {
+		t.Errorf("upper case name changed to %q", got)
+	}
+	if got, want := CaseSafeFileName(strings.Repeat("a", 300)), SafeFileName(strings.Repeat("a", 300)); got != want {
+		t.Errorf("shortened name got %q, want %q", got, want)
+	}
+}
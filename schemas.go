@@ -0,0 +1,81 @@
+package oradex
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// excludedSchemas is the default list of Oracle supplied/maintained
+// schemas that are excluded from extraction. Entries ending in % are
+// prefix matches.
+var excludedSchemas = []string{
+	"ANONYMOUS", "APEX_%", "APPQOSSYS", "AUDSYS", "CTXSYS", "DBSFWUSER", "DBSNMP", "DGPDB_INT",
+	"DIP", "DMSYS", "DVF", "DVSYS", "EXFSYS", "FLOWS_%", "GGSYS", "GSMADMIN_INTERNAL",
+	"GSMCATUSER", "GSMROOTUSER", "GSMUSER", "LBACSYS", "MDDATA", "MDSYS", "MGMT_VIEW",
+	"OJVMSYS", "OLAPSYS", "ORACLE_OCM", "ORDDATA", "ORDPLUGINS", "ORDSYS", "OUTLN",
+	"PERFSTAT", "RDSADMIN", "REMOTE_SCHEDULER_AGENT", "SI_INFORMTN_SCHEMA", "SQLTXPLAIN", "SYS",
+	"SYS$UMF", "SYSBACKUP", "SYSDG", "SYSKM", "SYSMAN", "SYSRAC", "SYSTEM", "TSMSYS",
+	"WMSYS", "XDB", "XS$NULL",
+}
+
+// ExcludedSchemas returns the default list of system schemas that are
+// excluded from extraction
+func ExcludedSchemas() []string {
+	return append([]string(nil), excludedSchemas...)
+}
+
+// AddExcludedSchemas returns the list of excluded system schemas with
+// the schemas appended, for use as the SystemSchemas of the Dictionary
+func AddExcludedSchemas(l, schemas []string) []string {
+	l = append([]string(nil), l...)
+	for _, v := range schemas {
+		v = trimString(v)
+		if v != "" {
+			l = append(l, strings.ToUpper(v))
+		}
+	}
+	return l
+}
+
+// systemSchemas returns the system schemas that are excluded, either
+// those of the Dictionary or the default list
+func (d Dictionary) systemSchemas() []string {
+	if d.SystemSchemas != nil {
+		return d.SystemSchemas
+	}
+	return excludedSchemas
+}
+
+// ExcludedSchemaClause returns a SQL predicate that excludes the system
+// schemas, as determined by the Dictionary carried by the context, for
+// the specified column
+func ExcludedSchemaClause(ctx context.Context, column string) string {
+
+	var names []string
+	var patterns []string
+
+	for _, v := range DictionaryFrom(ctx).systemSchemas() {
+		if strings.HasSuffix(v, "%") {
+			patterns = append(patterns, fmt.Sprintf("%s NOT LIKE %s", column, quoteLiteral(v)))
+		} else {
+			names = append(names, quoteLiteral(v))
+		}
+	}
+
+	var l []string
+	if len(names) > 0 {
+		l = append(l, fmt.Sprintf("%s NOT IN ( %s )", column, strings.Join(names, ", ")))
+	}
+	l = append(l, patterns...)
+
+	if len(l) == 0 {
+		return "1 = 1"
+	}
+	return "( " + strings.Join(l, " AND ") + " )"
+}
+
+// quoteLiteral returns a string as a quoted SQL literal
+func quoteLiteral(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
@@ -0,0 +1,73 @@
+package oradex
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// SizeReport contains the storage allocated to the segments of a schema
+type SizeReport struct {
+	Schema      string           `json:"schema"`
+	TotalBytes  int64            `json:"storage_bytes"`
+	BytesByType map[string]int64 `json:"storage_bytes_by_type"`
+}
+
+// SchemaSizeReport returns the storage allocated to the schema, in
+// total and by segment type.
+func SchemaSizeReport(ctx context.Context, db Querier, schema string) (SizeReport, error) {
+
+	r := SizeReport{Schema: schema, BytesByType: make(map[string]int64)}
+
+	query := `
+SELECT segment_type,
+        sum ( bytes ) AS bytes
+    FROM dba_segments
+    WHERE owner = :1
+    GROUP BY segment_type
+`
+
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, query), schema)
+	if err != nil {
+		return r, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	for rows.Next() {
+		var segType string
+		var bytes int64
+		err = rows.Scan(&segType, &bytes)
+		if err != nil {
+			return r, err
+		}
+		r.BytesByType[segType] = bytes
+		r.TotalBytes += bytes
+	}
+
+	return r, err
+}
+
+// WriteTable writes the size report as a formatted table
+func (r SizeReport) WriteTable(w io.Writer) error {
+
+	var types []string
+	for k := range r.BytesByType {
+		types = append(types, k)
+	}
+	sort.Strings(types)
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "%s\tSegment Type\tBytes\t\n", r.Schema)
+	for _, t := range types {
+		fmt.Fprintf(tw, "\t%s\t%d\t\n", t, r.BytesByType[t])
+	}
+	fmt.Fprintf(tw, "\tTotal\t%d\t\n", r.TotalBytes)
+
+	return tw.Flush()
+}
@@ -0,0 +1,280 @@
+package oradex
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/godror/godror"
+)
+
+// ObjDDLTo streams the DDL for the specified object, as returned by
+// DBMS_METADATA, to the writer without first reading the entire CLOB
+// into memory. The DDL is cleaned up as it is written (see ddlWriter),
+// with the exception of the trailing semicolon that ObjDDL ensures for
+// views and materialized views.
+func ObjDDLTo(ctx context.Context, w io.Writer, db Querier, schema, name, objType string) (int64, error) {
+	dw := newDDLWriter(w, objType)
+	n, err := queryLobTo(ctx, dw, db, "SELECT dbms_metadata.get_ddl ( :1, :2, :3 ) FROM DUAL", metadataType(objType), name, schema)
+	if err != nil {
+		return n, err
+	}
+	return n, dw.Flush()
+}
+
+// ddlWriter cleans up the DDL written to it before passing it on to the
+// underlying writer. Leading and trailing white-space is removed and,
+// for other than views and materialized views, the white-space that
+// precedes a "/" on a line of its own, which ends a PL/SQL block, is
+// replaced with a single new line. White-space is held back until it is
+// known whether or not it needs removing so that the DDL is cleaned up
+// in a single pass, however it is split across writes, with the same
+// result as trimming the DDL as a whole and then replacing each match of
+// "[\n\r\t ]+/\n" with "\n/\n".
+type ddlWriter struct {
+	w       io.Writer
+	plsql   bool
+	started bool
+	// pending white-space
+	pend []byte
+	// whether the pending white-space is followed by a "/"
+	slash bool
+	// the white-space preceding a block end, which is only replaced if
+	// the block end turns out not to be at the end of the DDL
+	held []byte
+}
+
+func newDDLWriter(w io.Writer, objType string) *ddlWriter {
+	return &ddlWriter{
+		w:     w,
+		plsql: objType != typeView && objType != typeMaterializedView,
+	}
+}
+
+// Write implements io.Writer. The returned count is that of the bytes
+// consumed, not of those written to the underlying writer.
+func (d *ddlWriter) Write(p []byte) (int, error) {
+
+	var out []byte
+
+	for _, c := range p {
+		if !d.started {
+			if isSpace(c) {
+				continue
+			}
+			d.started = true
+		}
+
+		if d.slash {
+			d.slash = false
+			if c == '\n' {
+				// A block end, its new line is not part of any
+				// following white-space
+				d.held = append(d.held, d.pend...)
+				d.pend = d.pend[:0]
+				continue
+			}
+			out = append(out, d.pend...)
+			out = append(out, '/')
+			d.pend = d.pend[:0]
+		}
+
+		if len(d.held) > 0 && !isSpace(c) {
+			out = append(out, "\n/\n"...)
+			d.held = d.held[:0]
+		}
+
+		switch {
+		case isSpace(c):
+			d.pend = append(d.pend, c)
+		case c == '/' && d.plsql && len(d.pend) > 0:
+			d.slash = true
+		default:
+			out = append(out, d.pend...)
+			out = append(out, c)
+			d.pend = d.pend[:0]
+		}
+	}
+
+	if len(out) > 0 {
+		if _, err := d.w.Write(out); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush writes any held or pending "/", as is, dropping any trailing
+// white-space.
+func (d *ddlWriter) Flush() error {
+
+	var out []byte
+	switch {
+	case d.slash:
+		out = append(d.pend, '/')
+	case len(d.held) > 0:
+		out = append(d.held, '/')
+	}
+	d.slash = false
+	d.held = nil
+	d.pend = nil
+
+	if len(out) == 0 {
+		return nil
+	}
+	_, err := d.w.Write(out)
+	return err
+}
+
+// queryLobTo streams the CLOB returned by the (single row, single
+// column) query to the writer in chunks, so that multi-megabyte DDL is
+// neither truncated nor read in a single allocation
+func queryLobTo(ctx context.Context, w io.Writer, db Querier, query string, args ...interface{}) (int64, error) {
+
+	rows, err := db.QueryContext(ctx, query, append(args, godror.LobAsReader())...)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	var n int64
+	if rows.Next() {
+		var v interface{}
+		err = rows.Scan(&v)
+		if err != nil {
+			return n, err
+		}
+
+		switch lob := v.(type) {
+		case *godror.Lob:
+			n, err = io.Copy(w, lob)
+		case string:
+			var i int
+			i, err = io.WriteString(w, lob)
+			n = int64(i)
+		case nil:
+		default:
+			err = fmt.Errorf("unexpected LOB type %T", v)
+		}
+	}
+
+	return n, err
+}
+
+// queryLob returns the CLOB returned by the (single row, single column)
+// query, read in chunks (see queryLobTo)
+func queryLob(ctx context.Context, db Querier, query string, args ...interface{}) (string, error) {
+	var b strings.Builder
+	_, err := queryLobTo(ctx, &b, db, query, args...)
+	return b.String(), err
+}
+
+// streamable returns whether or not the export options allow for the
+// object DDL to be streamed as is, without first being read in full in
+// order to be rewritten or retried.
+func (e *Extractor) streamable() bool {
+	o := e.opts
+	switch {
+	case !o.TargetVersion.IsZero(), !e.emitSchema():
+		return false
+	case len(o.RemapSchemas) > 0, len(o.RemapTablespaces) > 0, len(o.RewriteRules) > 0:
+		return false
+	case o.Normalize, o.Format, o.ResetSequences, o.Synonyms:
+		return false
+	case o.Retries > 0, o.ObjectTimeout > 0:
+		return false
+	}
+	return true
+}
+
+// ExportDDLTo writes the DDL for the specified object and all
+// *supporting* objects and grants to the writer. For objects other than
+// tables, views, roles, queues, and triggers the object DDL is streamed
+// directly from the database (see ObjDDLTo) unless the export options
+// require it to be read in full (see streamable).
+func (e *Extractor) ExportDDLTo(ctx context.Context, w io.Writer, schema, name, objType string) (ObjectResult, error) {
+
+	switch objType {
+	case typeTable, typeView, typeMaterializedView, typeQueue, typeRole, typeTrigger:
+		objDDL, res, err := e.ExportDDL(ctx, schema, name, objType)
+		if err != nil {
+			return res, err
+		}
+		_, err = io.WriteString(w, objDDL)
+		return res, err
+	}
+	if !e.streamable() {
+		objDDL, res, err := e.ExportDDL(ctx, schema, name, objType)
+		if err != nil {
+			return res, err
+		}
+		_, err = io.WriteString(w, objDDL)
+		return res, err
+	}
+
+	db := e.db
+
+	start := time.Now()
+	res := ObjectResult{Schema: schema, Name: name, ObjType: objType}
+	o := Object{Owner: schema, Name: name, Type: objType}
+
+	// The supporting DDL is rendered as by Object.String, and so as by
+	// ExportDDL, around the streamed object DDL
+	if e.opts.Drop {
+		o.DropDDL = DropDDL(schema, name, objType, e.opts.DropCascade)
+	}
+
+	var err error
+	if e.opts.NeededGrants {
+		o.withNeededGrants = true
+		o.NeededGrants, err = ObjNeededPrivList(ctx, db, schema, name, objType)
+		res.note(err)
+	}
+
+	head, _ := o.sections()
+	if len(head) > 0 {
+		_, err = io.WriteString(w, strings.Join(head, dblSpace())+dblSpace())
+		if err != nil {
+			res.Elapsed = time.Since(start)
+			return res, err
+		}
+	}
+
+	_, err = ObjDDLTo(ctx, w, db, schema, name, objType)
+	if err != nil {
+		res.Elapsed = time.Since(start)
+		return res, objectError(schema, name, objType, err)
+	}
+
+	if e.opts.CurrentValue && objType == typeSequence {
+		restart, err := ObjSequenceRestart(ctx, db, schema, name)
+		res.note(err)
+		if err == nil {
+			o.AlterDDL = append(o.AlterDDL, restart)
+		}
+	}
+
+	if e.opts.ObjectGrants {
+		o.withGrants = true
+		o.Grants, err = ObjGrantedPrivList(ctx, db, schema, name, objType)
+		res.note(err)
+	}
+
+	_, tail := o.sections()
+	for _, s := range tail {
+		_, err = io.WriteString(w, dblSpace()+s)
+		if err != nil {
+			res.Elapsed = time.Since(start)
+			return res, err
+		}
+	}
+
+	res.Elapsed = time.Since(start)
+	return res, nil
+}
@@ -0,0 +1,187 @@
+package oradex
+
+import (
+	"context"
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// blockEndRe is the clean up of the "/" that ends a PL/SQL block as it
+// was done, on the DDL as a whole, before the DDL was streamed
+var blockEndRe = regexp.MustCompile("[\n\r\t ]+/\n")
+
+// cleanDDL is the reference for ddlWriter
+func cleanDDL(s, objType string) string {
+	s = trimString(s)
+	if objType != typeView && objType != typeMaterializedView {
+		s = blockEndRe.ReplaceAllString(s, "\n/\n")
+	}
+	return s
+}
+
+// writeChunks writes the DDL to a ddlWriter in chunks of the specified
+// size and returns the result
+func writeChunks(t *testing.T, s, objType string, size int) string {
+
+	t.Helper()
+
+	var b strings.Builder
+	w := newDDLWriter(&b, objType)
+	for i := 0; i < len(s); i += size {
+		j := i + size
+		if j > len(s) {
+			j = len(s)
+		}
+		if _, err := w.Write([]byte(s[i:j])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestDDLWriter(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		in      string
+		objType string
+	}{
+		{"empty", "", typeTable},
+		{"white-space only", " \n\t\n ", typeTable},
+		{"trimmed", "\n  CREATE TABLE t ( c NUMBER )  \n", typeTable},
+		{"block end", "BEGIN\n    NULL ;\nEND ;  \n\n  /\nGRANT x", typePackage},
+		{"block end at the end", "END ;\n\n/\n", typePackage},
+		{"block end then white-space", "END ;  \n/\n  \n", typePackage},
+		{"block end without new line", "END ;\n/", typePackage},
+		{"consecutive block ends", "END ;\n /\n /\n/\nx", typePackage},
+		{"block end after a block end", "b\n/\n/\n\t\t/\na/ ", typePackage},
+		{"leading block end", "  /\nx", typePackage},
+		{"slash without white-space", "a/\nb", typePackage},
+		{"division", "x := a / b ;\n", "FUNCTION"},
+		{"view slash", "SELECT a\n  /\nb FROM t", typeView},
+		{"crlf", "END ;\r\n\r\n/\r\nx", typePackage},
+		{"recorded package", readFixture(t, "ddl/app.order_api.package.sql"), typePackage},
+		{"recorded view", readFixture(t, "ddl/app.open_orders.view.sql"), typeView},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := cleanDDL(tt.in, tt.objType)
+
+			// The result must not depend on how the DDL is chunked
+			for size := 1; size <= len(tt.in)+1; size++ {
+				if got := writeChunks(t, tt.in, tt.objType, size); got != want {
+					t.Fatalf("chunks of %d: got %q, want %q", size, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDDLWriterRandom(t *testing.T) {
+
+	const alphabet = "\n\n\r\t  //ab;"
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		b := make([]byte, r.Intn(24))
+		for j := range b {
+			b[j] = alphabet[r.Intn(len(alphabet))]
+		}
+		s := string(b)
+
+		for _, objType := range []string{typePackage, typeView} {
+			want := cleanDDL(s, objType)
+			size := 1 + r.Intn(len(s)+1)
+			if got := writeChunks(t, s, objType, size); got != want {
+				t.Fatalf("%s %q in chunks of %d: got %q, want %q", objType, s, size, got, want)
+			}
+		}
+	}
+}
+
+func TestObjDDLTo(t *testing.T) {
+
+	db := openFixtures(t, "app.json")
+
+	tests := []struct {
+		name    string
+		objType string
+	}{
+		{"ORDER_SEQ", typeSequence},
+		{"OPEN_ORDERS", typeView},
+		{"ORDER_API", typePackage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := ObjDDL(context.Background(), db, "APP", tt.name, tt.objType)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Streaming only lacks the semicolon that ends views
+			var b strings.Builder
+			_, err = ObjDDLTo(context.Background(), &b, db, "APP", tt.name, tt.objType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); !strings.HasPrefix(want, got) || strings.Trim(want[len(got):], "\n;") != "" {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestExtractorExportDDLTo(t *testing.T) {
+
+	db := openFixtures(t, "app.json")
+
+	tests := []struct {
+		name         string
+		objType      string
+		drop         bool
+		neededGrants bool
+		objectGrants bool
+		currentValue bool
+	}{
+		{"ORDER_SEQ", typeSequence, false, false, false, false},
+		// ORDER_SEQ has neither needed nor granted privileges
+		{"ORDER_SEQ", typeSequence, true, true, true, true},
+		{"ORDER_API", typePackage, false, true, false, false},
+		{"ORDER_API", typePackage, true, true, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := NewExportOptions()
+			opts.Drop = tt.drop
+			opts.NeededGrants = tt.neededGrants
+			opts.ObjectGrants = tt.objectGrants
+			opts.CurrentValue = tt.currentValue
+			e := NewExtractor(db, opts)
+			if !e.streamable() {
+				t.Fatal("the export options do not allow streaming")
+			}
+
+			want, _, err := e.ExportDDL(context.Background(), "APP", tt.name, tt.objType)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var b strings.Builder
+			_, err = e.ExportDDLTo(context.Background(), &b, "APP", tt.name, tt.objType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
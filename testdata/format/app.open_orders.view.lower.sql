@@ -0,0 +1,11 @@
+create or replace force editionable view "APP"."OPEN_ORDERS" ("ORDER_ID", "CUSTOMER_ID", "NOTE") as
+  select o.order_id,
+        o.customer_id,
+        o.note
+    from app.orders o
+    where o.status = 'NEW'
+
+
+
+    -- excludes the  cancelled   orders
+;
\ No newline at end of file
@@ -0,0 +1 @@
+create sequence  "APP"."ORDER_SEQ"  minvalue 100 maxvalue 9999999999999999999999999999 increment by 1 start with 4821 cache 20 noorder  nocycle  nokeep  noscale  global
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE MATERIALIZED VIEW "APP"."ORDER_TOTALS" ("CUSTOMER_ID", "TOTAL")
+  SEGMENT CREATION IMMEDIATE
+  ORGANIZATION HEAP PCTFREE 10 PCTUSED 40 INITRANS 1 MAXTRANS 255
+  TABLESPACE "USERS"
+  BUILD IMMEDIATE
+  USING INDEX
+  REFRESH COMPLETE ON DEMAND START WITH TO_DATE('2026-10-18 02:00:00', 'YYYY-MM-DD HH24:MI:SS') NEXT SYSDATE + 1
+  USING DEFAULT LOCAL ROLLBACK SEGMENT
+  USING ENFORCED CONSTRAINTS DISABLE ON QUERY COMPUTATION DISABLE QUERY REWRITE
+  AS SELECT customer_id, count (*) AS total FROM app.orders GROUP BY customer_id
\ No newline at end of file
@@ -0,0 +1,15 @@
+create table "APP"."ORDERS" (
+    "ORDER_ID" number(12,0) not null enable,
+    "CUSTOMER_ID" number(12,0) not null enable,
+    "STATUS" varchar2(20 byte) default 'NEW' not null enable,
+    "NOTE" varchar2(200 byte),
+    constraint "ORDERS_PK" primary key ("ORDER_ID") using index pctfree 10 initrans 2 maxtrans 255 compute statistics storage(initial 65536 next 1048576 minextents 1 maxextents 2147483645 pctincrease 0 freelists 1 freelist groups 1 buffer_pool default flash_cache default cell_flash_cache default) tablespace "USERS" enable,
+    constraint "ORDERS_CUSTOMER_FK" foreign key ("CUSTOMER_ID") references "APP"."CUSTOMERS" ("CUSTOMER_ID") enable
+)
+    segment creation immediate
+    pctfree 10 pctused 40 initrans 1 maxtrans 255
+    nocompress logging
+    storage(initial 65536 next 1048576 minextents 1 maxextents 2147483645
+  pctincrease 0 freelists 1 freelist groups 1
+  buffer_pool default flash_cache default cell_flash_cache default)
+    tablespace "USERS"
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE OR REPLACE FORCE EDITIONABLE VIEW "APP"."OPEN_ORDERS" ("ORDER_ID", "CUSTOMER_ID", "NOTE") AS 
+  SELECT o.order_id,
+        o.customer_id,
+        o.note
+    FROM app.orders o
+    WHERE o.status = 'NEW'  
+
+
+
+    -- excludes the  cancelled   orders
+;
\ No newline at end of file
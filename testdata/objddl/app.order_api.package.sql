@@ -0,0 +1,12 @@
+CREATE OR REPLACE EDITIONABLE PACKAGE "APP"."ORDER_API" 
+AS
+    -- Creates an order,   returning the id
+    FUNCTION create_order (
+        a_customer_id IN NUMBER,
+        a_note IN VARCHAR2 DEFAULT NULL )
+        RETURN NUMBER ;
+
+END order_api ;  
+
+
+/
\ No newline at end of file
@@ -0,0 +1 @@
+CREATE SEQUENCE  "APP"."ORDER_SEQ"  MINVALUE 100 MAXVALUE 9999999999999999999999999999 INCREMENT BY 1 START WITH 4821 CACHE 20 NOORDER  NOCYCLE  NOKEEP  NOSCALE  GLOBAL
\ No newline at end of file
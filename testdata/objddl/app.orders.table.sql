@@ -0,0 +1,20 @@
+CREATE TABLE "APP"."ORDERS" 
+   (	"ORDER_ID" NUMBER(12,0) NOT NULL ENABLE, 
+	"CUSTOMER_ID" NUMBER(12,0) NOT NULL ENABLE, 
+	"STATUS" VARCHAR2(20 BYTE) DEFAULT 'NEW' NOT NULL ENABLE, 
+	"NOTE" VARCHAR2(200 BYTE), 
+	 CONSTRAINT "ORDERS_PK" PRIMARY KEY ("ORDER_ID")
+  USING INDEX PCTFREE 10 INITRANS 2 MAXTRANS 255 COMPUTE STATISTICS 
+  STORAGE(INITIAL 65536 NEXT 1048576 MINEXTENTS 1 MAXEXTENTS 2147483645
+  PCTINCREASE 0 FREELISTS 1 FREELIST GROUPS 1
+  BUFFER_POOL DEFAULT FLASH_CACHE DEFAULT CELL_FLASH_CACHE DEFAULT)
+  TABLESPACE "USERS"  ENABLE, 
+	 CONSTRAINT "ORDERS_CUSTOMER_FK" FOREIGN KEY ("CUSTOMER_ID")
+	  REFERENCES "APP"."CUSTOMERS" ("CUSTOMER_ID") ENABLE
+   ) SEGMENT CREATION IMMEDIATE 
+  PCTFREE 10 PCTUSED 40 INITRANS 1 MAXTRANS 255 
+ NOCOMPRESS LOGGING
+  STORAGE(INITIAL 65536 NEXT 1048576 MINEXTENTS 1 MAXEXTENTS 2147483645
+  PCTINCREASE 0 FREELISTS 1 FREELIST GROUPS 1
+  BUFFER_POOL DEFAULT FLASH_CACHE DEFAULT CELL_FLASH_CACHE DEFAULT)
+  TABLESPACE "USERS"
\ No newline at end of file
@@ -0,0 +1,104 @@
+package oradex
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ConstraintToggleScripts returns the scripts for disabling, and then
+// re-enabling, the currently enabled foreign key and check constraints
+// of the tables in the specified schema, as for bulk loading data. The
+// foreign keys are disabled first and enabled last. Primary key and
+// unique constraints are left as is since disabling them drops their
+// indexes.
+func ConstraintToggleScripts(ctx context.Context, db Querier, schema string) (string, string, error) {
+
+	query := `
+SELECT owner,
+        table_name,
+        constraint_name
+    FROM dba_constraints
+    WHERE owner = :1
+        AND constraint_type IN ( 'C', 'R' )
+        AND status = 'ENABLED'
+        AND table_name NOT LIKE 'BIN$%'
+    ORDER BY CASE constraint_type
+            WHEN 'R' THEN 1
+            ELSE 2
+            END,
+        table_name,
+        constraint_name
+`
+
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, query), schema)
+	if err != nil {
+		return "", "", err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	var disable, enable []string
+	for rows.Next() {
+		var owner, table, name string
+		err = rows.Scan(&owner, &table, &name)
+		if err != nil {
+			return "", "", err
+		}
+		disable = append(disable, fmt.Sprintf("ALTER TABLE \"%s\".\"%s\" DISABLE CONSTRAINT \"%s\" ;", owner, table, name))
+		enable = append(enable, fmt.Sprintf("ALTER TABLE \"%s\".\"%s\" ENABLE CONSTRAINT \"%s\" ;", owner, table, name))
+	}
+
+	return strings.Join(disable, "\n"), strings.Join(reverseStrings(enable), "\n"), err
+}
+
+// TriggerToggleScripts returns the scripts for disabling, and then
+// re-enabling, the currently enabled triggers owned by the specified
+// schema
+func TriggerToggleScripts(ctx context.Context, db Querier, schema string) (string, string, error) {
+
+	query := `
+SELECT owner,
+        trigger_name
+    FROM dba_triggers
+    WHERE owner = :1
+        AND status = 'ENABLED'
+        AND trigger_name NOT LIKE 'BIN$%'
+    ORDER BY trigger_name
+`
+
+	rows, err := db.QueryContext(ctx, DictQuery(ctx, query), schema)
+	if err != nil {
+		return "", "", err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	var disable, enable []string
+	for rows.Next() {
+		var owner, name string
+		err = rows.Scan(&owner, &name)
+		if err != nil {
+			return "", "", err
+		}
+		disable = append(disable, fmt.Sprintf("ALTER TRIGGER \"%s\".\"%s\" DISABLE ;", owner, name))
+		enable = append(enable, fmt.Sprintf("ALTER TRIGGER \"%s\".\"%s\" ENABLE ;", owner, name))
+	}
+
+	return strings.Join(disable, "\n"), strings.Join(enable, "\n"), err
+}
+
+// reverseStrings returns the list in reverse order
+func reverseStrings(l []string) []string {
+	r := make([]string, 0, len(l))
+	for i := len(l) - 1; i >= 0; i-- {
+		r = append(r, l[i])
+	}
+	return r
+}
@@ -0,0 +1,130 @@
+package oradex
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// TransformParams are the DBMS_METADATA session transform parameters
+// that determine the content of the generated DDL. See the Oracle
+// documentation for DBMS_METADATA.SET_TRANSFORM_PARAM for the details
+// of each parameter.
+type TransformParams struct {
+	Pretty             bool
+	SQLTerminator      bool
+	Constraints        bool
+	RefConstraints     bool
+	ConstraintsAsAlter bool
+	Force              bool
+	EmitSchema         bool
+	OID                bool
+	SizeByteKeyword    bool
+	SegmentAttributes  bool
+	Storage            bool
+	Tablespace         bool
+	SegmentCreation    bool
+	Partitioning       bool
+
+	// CollationClause, when set, is one of NEVER, ALWAYS, or NON_DEFAULT
+	// and requires Oracle 12.2 or later
+	CollationClause string
+}
+
+// DefaultTransformParams returns the transform parameters used by
+// default, which omit the storage related clauses
+func DefaultTransformParams() TransformParams {
+	return TransformParams{
+		Pretty:          true,
+		SQLTerminator:   true,
+		Constraints:     true,
+		RefConstraints:  true,
+		EmitSchema:      true,
+		OID:             true,
+		Tablespace:      true,
+		SegmentCreation: true,
+		Partitioning:    true,
+	}
+}
+
+// sql returns the PL/SQL block for setting the transform parameters.
+// The session sort order is also set to binary so that the ordering of
+// the extracted grants, comments, indexes, and triggers does not depend
+// on the language settings of the client. When guardCollation is set
+// the failure to set the collation clause, on databases older than
+// 12.2, is ignored.
+func (p TransformParams) sql(guardCollation bool) string {
+
+	l := []string{
+		"    EXECUTE IMMEDIATE 'ALTER SESSION SET NLS_SORT = BINARY' ;",
+		"    EXECUTE IMMEDIATE 'ALTER SESSION SET NLS_COMP = BINARY' ;",
+	}
+
+	set := func(name, value string) {
+		l = append(l, fmt.Sprintf(`    DBMS_METADATA.SET_TRANSFORM_PARAM
+        ( DBMS_METADATA.SESSION_TRANSFORM, '%s', %s );`, name, value))
+	}
+
+	set("DEFAULT", "TRUE")
+	set("CONSTRAINTS", boolToText(p.Constraints))
+	set("REF_CONSTRAINTS", boolToText(p.RefConstraints))
+	set("CONSTRAINTS_AS_ALTER", boolToText(p.ConstraintsAsAlter))
+	set("FORCE", boolToText(p.Force))
+	set("OID", boolToText(p.OID))
+	set("SIZE_BYTE_KEYWORD", boolToText(p.SizeByteKeyword))
+	set("SEGMENT_ATTRIBUTES", boolToText(p.SegmentAttributes))
+	set("STORAGE", boolToText(p.Storage))
+	set("TABLESPACE", boolToText(p.Tablespace))
+	set("SEGMENT_CREATION", boolToText(p.SegmentCreation))
+	set("PARTITIONING", boolToText(p.Partitioning))
+	set("SQLTERMINATOR", boolToText(p.SQLTerminator))
+	set("PRETTY", boolToText(p.Pretty))
+	set("EMIT_SCHEMA", boolToText(p.EmitSchema))
+	if p.CollationClause != "" && guardCollation {
+		l = append(l, "    BEGIN")
+		set("COLLATION_CLAUSE", quoteLiteral(strings.ToUpper(p.CollationClause)))
+		l = append(l, "    EXCEPTION", "        WHEN OTHERS THEN NULL ;", "    END ;")
+	} else if p.CollationClause != "" {
+		set("COLLATION_CLAUSE", quoteLiteral(strings.ToUpper(p.CollationClause)))
+	}
+
+	return "\nBEGIN\n" + strings.Join(l, "\n") + "\nEND; "
+}
+
+// InitTransforms sets the DBMS_METADATA session transform parameters.
+// The parameters only apply to the session that they are set in so,
+// for a *sql.DB, only to one of the sessions in the pool. See
+// SessionInitSQL for setting them for every session in the pool.
+func InitTransforms(ctx context.Context, db Querier, p TransformParams) error {
+	_, err := db.ExecContext(ctx, p.sql(false))
+	return err
+}
+
+// SessionInitSQL returns the PL/SQL block that sets the DBMS_METADATA
+// session transform parameters for the options. Running it for each new
+// session of a connection pool (i.e. as one of the godror OnInitStmts)
+// ensures that the parameters are honored regardless of which of the
+// pooled sessions the DDL is extracted with. As the database version is
+// not known until connected, a collation clause is ignored on databases
+// that do not support it.
+func SessionInitSQL(opts ExportOptions) string {
+	return opts.transformParams().sql(true)
+}
+
+// transformParams returns the transform parameters for the options:
+// either the Transforms or those derived from Storage, Force, Alter, and
+// EmitSchema
+func (o ExportOptions) transformParams() TransformParams {
+
+	if o.Transforms != nil {
+		return *o.Transforms
+	}
+
+	p := DefaultTransformParams()
+	p.Storage = o.Storage
+	p.SegmentAttributes = o.Storage
+	p.Force = o.Force
+	p.ConstraintsAsAlter = o.Alter
+	p.EmitSchema = o.EmitSchema
+	return p
+}
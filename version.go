@@ -0,0 +1,336 @@
+package oradex
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// OracleVersion is the major/minor release of an Oracle database
+type OracleVersion struct {
+	Major int
+	Minor int
+}
+
+// Known Oracle versions that DDL may be downgraded to
+var (
+	Oracle11gR2 = OracleVersion{11, 2}
+	Oracle12cR1 = OracleVersion{12, 1}
+	Oracle12cR2 = OracleVersion{12, 2}
+	Oracle19c   = OracleVersion{19, 0}
+	Oracle21c   = OracleVersion{21, 0}
+	Oracle23c   = OracleVersion{23, 0}
+)
+
+// String returns the version as major.minor
+func (v OracleVersion) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// IsZero returns true if the version has not been set
+func (v OracleVersion) IsZero() bool {
+	return v.Major == 0 && v.Minor == 0
+}
+
+// Less returns true if the version is older than the other version
+func (v OracleVersion) Less(o OracleVersion) bool {
+	if v.Major != o.Major {
+		return v.Major < o.Major
+	}
+	return v.Minor < o.Minor
+}
+
+// ParseOracleVersion parses a version string such as "11.2", "19c", or
+// "19.3.0.0.0"
+func ParseOracleVersion(s string) (OracleVersion, error) {
+
+	var v OracleVersion
+
+	t := strings.TrimRight(strings.ToLower(trimString(s)), "cg")
+	p := strings.Split(t, ".")
+
+	major, err := strconv.Atoi(p[0])
+	if err != nil {
+		return v, fmt.Errorf("invalid Oracle version %q", s)
+	}
+	v.Major = major
+
+	if len(p) > 1 {
+		minor, err := strconv.Atoi(p[1])
+		if err != nil {
+			return v, fmt.Errorf("invalid Oracle version %q", s)
+		}
+		v.Minor = minor
+	}
+
+	return v, nil
+}
+
+// DbVersion returns the version of the connected database
+func DbVersion(ctx context.Context, db Querier) (OracleVersion, error) {
+
+	var v OracleVersion
+	var version string
+
+	// v$instance is not generally available to non-DBA users
+	pcvQuery := "SELECT version FROM product_component_version WHERE product LIKE 'Oracle Database%'"
+	query := "SELECT version FROM v$instance"
+	if d := DictionaryFrom(ctx); d.AllViews || d.MissingViews["v$instance"] {
+		query = pcvQuery
+	}
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil && query != pcvQuery {
+		rows, err = db.QueryContext(ctx, pcvQuery)
+	}
+	if err != nil {
+		return v, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	if rows.Next() {
+		err = rows.Scan(&version)
+		if err != nil {
+			return v, err
+		}
+	}
+
+	return ParseOracleVersion(version)
+}
+
+// Features of the data dictionary, and of DBMS_METADATA, that depend on
+// the version of the database
+const (
+	FeatureOracleMaintained = "oracle_maintained"
+	FeatureMultitenant      = "multitenant"
+	FeatureCollationClause  = "collation_clause"
+)
+
+// features maps each feature to the version that it was introduced in
+var features = map[string]OracleVersion{
+	FeatureOracleMaintained: Oracle12cR1,
+	FeatureMultitenant:      Oracle12cR1,
+	FeatureCollationClause:  Oracle12cR2,
+}
+
+// HasFeature returns true if the connected database, as described by
+// the Dictionary carried by the context, supports the feature. Features
+// are assumed to be supported if the version of the database is not
+// known.
+func HasFeature(ctx context.Context, feature string) bool {
+	return DictionaryFrom(ctx).Version.hasFeature(feature)
+}
+
+// hasFeature returns true if the version supports the feature, or if the
+// version is not known
+func (v OracleVersion) hasFeature(feature string) bool {
+	if v.IsZero() {
+		return true
+	}
+	return !v.Less(features[feature])
+}
+
+// OracleMaintainedClause returns a SQL predicate that excludes the Oracle
+// maintained roles, or objects, for the alias of a dba_roles or
+// dba_objects view. Databases older than 12.1 do not record which are
+// Oracle maintained so, for those, the names of the commonly supplied
+// roles, job classes, and windows are excluded instead.
+func OracleMaintainedClause(ctx context.Context, alias, nameColumn string) string {
+	if HasFeature(ctx, FeatureOracleMaintained) {
+		return fmt.Sprintf("%s.oracle_maintained = 'N'", alias)
+	}
+
+	var l []string
+	for _, v := range oracleSuppliedNames {
+		l = append(l, quoteLiteral(v))
+	}
+	return fmt.Sprintf("%s.%s NOT IN ( %s )", alias, nameColumn, strings.Join(l, ", "))
+}
+
+// oracleSuppliedNames are the commonly supplied roles, scheduler job
+// classes, and windows for databases that predate oracle_maintained
+var oracleSuppliedNames = []string{
+	"ADM_PARALLEL_EXECUTE_TASK", "APEX_ADMINISTRATOR_ROLE", "AQ_ADMINISTRATOR_ROLE", "AQ_USER_ROLE",
+	"AUTHENTICATEDUSER", "AUTO_TASKS_JOB_CLASS", "CONNECT", "CSW_USR_ROLE", "CTXAPP", "CWM_USER",
+	"DATAPUMP_EXP_FULL_DATABASE", "DATAPUMP_IMP_FULL_DATABASE", "DBA", "DBFS_ROLE",
+	"DEFAULT_JOB_CLASS", "DELETE_CATALOG_ROLE", "DBMS_JOB", "EJBCLIENT", "EXECUTE_CATALOG_ROLE",
+	"EXP_FULL_DATABASE", "FRIDAY_WINDOW", "GATHER_SYSTEM_STATISTICS", "GLOBAL_AQ_USER_ROLE",
+	"HS_ADMIN_EXECUTE_ROLE", "HS_ADMIN_ROLE", "HS_ADMIN_SELECT_ROLE", "IMP_FULL_DATABASE",
+	"JAVADEBUGPRIV", "JAVAIDPRIV", "JAVASYSPRIV", "JAVAUSERPRIV", "JAVA_ADMIN", "JAVA_DEPLOY",
+	"JMXSERVER", "LOGSTDBY_ADMINISTRATOR", "MGMT_USER", "MONDAY_WINDOW", "OEM_ADVISOR",
+	"OEM_MONITOR", "OLAP_DBA", "OLAP_USER", "OLAP_XS_ADMIN", "ORDADMIN", "OWB$CLIENT",
+	"OWB_DESIGNCENTER_VIEW", "OWB_USER", "RECOVERY_CATALOG_OWNER", "RESOURCE",
+	"SATURDAY_WINDOW", "SCHEDULER_ADMIN", "SELECT_CATALOG_ROLE", "SPATIAL_CSW_ADMIN",
+	"SPATIAL_WFS_ADMIN", "SUNDAY_WINDOW", "THURSDAY_WINDOW", "TUESDAY_WINDOW", "WEDNESDAY_WINDOW",
+	"WEEKEND_WINDOW", "WEEKNIGHT_WINDOW", "WFS_USR_ROLE", "WM_ADMIN_ROLE", "XDBADMIN", "XDB_SET_INVOKER",
+	"XDB_WEBSERVICES", "XDB_WEBSERVICES_OVER_HTTP", "XDB_WEBSERVICES_WITH_PUBLIC",
+}
+
+// downgradeRule describes a DDL feature and the version that it was
+// introduced in. Rules without a replacement only generate a warning.
+// The scope limits the statements that the rule is applied to.
+type downgradeRule struct {
+	introduced  OracleVersion
+	feature     string
+	scope       ruleScope
+	re          *regexp.Regexp
+	replacement *string
+}
+
+// ruleScope is the kind of statement that a downgrade rule applies to
+type ruleScope int
+
+const (
+	// scopeAll rules apply to all statements, including source text
+	scopeAll ruleScope = iota
+	// scopeSQL rules apply to all but the PL/SQL source statements
+	scopeSQL
+	// scopeTable rules apply to the column clauses of CREATE TABLE and
+	// ALTER TABLE statements
+	scopeTable
+)
+
+func replaceWith(s string) *string {
+	return &s
+}
+
+// identityOptions are the sequence options of an identity column, which
+// DBMS_METADATA writes without the enclosing parentheses
+const identityOptions = `(?:[ \t]+(?:START[ \t]+WITH[ \t]+(?:LIMIT[ \t]+VALUE|-?\d+)|INCREMENT[ \t]+BY[ \t]+-?\d+|` +
+	`(?:MAXVALUE|MINVALUE|CACHE)[ \t]+-?\d+|NO(?:MAXVALUE|MINVALUE|CACHE|CYCLE|ORDER|KEEP|SCALE|SHARD)|` +
+	`CYCLE|ORDER|KEEP|(?:SCALE|SHARD)(?:[ \t]+(?:NO)?EXTEND)?|SESSION|GLOBAL)\b)*`
+
+var downgradeRules = []downgradeRule{
+	{Oracle12cR1, "identity column", scopeTable, regexp.MustCompile(`(?i)[ \t]+GENERATED[ \t]+(ALWAYS|BY[ \t]+DEFAULT([ \t]+ON[ \t]+NULL)?)[ \t]+AS[ \t]+IDENTITY([ \t]*\([^)]*\)|` + identityOptions + `)`), replaceWith("")},
+	{Oracle12cR1, "DEFAULT ON NULL", scopeTable, regexp.MustCompile(`(?i)\bDEFAULT[ \t]+ON[ \t]+NULL\b`), replaceWith("DEFAULT")},
+	{Oracle12cR1, "invisible column", scopeTable, regexp.MustCompile(`(?i)("[^"]+"[ \t]+[A-Z0-9_]+(\([^)]*\))?)[ \t]+INVISIBLE\b`), replaceWith("$1")},
+	{Oracle12cR1, "APPROX_COUNT_DISTINCT", scopeSQL, regexp.MustCompile(`(?i)\bAPPROX_COUNT_DISTINCT[ \t]*\(`), replaceWith("COUNT ( DISTINCT ")},
+	{Oracle12cR1, "row limiting clause", scopeAll, regexp.MustCompile(`(?i)\bFETCH[ \t]+(FIRST|NEXT)\b`), nil},
+	{Oracle12cR2, "identifier longer than 30 characters", scopeAll, regexp.MustCompile(`"[^"\n]{31,128}"`), nil},
+	{Oracle19c, "SQL macro", scopeAll, regexp.MustCompile(`(?i)\bSQL_MACRO\b`), nil},
+	{Oracle21c, "JSON data type", scopeTable, regexp.MustCompile(`(?i)("[^"]+"[ \t]+)JSON\b`), replaceWith("${1}CLOB")},
+	{Oracle23c, "IF [NOT] EXISTS clause", scopeSQL, regexp.MustCompile(`(?im)^([ \t]*(?:CREATE|DROP|ALTER)[ \t]+(?:[A-Z]+[ \t]+){0,3}?)IF[ \t]+(?:NOT[ \t]+)?EXISTS[ \t]+`), replaceWith("$1")},
+	{Oracle23c, "BOOLEAN data type", scopeTable, regexp.MustCompile(`(?i)("[^"]+"[ \t]+)BOOLEAN\b`), replaceWith("${1}NUMBER(1)")},
+}
+
+var (
+	// sourceStmtRe matches the start of a PL/SQL source statement, which
+	// is terminated by a line containing only a slash
+	sourceStmtRe = regexp.MustCompile(`(?i)^\s*(CREATE\s+(OR\s+REPLACE\s+)?((NON)?EDITIONABLE\s+)?(AND\s+(RESOLVE|COMPILE)\s+)?(NOFORCE\s+)?(PACKAGE|PROCEDURE|FUNCTION|TRIGGER|TYPE|LIBRARY|JAVA)\b|BEGIN\b|DECLARE\b)`)
+	// tableStmtRe matches the start of a CREATE TABLE or ALTER TABLE
+	// statement
+	tableStmtRe = regexp.MustCompile(`(?i)^\s*(CREATE\s+((GLOBAL|PRIVATE)\s+TEMPORARY\s+|SHARDED\s+|DUPLICATED\s+|IMMUTABLE\s+|BLOCKCHAIN\s+)*TABLE|ALTER\s+TABLE)\b`)
+	// stmtStartRe matches the start of a (non-source) statement
+	stmtStartRe = regexp.MustCompile(`(?i)^\s*(CREATE|ALTER|DROP|COMMENT|GRANT|REVOKE)\b`)
+	// stmtEndRe matches the line that terminates a source statement
+	stmtEndRe = regexp.MustCompile(`^\s*/\s*$`)
+)
+
+// ddlStatement is one of the statements of the DDL for an object
+type ddlStatement struct {
+	text  string
+	scope ruleScope
+}
+
+// splitStatements splits the DDL into statements so that the downgrade
+// rules can be limited to the statements that they apply to. Source
+// statements run to the terminating slash, the other statements run to
+// the start of the next statement.
+func splitStatements(ddl string) []ddlStatement {
+
+	var l []ddlStatement
+	inSource := false
+
+	for _, line := range strings.SplitAfter(ddl, "\n") {
+		switch {
+		case inSource:
+			l[len(l)-1].text += line
+			inSource = !stmtEndRe.MatchString(line)
+			continue
+		case sourceStmtRe.MatchString(line):
+			l = append(l, ddlStatement{text: line, scope: scopeAll})
+			inSource = !stmtEndRe.MatchString(line)
+			continue
+		case tableStmtRe.MatchString(line):
+			l = append(l, ddlStatement{text: line, scope: scopeTable})
+			continue
+		case stmtStartRe.MatchString(line), len(l) == 0:
+			l = append(l, ddlStatement{text: line, scope: scopeSQL})
+			continue
+		}
+		l[len(l)-1].text += line
+	}
+
+	return l
+}
+
+// applies returns true if a rule of the scope applies to the statement
+func (s ddlStatement) applies(scope ruleScope) bool {
+	switch scope {
+	case scopeSQL:
+		return s.scope != scopeAll
+	case scopeTable:
+		return s.scope == scopeTable
+	}
+	return true
+}
+
+// DowngradeDDL strips, or rewrites, syntax from DDL generated on the
+// "from" version that is not supported by the "to" version. Warnings
+// are returned for each feature that was affected and for features
+// that could not be automatically rewritten.
+func DowngradeDDL(ddl string, from, to OracleVersion) (string, []string, error) {
+
+	var warnings []string
+
+	if to.IsZero() {
+		return ddl, warnings, fmt.Errorf("no target version specified")
+	}
+	if !to.Less(from) {
+		return ddl, warnings, nil
+	}
+	if to.Less(Oracle11gR2) {
+		return ddl, warnings, fmt.Errorf("downgrading to Oracle %s is not supported", to)
+	}
+
+	stmts := splitStatements(ddl)
+
+	for _, rule := range downgradeRules {
+		if !to.Less(rule.introduced) || from.Less(rule.introduced) {
+			continue
+		}
+
+		matched := false
+		for i, stmt := range stmts {
+			if !stmt.applies(rule.scope) || !rule.re.MatchString(stmt.text) {
+				continue
+			}
+			matched = true
+			if rule.replacement != nil {
+				stmts[i].text = rule.re.ReplaceAllString(stmt.text, *rule.replacement)
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		if rule.replacement == nil {
+			warnings = append(warnings, fmt.Sprintf("%s is not supported by Oracle %s and needs to be manually rewritten", rule.feature, to))
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("%s is not supported by Oracle %s and was removed or rewritten", rule.feature, to))
+	}
+
+	var b strings.Builder
+	for _, stmt := range stmts {
+		b.WriteString(stmt.text)
+	}
+
+	return b.String(), warnings, nil
+}
@@ -0,0 +1,142 @@
+package oradex
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseOracleVersion(t *testing.T) {
+
+	tests := []struct {
+		in      string
+		want    OracleVersion
+		wantErr bool
+	}{
+		{"11.2", Oracle11gR2, false},
+		{"11g", OracleVersion{11, 0}, false},
+		{"19c", Oracle19c, false},
+		{" 19.3.0.0.0 ", OracleVersion{19, 3}, false},
+		{"23", Oracle23c, false},
+		{"", OracleVersion{}, true},
+		{"latest", OracleVersion{}, true},
+		{"12.x", OracleVersion{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseOracleVersion(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDowngradeDDL(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		ddl      string
+		from     OracleVersion
+		to       OracleVersion
+		want     string
+		warnings []string
+		wantErr  bool
+	}{
+		{
+			"no target",
+			`CREATE TABLE "T" ( "C" NUMBER )`,
+			Oracle19c, OracleVersion{},
+			`CREATE TABLE "T" ( "C" NUMBER )`,
+			nil,
+			true,
+		},
+		{
+			"unsupported target",
+			`CREATE TABLE "T" ( "C" NUMBER )`,
+			Oracle19c, OracleVersion{10, 2},
+			`CREATE TABLE "T" ( "C" NUMBER )`,
+			nil,
+			true,
+		},
+		{
+			"newer target",
+			`CREATE TABLE "T" ( "C" BOOLEAN )`,
+			Oracle23c, Oracle23c,
+			`CREATE TABLE "T" ( "C" BOOLEAN )`,
+			nil,
+			false,
+		},
+		{
+			"identity column",
+			`CREATE TABLE "T" ( "ID" NUMBER GENERATED BY DEFAULT ON NULL AS IDENTITY MINVALUE 1 MAXVALUE 9999999999999999999999999999 INCREMENT BY 1 START WITH 1 CACHE 20 NOORDER  NOCYCLE  NOKEEP  NOSCALE  NOT NULL ENABLE )`,
+			Oracle19c, Oracle11gR2,
+			`CREATE TABLE "T" ( "ID" NUMBER  NOT NULL ENABLE )`,
+			[]string{"identity column"},
+			false,
+		},
+		{
+			"default on null",
+			`CREATE TABLE "T" ( "C" VARCHAR2(1) DEFAULT ON NULL 'N' )`,
+			Oracle19c, Oracle11gR2,
+			`CREATE TABLE "T" ( "C" VARCHAR2(1) DEFAULT 'N' )`,
+			[]string{"DEFAULT ON NULL"},
+			false,
+		},
+		{
+			"only tables",
+			"CREATE TABLE \"T\" ( \"C\" BOOLEAN )\n  CREATE OR REPLACE FUNCTION \"F\" RETURN BOOLEAN\nIS BEGIN RETURN TRUE ; END ;\n/\n",
+			Oracle23c, Oracle21c,
+			"CREATE TABLE \"T\" ( \"C\" NUMBER(1) )\n  CREATE OR REPLACE FUNCTION \"F\" RETURN BOOLEAN\nIS BEGIN RETURN TRUE ; END ;\n/\n",
+			[]string{"BOOLEAN data type"},
+			false,
+		},
+		{
+			"if not exists",
+			`CREATE TABLE IF NOT EXISTS "T" ( "C" JSON )`,
+			Oracle23c, Oracle19c,
+			`CREATE TABLE "T" ( "C" CLOB )`,
+			[]string{"JSON data type", "IF [NOT] EXISTS clause"},
+			false,
+		},
+		{
+			"manual rewrite",
+			`CREATE VIEW "V" AS SELECT * FROM t ORDER BY 1 FETCH FIRST 5 ROWS ONLY`,
+			Oracle19c, Oracle11gR2,
+			`CREATE VIEW "V" AS SELECT * FROM t ORDER BY 1 FETCH FIRST 5 ROWS ONLY`,
+			[]string{"row limiting clause"},
+			false,
+		},
+		{
+			"long identifier",
+			`CREATE TABLE "ORDER_LINE_ITEM_DISCOUNT_ADJUSTMENTS" ( "C" NUMBER )`,
+			Oracle19c, Oracle12cR1,
+			`CREATE TABLE "ORDER_LINE_ITEM_DISCOUNT_ADJUSTMENTS" ( "C" NUMBER )`,
+			[]string{"identifier longer than 30 characters"},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warnings, err := DowngradeDDL(tt.ddl, tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if len(warnings) != len(tt.warnings) {
+				t.Fatalf("got warnings %q, want %q", warnings, tt.warnings)
+			}
+			for i, w := range tt.warnings {
+				if !strings.HasPrefix(warnings[i], w+" is not supported") {
+					t.Errorf("got warning %q, want %q", warnings[i], w)
+				}
+			}
+		})
+	}
+}
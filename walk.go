@@ -0,0 +1,78 @@
+package oradex
+
+import (
+	"context"
+	"time"
+)
+
+// WalkFunc is the function called by WalkSchema for each object. If it
+// returns an error then the walk is stopped and WalkSchema returns the
+// error.
+type WalkFunc func(o Object) error
+
+// WalkSchema extracts each of the objects in the specified schema, as
+// limited by the object type and name options, and calls fn with each.
+// This allows for storing the DDL somewhere other than the file system
+// without having to reimplement the listing of the objects. Objects that
+// can not be extracted are recorded in the returned result and skipped.
+// The DBMS_METADATA transformation parameters are initialized from the
+// options first. See Extractor.WalkSchema.
+func WalkSchema(ctx context.Context, db Querier, schema string, opts ExportOptions, fn WalkFunc) (ExtractionResult, error) {
+
+	e := NewExtractor(db, opts)
+	if err := e.Init(ctx); err != nil {
+		return ExtractionResult{Schema: schema}, err
+	}
+
+	return e.WalkSchema(ctx, schema, fn)
+}
+
+// WalkSchema extracts each of the objects in the specified schema, as
+// limited by the object type and name options of the Extractor, and
+// calls fn with each. The walk stops at the first error returned by fn,
+// or when the context is cancelled.
+func (e *Extractor) WalkSchema(ctx context.Context, schema string, fn WalkFunc) (ExtractionResult, error) {
+
+	start := time.Now()
+	summary := ExtractionResult{Schema: schema}
+
+	f := Filter{
+		ObjectTypes:        e.opts.ObjectTypes,
+		ExcludeObjectTypes: e.opts.ExcludeObjectTypes,
+		IncludeNames:       e.opts.IncludeNames,
+		ExcludeNames:       e.opts.ExcludeNames,
+		SeparateTriggers:   e.opts.SeparateTriggers,
+	}
+
+	l, err := ListObjects(ctx, e.db, schema, f)
+	if err != nil {
+		summary.Elapsed = time.Since(start)
+		return summary, err
+	}
+
+	for _, v := range l {
+		if !e.opts.IncludeObject(v.Name, v.ObjType) {
+			continue
+		}
+
+		o, res, err := e.exportObject(ctx, v.Schema, v.Name, v.ObjType)
+		summary.Add(res, err)
+		if err != nil {
+			if ctx.Err() != nil {
+				summary.Elapsed = time.Since(start)
+				return summary, ctx.Err()
+			}
+			logger.Error(err.Error())
+			continue
+		}
+
+		err = fn(o)
+		if err != nil {
+			summary.Elapsed = time.Since(start)
+			return summary, err
+		}
+	}
+
+	summary.Elapsed = time.Since(start)
+	return summary, nil
+}
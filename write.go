@@ -0,0 +1,74 @@
+package oradex
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile writes the data to the named file unless the file already
+// has the same content, in which case the file, and its modification
+// time, is left as is. The data is written to a temporary file in the
+// same directory that then replaces the file so that an interrupted
+// write never leaves a partially written file. Returns whether or not
+// the file was written.
+func WriteFile(filename string, b []byte, perm os.FileMode) (bool, error) {
+
+	current, err := ioutil.ReadFile(filename)
+	if err == nil && bytes.Equal(current, b) {
+		return false, nil
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return false, err
+	}
+	tmp := f.Name()
+
+	_, err = f.Write(b)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, perm)
+	}
+	if err == nil {
+		err = os.Rename(tmp, filename)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// replaceDir replaces the directory dir with the directory newDir. As a
+// directory can not be renamed over one that is not empty, any existing
+// dir is first moved aside, and is restored should the rename fail.
+func replaceDir(newDir, dir string) error {
+
+	old := newDir + ".old"
+	err := os.Rename(dir, old)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	replaced := err == nil
+
+	err = os.Rename(newDir, dir)
+	if err != nil {
+		if replaced {
+			os.Rename(old, dir)
+		}
+		return err
+	}
+
+	if replaced {
+		return os.RemoveAll(old)
+	}
+	return nil
+}
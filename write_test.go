@@ -0,0 +1,69 @@
+package oradex
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReplaceDir(t *testing.T) {
+
+	base := t.TempDir()
+	dir := filepath.Join(base, "_group")
+
+	// listDir returns the names of the files in the directory
+	listDir := func() []string {
+		var l []string
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range files {
+			l = append(l, f.Name())
+		}
+		return l
+	}
+
+	for _, names := range [][]string{
+		{"0001_APP.ORDERS.sql", "0002_APP.OPEN_ORDERS.sql"},
+		{"0001_APP.ORDER_SEQ.sql"},
+	} {
+		tmp, err := ioutil.TempDir(base, "._group.*.tmp")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range names {
+			err = ioutil.WriteFile(filepath.Join(tmp, name), nil, 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		err = replaceDir(tmp, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := listDir(); !reflect.DeepEqual(got, names) {
+			t.Errorf("got %q, want %q", got, names)
+		}
+	}
+
+	// Only the group remains
+	files, err := ioutil.ReadDir(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("got %d entries in the base directory, want 1", len(files))
+	}
+
+	// A failed replacement leaves the group as is
+	err = replaceDir(filepath.Join(base, "missing"), dir)
+	if err == nil {
+		t.Error("no error for a missing directory")
+	}
+	if got := listDir(); len(got) != 1 {
+		t.Errorf("got %q after a failed replacement", got)
+	}
+}